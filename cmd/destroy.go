@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
@@ -11,36 +14,74 @@ import (
 
 func newDestroyCmd(cc clients.Connector) *cobra.Command {
 	return &cobra.Command{
-		Use:   "destroy [file]",
-		Short: "Destroy the current stack or file",
-		Long: `Destroy the current stack or file. 
+		Use:   "destroy [file] | [resource] ...",
+		Short: "Destroy the current stack, file, or resources",
+		Long: `Destroy the current stack, file, or resources. 
 	If the optional parameter "file" is passed then only the resources contained
-	in the file will be destroyed`,
-		Example: `yard destroy`,
-		Run: func(cmd *cobra.Command, args []string) {
-			dst := ""
-			if len(args) > 0 {
-				dst = args[0]
+	in the file will be destroyed.
+	If one or more resources are passed then only those resources and any resources
+	which depend on them will be destroyed, resources are specified as type.name or
+	type.name[index]. Any other argument is treated as a file or folder, files
+	in the current folder must be prefixed with ./ i.e. ./network.hcl`,
+		Example: `
+  # Destroy the current stack
+  shipyard destroy
+
+  # Destroy the resources defined in a file
+  shipyard destroy ./network.hcl
+
+  # Destroy resources and anything which depends on them
+  shipyard destroy helm.vault k8s_config.app
+	`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dst, targets, err := parseDestroyArgs(args)
+			if err != nil {
+				return err
+			}
+
+			// Cancel the destroy when the user hits Ctrl-C, the state
+			// is saved with any resources which have not been destroyed
+			ctx, stop := interruptContext(hclog.Default())
+			defer stop()
+
+			// show the progress for each resource
+			unsubscribe := engine.Subscribe(newProgressObserver(cmd.OutOrStdout()))
+			defer unsubscribe()
+
+			if len(targets) > 0 {
+				err := engine.DestroyTargets(ctx, targets)
+				if err != nil {
+					return fmt.Errorf("Unable to destroy resources: %s", err)
+				}
+
+				return nil
 			}
 
-			// When destroying a stack all the config
-			// which is created with apply is copied
-			// to the state folder
-			var err error
 			if dst == "" {
-				err = engine.Destroy(dst, true)
+				err = engine.Destroy(ctx, dst, true)
 			} else {
-				err = engine.Destroy(dst, false)
+				err = engine.Destroy(ctx, dst, false)
 			}
 
 			if err != nil {
-				hclog.Default().Error("Unable to destroy stack", "error", err)
-				return
+				return fmt.Errorf("Unable to destroy stack: %s", err)
 			}
 
-			// clean up the data folder
+			// clean up the data folder for the workspace
 			os.RemoveAll(utils.GetDataFolder(""))
 
+			// the certs and the ingress are shared by all workspaces, only
+			// remove them when no workspace has any resources
+			active, err := utils.ActiveWorkspaces()
+			if err != nil {
+				hclog.Default().Error("Unable to list workspaces", "error", err)
+				return nil
+			}
+
+			if len(active) > 0 {
+				return nil
+			}
+
 			// remove the certs
 			os.RemoveAll(utils.CertsDir(""))
 
@@ -51,6 +92,48 @@ func newDestroyCmd(cc clients.Connector) *cobra.Command {
 					hclog.Default().Error("Unable to stop ingress", "error", err)
 				}
 			}
+
+			return nil
 		},
+		SilenceUsage: true,
+	}
+}
+
+// resourceAddressRegex matches the address of a resource i.e. helm.vault,
+// or an instance created with count or for_each i.e. container.consul[0]
+var resourceAddressRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*\.[A-Za-z0-9_-]+(\[[^\]]+\])?$`)
+
+// parseDestroyArgs returns the path or the resource addresses to destroy, arguments
+// which are resource addresses are targets, any other argument is a path. Only one
+// path can be destroyed and paths can not be mixed with resources.
+func parseDestroyArgs(args []string) (string, []string, error) {
+	paths := []string{}
+	targets := []string{}
+
+	for _, a := range args {
+		if resourceAddressRegex.MatchString(a) {
+			targets = append(targets, a)
+			continue
+		}
+
+		paths = append(paths, a)
 	}
+
+	if len(paths) > 0 && len(targets) > 0 {
+		return "", nil, fmt.Errorf("Unable to destroy a file or folder and resources at the same time, got paths %s and resources %s", strings.Join(paths, ", "), strings.Join(targets, ", "))
+	}
+
+	if len(paths) > 1 {
+		return "", nil, fmt.Errorf("Only one file or folder can be destroyed, got %s", strings.Join(paths, ", "))
+	}
+
+	if len(paths) == 1 {
+		if !utils.IsLocalFolder(paths[0]) {
+			return "", nil, fmt.Errorf("File or folder %s does not exist", paths[0])
+		}
+
+		return paths[0], nil, nil
+	}
+
+	return "", targets, nil
 }
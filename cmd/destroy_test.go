@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestDestroyArgsWithResourcesReturnsTargets(t *testing.T) {
+	dst, targets, err := parseDestroyArgs([]string{"helm.vault", "container.consul[0]", `container.consul["dc1"]`})
+	assert.NoError(t, err)
+
+	assert.Empty(t, dst)
+	assert.Equal(t, []string{"helm.vault", "container.consul[0]", `container.consul["dc1"]`}, targets)
+}
+
+func TestDestroyArgsWithPathReturnsPath(t *testing.T) {
+	dst, targets, err := parseDestroyArgs([]string{"../examples/single_file/container.hcl"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "../examples/single_file/container.hcl", dst)
+	assert.Empty(t, targets)
+}
+
+func TestDestroyArgsWithNoArgsReturnsNoPathOrTargets(t *testing.T) {
+	dst, targets, err := parseDestroyArgs([]string{})
+	assert.NoError(t, err)
+
+	assert.Empty(t, dst)
+	assert.Empty(t, targets)
+}
+
+func TestDestroyArgsWithMissingPathReturnsError(t *testing.T) {
+	_, _, err := parseDestroyArgs([]string{"./does/not/exist"})
+	assert.Error(t, err)
+}
+
+func TestDestroyArgsWithPathsAndResourcesReturnsError(t *testing.T) {
+	_, _, err := parseDestroyArgs([]string{"../examples/single_file", "helm.vault"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "at the same time")
+}
+
+func TestDestroyArgsWithMultiplePathsReturnsError(t *testing.T) {
+	_, _, err := parseDestroyArgs([]string{"../examples/single_file", "../examples/build"})
+	assert.Error(t, err)
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/shipyard-run/shipyard/pkg/config"
 	"github.com/shipyard-run/shipyard/pkg/shipyard"
-	"github.com/shipyard-run/shipyard/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +33,7 @@ func newEnvCmd(e shipyard.Engine) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			c := config.New()
-			err := c.FromJSON(utils.StatePath())
+			err := loadState(c)
 			if err != nil {
 				fmt.Println("Unable to load state", err)
 				os.Exit(1)
@@ -56,7 +55,11 @@ func newEnvCmd(e shipyard.Engine) *cobra.Command {
 			for _, r := range c.Resources {
 				if r.Info().Type == config.TypeOutput {
 					val := strings.ReplaceAll(r.(*config.Output).Value, `\`, `\\`)
-					fmt.Printf("%s%s=\"%s\"\n", prefix, r.Info().Name, val)
+
+					// outputs from modules are named [module].[name], dots are
+					// not valid in environment variable names
+					name := strings.ReplaceAll(r.Info().Name, ".", "_")
+					fmt.Printf("%s%s=\"%s\"\n", prefix, name, val)
 				}
 			}
 			return nil
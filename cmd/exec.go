@@ -39,7 +39,7 @@ func newExecCmd(dt clients.ContainerTasks) *cobra.Command {
 
 			// find a list of resources in the current stack
 			sc := config.New()
-			err := sc.FromJSON(utils.StatePath())
+			err := loadState(sc)
 			if err != nil {
 				return fmt.Errorf("No resources are running, start a stack with 'shipyard run [blueprint]'")
 			}
@@ -92,6 +92,18 @@ func TestExecCreatesShellInContainer(t *testing.T) {
 	assert.Equal(t, []string{"sh"}, call.Arguments[1].([]string))
 }
 
+func TestExecCreatesShellInContainerInstance(t *testing.T) {
+	c, mt, cleanup := setupExec(instanceState)
+	defer cleanup()
+
+	c.SetArgs([]string{"container.consul[1]"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	mt.AssertCalled(t, "FindContainerIDs", "consul[1]", config.TypeContainer)
+}
+
 func TestExecCreatesShellInContainerWithCustomCommand(t *testing.T) {
 	c, mt, cleanup := setupExec(baseState)
 	defer cleanup()
@@ -263,3 +275,21 @@ var baseState = `
   ]
 }
 `
+
+var instanceState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "consul[0]",
+      "status": "applied",
+	  "type": "container"
+	},
+	{
+      "name": "consul[1]",
+      "status": "applied",
+	  "type": "container"
+	}
+  ]
+}
+`
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/spf13/cobra"
+)
+
+func newGraphCmd(e shipyard.Engine, bp clients.Getter) *cobra.Command {
+	var variables []string
+	var variablesFile string
+	var jsonOutput bool
+	var colorStatus bool
+
+	graphCmd := &cobra.Command{
+		Use:   "graph [file] [directory] ...",
+		Short: "Output the dependency graph for a blueprint",
+		Long: `Output the dependency graph for a blueprint.
+	The graph shows the order in which resources are created, an edge from
+	one resource to another means the first resource depends on the second.
+	By default the graph is output in Graphviz DOT format, resources which
+	belong to a module are grouped in a subgraph`,
+		Example: `
+  # Render the graph for the blueprint in the current directory
+  shipyard graph | dot -Tsvg > graph.svg
+
+  # Color resources by their status in the state
+  shipyard graph --status ./my-stack
+
+  # Output the graph as JSON
+  shipyard graph --json ./my-stack
+	`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vars := parseVariables(variables)
+
+			err := checkVariablesFile(variablesFile)
+			if err != nil {
+				return err
+			}
+
+			dst := "./"
+			if len(args) == 1 {
+				dst = args[0]
+			}
+
+			dst, err = getBlueprint(bp, dst)
+			if err != nil {
+				return err
+			}
+
+			g, err := e.Graph(dst, vars, variablesFile)
+			if err != nil {
+				return fmt.Errorf("Unable to create graph for blueprint: %s", err)
+			}
+
+			if jsonOutput {
+				s, err := json.MarshalIndent(g, "", "  ")
+				if err != nil {
+					return fmt.Errorf("Unable to render graph: %s", err)
+				}
+
+				cmd.Println(string(s))
+				return nil
+			}
+
+			cmd.Print(g.DOT(colorStatus))
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	graphCmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "Output the graph as JSON")
+	graphCmd.Flags().BoolVarP(&colorStatus, "status", "", false, "Color resources by their status in the state")
+	graphCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
+	graphCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+
+	return graphCmd
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	clientmocks "github.com/shipyard-run/shipyard/pkg/clients/mocks"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/shipyard-run/shipyard/pkg/shipyard/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupGraph(t *testing.T) (*cobra.Command, *mocks.Engine, *bytes.Buffer) {
+	mockGetter := &clientmocks.Getter{}
+	mockGetter.On("Get", mock.Anything, mock.Anything).Return(nil)
+
+	g := &shipyard.Graph{
+		Nodes: []shipyard.GraphNode{
+			shipyard.GraphNode{Name: "cloud", Type: config.TypeNetwork, Status: config.Applied},
+			shipyard.GraphNode{Name: "consul", Type: config.TypeContainer, Module: "consul", Status: config.PendingCreation},
+		},
+		Edges: []shipyard.GraphEdge{
+			shipyard.GraphEdge{From: "container.consul", To: "network.cloud"},
+		},
+	}
+
+	mockEngine := &mocks.Engine{}
+	mockEngine.On("Graph", mock.Anything, mock.Anything, mock.Anything).Return(g, nil)
+
+	out := bytes.NewBuffer([]byte(""))
+
+	cmd := newGraphCmd(mockEngine, mockGetter)
+	cmd.SetOut(out)
+
+	return cmd, mockEngine, out
+}
+
+func TestGraphCallsEngineWithVariables(t *testing.T) {
+	c, me, _ := setupGraph(t)
+	c.SetArgs([]string{"--var", "foo=bar", "/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	me.AssertCalled(t, "Graph", "/tmp", map[string]string{"foo": "bar"}, "")
+}
+
+func TestGraphOutputsDOT(t *testing.T) {
+	c, _, out := setupGraph(t)
+	c.SetArgs([]string{"/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "digraph shipyard {")
+	assert.Contains(t, out.String(), `"container.consul" -> "network.cloud"`)
+	assert.NotContains(t, out.String(), "fillcolor")
+}
+
+func TestGraphOutputsDOTWithStatus(t *testing.T) {
+	c, _, out := setupGraph(t)
+	c.SetArgs([]string{"--status", "/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), `fillcolor = "green"`)
+}
+
+func TestGraphOutputsJSON(t *testing.T) {
+	c, _, out := setupGraph(t)
+	c.SetArgs([]string{"--json", "/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), `"from": "container.consul"`)
+	assert.Contains(t, out.String(), `"module": "consul"`)
+}
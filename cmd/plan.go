@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hokaccha/go-prettyjson"
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/spf13/cobra"
+)
+
+/*
+[ CREATE   ] network.cloud (green)
+[ UPDATE   ] helm.consul (purple)
+[ RECREATE ] k8s_cluster.k3s (yellow)
+[ NO-OP    ] helm.vault (white)
+*/
+
+func newPlanCmd(e shipyard.Engine, bp clients.Getter) *cobra.Command {
+	var variables []string
+	var variablesFile string
+	var jsonOutput bool
+
+	planCmd := &cobra.Command{
+		Use:   "plan [file] [directory] ...",
+		Short: "Show the changes run would make to the current stack",
+		Long: `Show the changes run would make to the current stack.
+	Plan reads the supplied configuration and the current state, it does not
+	create or destroy any resources`,
+		Example: `
+  # Show the changes for the blueprint in the current directory
+  shipyard plan
+
+  # Show the changes as JSON
+  shipyard plan --json ./my-stack
+	`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vars := parseVariables(variables)
+
+			err := checkVariablesFile(variablesFile)
+			if err != nil {
+				return err
+			}
+
+			dst := "./"
+			if len(args) == 1 {
+				dst = args[0]
+			}
+
+			dst, err = getBlueprint(bp, dst)
+			if err != nil {
+				return err
+			}
+
+			p, err := e.Plan(dst, vars, variablesFile)
+			if err != nil {
+				return fmt.Errorf("Unable to plan blueprint: %s", err)
+			}
+
+			if jsonOutput {
+				s, err := prettyjson.Marshal(p)
+				if err != nil {
+					return fmt.Errorf("Unable to render plan: %s", err)
+				}
+
+				cmd.Println(string(s))
+				return nil
+			}
+
+			renderPlan(cmd, p)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	planCmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "Output the plan as JSON")
+	planCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
+	planCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+
+	return planCmd
+}
+
+// renderPlan writes the human readable form of the plan to the
+// commands output
+func renderPlan(cmd *cobra.Command, p *shipyard.Plan) {
+	cmd.Println()
+	for _, r := range p.Resources {
+		action := fmt.Sprintf(White, "NO-OP   ")
+		switch r.Action {
+		case shipyard.PlanCreate:
+			action = fmt.Sprintf(Green, "CREATE  ")
+		case shipyard.PlanUpdate:
+			action = fmt.Sprintf(Purple, "UPDATE  ")
+		case shipyard.PlanRecreate:
+			action = fmt.Sprintf(Yellow, "RECREATE")
+		case shipyard.PlanDisabled:
+			action = fmt.Sprintf(Teal, "DISABLED")
+		}
+
+		cmd.Printf(" [ %s ] %s.%s\n", action, r.Type, r.Name)
+	}
+
+	cmd.Println()
+	cmd.Printf(
+		"Create: %d Update: %d Recreate: %d No-op: %d Disabled: %d\n",
+		p.Count(shipyard.PlanCreate),
+		p.Count(shipyard.PlanUpdate),
+		p.Count(shipyard.PlanRecreate),
+		p.Count(shipyard.PlanNoOp),
+		p.Count(shipyard.PlanDisabled),
+	)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	clientmocks "github.com/shipyard-run/shipyard/pkg/clients/mocks"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/shipyard-run/shipyard/pkg/shipyard/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupPlan(t *testing.T) (*cobra.Command, *mocks.Engine, *bytes.Buffer) {
+	mockGetter := &clientmocks.Getter{}
+	mockGetter.On("Get", mock.Anything, mock.Anything).Return(nil)
+
+	p := &shipyard.Plan{
+		Resources: []shipyard.PlanItem{
+			shipyard.PlanItem{Name: "cloud", Type: config.TypeNetwork, Status: config.PendingCreation, Action: shipyard.PlanCreate},
+			shipyard.PlanItem{Name: "consul", Type: config.TypeContainer, Status: config.Failed, Action: shipyard.PlanRecreate},
+		},
+	}
+
+	mockEngine := &mocks.Engine{}
+	mockEngine.On("Plan", mock.Anything, mock.Anything, mock.Anything).Return(p, nil)
+
+	out := bytes.NewBuffer([]byte(""))
+
+	cmd := newPlanCmd(mockEngine, mockGetter)
+	cmd.SetOut(out)
+
+	return cmd, mockEngine, out
+}
+
+func TestPlanCallsEngineWithVariables(t *testing.T) {
+	c, me, _ := setupPlan(t)
+	c.SetArgs([]string{"--var", "foo=bar", "/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	me.AssertCalled(t, "Plan", "/tmp", map[string]string{"foo": "bar"}, "")
+}
+
+func TestPlanOutputsActions(t *testing.T) {
+	c, _, out := setupPlan(t)
+	c.SetArgs([]string{"/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "network.cloud")
+	assert.Contains(t, out.String(), "Create: 1 Update: 0 Recreate: 1 No-op: 0 Disabled: 0")
+}
+
+func TestPlanOutputsJSON(t *testing.T) {
+	c, _, out := setupPlan(t)
+	c.SetArgs([]string{"--json", "/tmp"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), `"action"`)
+	assert.Contains(t, out.String(), `"recreate"`)
+}
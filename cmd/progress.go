@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+)
+
+/*
+[ CREATING ] container.consul (yellow)
+[ HEALTH   ] container.consul Checking HTTP endpoint http://localhost:8500 (teal)
+[ CREATED  ] container.consul 12s (green)
+[ FAILED   ] helm.vault boom (red)
+*/
+
+// progressObserver writes a line to the output for each event
+// emitted by the engine
+type progressObserver struct {
+	out     io.Writer
+	mutex   sync.Mutex
+	started map[string]time.Time
+}
+
+func newProgressObserver(out io.Writer) *progressObserver {
+	return &progressObserver{out: out, started: map[string]time.Time{}}
+}
+
+// OnEvent renders the event
+func (p *progressObserver) OnEvent(e shipyard.Event) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	label := ""
+	detail := e.Message
+
+	switch e.Type {
+	case shipyard.EventResourceStarted:
+		p.started[e.Address()] = e.Time
+		label = fmt.Sprintf(Yellow, padLabel(progressVerb(e.Action, "ING")))
+	case shipyard.EventResourceSucceeded:
+		label = fmt.Sprintf(Green, padLabel(progressVerb(e.Action, "ED")))
+		if st, ok := p.started[e.Address()]; ok {
+			detail = e.Time.Sub(st).Round(time.Second).String()
+		}
+	case shipyard.EventResourceRetry:
+		label = fmt.Sprintf(Magenta, padLabel("RETRYING"))
+	case shipyard.EventResourceFailed:
+		label = fmt.Sprintf(Red, padLabel("FAILED"))
+	case shipyard.EventResourceSkipped:
+		// do not show resources which have not changed
+		if e.Action == shipyard.PlanNoOp || e.Action == shipyard.PlanDisabled {
+			return
+		}
+
+		label = fmt.Sprintf(White, padLabel("SKIPPED"))
+	case shipyard.EventHealthCheck:
+		label = fmt.Sprintf(Teal, padLabel("HEALTH"))
+		if e.Error != nil {
+			detail = fmt.Sprintf("%s: %s", e.Message, e.Error)
+		}
+	case shipyard.EventLifecycleHook:
+		label = fmt.Sprintf(Teal, padLabel("HOOK"))
+		detail = e.Message
+		if e.Error != nil {
+			detail = fmt.Sprintf("%s: %s", e.Message, e.Error)
+		}
+	default:
+		return
+	}
+
+	fmt.Fprintf(p.out, " [ %s ] %s %s\n", label, e.Address(), detail)
+}
+
+// progressVerb returns the verb for the action with the given suffix
+// i.e. CREATING or CREATED
+func progressVerb(a shipyard.PlanAction, suffix string) string {
+	verb := "CREAT"
+	switch a {
+	case shipyard.PlanUpdate:
+		verb = "UPDAT"
+	case shipyard.PlanRecreate:
+		verb = "RECREAT"
+	case shipyard.PlanDestroy:
+		verb = "DESTROY"
+	}
+
+	return verb + suffix
+}
+
+func padLabel(l string) string {
+	if len(l) >= 10 {
+		return l
+	}
+
+	return l + strings.Repeat(" ", 10-len(l))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestProgressRendersStartedAndSucceeded(t *testing.T) {
+	out := bytes.NewBufferString("")
+	p := newProgressObserver(out)
+
+	st := time.Now()
+	p.OnEvent(shipyard.Event{Type: shipyard.EventResourceQueued, ResourceType: config.TypeContainer, Name: "consul", Time: st})
+	p.OnEvent(shipyard.Event{Type: shipyard.EventResourceStarted, ResourceType: config.TypeContainer, Name: "consul", Action: shipyard.PlanCreate, Time: st})
+	p.OnEvent(shipyard.Event{Type: shipyard.EventResourceSucceeded, ResourceType: config.TypeContainer, Name: "consul", Action: shipyard.PlanCreate, Time: st.Add(2 * time.Second)})
+
+	assert.Contains(t, out.String(), "CREATING")
+	assert.Contains(t, out.String(), "CREATED")
+	assert.Contains(t, out.String(), "container.consul 2s")
+	assert.NotContains(t, out.String(), "QUEUED")
+}
+
+func TestProgressRendersFailedAndHealthChecks(t *testing.T) {
+	out := bytes.NewBufferString("")
+	p := newProgressObserver(out)
+
+	p.OnEvent(shipyard.Event{Type: shipyard.EventHealthCheck, ResourceType: config.TypeHelm, Name: "vault", Message: "Checking pods app=vault"})
+	p.OnEvent(shipyard.Event{Type: shipyard.EventResourceFailed, ResourceType: config.TypeHelm, Name: "vault", Action: shipyard.PlanUpdate, Message: "boom", Error: fmt.Errorf("boom")})
+
+	assert.Contains(t, out.String(), "helm.vault Checking pods app=vault")
+	assert.Contains(t, out.String(), "FAILED")
+	assert.Contains(t, out.String(), "helm.vault boom")
+}
+
+func TestProgressDoesNotRenderUnchangedResources(t *testing.T) {
+	out := bytes.NewBufferString("")
+	p := newProgressObserver(out)
+
+	p.OnEvent(shipyard.Event{Type: shipyard.EventResourceSkipped, ResourceType: config.TypeNetwork, Name: "cloud", Action: shipyard.PlanNoOp})
+
+	assert.Empty(t, out.String())
+}
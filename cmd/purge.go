@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -107,7 +106,7 @@ func newPurgeCmdFunc(dt clients.Docker, il clients.ImageLog, l hclog.Logger) fun
 			bHasError = true
 		}
 
-		cp := path.Join(utils.ShipyardHome(), "config")
+		cp := utils.ConfigDir()
 		l.Info("Removing config", "path", cp)
 		err = os.RemoveAll(cp)
 		if err != nil {
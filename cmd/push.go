@@ -45,7 +45,7 @@ func newPushCmd(ct clients.ContainerTasks, kc clients.Kubernetes, ht clients.HTT
 
 			// find the cluster in the state
 			sc := config.New()
-			err := sc.FromJSON(utils.StatePath())
+			err := loadState(sc)
 			if err != nil {
 				return xerrors.Errorf("No resources are running, start a stack with 'shipyard run [blueprint]'")
 			}
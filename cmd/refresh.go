@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hokaccha/go-prettyjson"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/spf13/cobra"
+)
+
+/*
+[ MISSING  ] container.consul Resource no longer exists (red)
+[ RECREATE ] helm.vault Dependency k8s_cluster.k3s no longer exists (yellow)
+*/
+
+func newRefreshCmd(e shipyard.Engine) *cobra.Command {
+	var jsonOutput bool
+
+	refreshCmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Check the resources in the current stack still exist",
+		Long: `Check the resources in the current stack still exist.
+	Resources which have been removed outside of Shipyard, i.e. a container
+	which has been removed with the Docker CLI, are marked in the state so that
+	they are created again by the next run`,
+		Example: `
+  # Refresh the state for the current stack
+  shipyard refresh
+
+  # Output the drift as JSON
+  shipyard refresh --json
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := interruptContext(hclog.Default())
+			defer stop()
+
+			d, err := e.Refresh(ctx)
+			if err != nil {
+				return fmt.Errorf("Unable to refresh state: %s", err)
+			}
+
+			if jsonOutput {
+				s, err := prettyjson.Marshal(d)
+				if err != nil {
+					return fmt.Errorf("Unable to render drift: %s", err)
+				}
+
+				cmd.Println(string(s))
+				return nil
+			}
+
+			renderDrift(cmd, d)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	refreshCmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "Output the drift as JSON")
+
+	return refreshCmd
+}
+
+// renderDrift writes the human readable form of the drift to
+// the commands output
+func renderDrift(cmd *cobra.Command, d *shipyard.Drift) {
+	cmd.Println()
+
+	if len(d.Resources) == 0 {
+		cmd.Println("No drift detected, all resources exist")
+		return
+	}
+
+	for _, r := range d.Resources {
+		action := fmt.Sprintf(Red, "MISSING ")
+		if r.Status == config.PendingModification {
+			action = fmt.Sprintf(Yellow, "RECREATE")
+		}
+
+		cmd.Printf(" [ %s ] %s %s\n", action, r.Address(), r.Message)
+	}
+
+	cmd.Println()
+	cmd.Printf("Drift detected for %d resources, run \"shipyard run\" to create them again\n", len(d.Resources))
+}
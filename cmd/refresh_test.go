@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/shipyard-run/shipyard/pkg/shipyard/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupRefresh(t *testing.T, d *shipyard.Drift, err error) (*cobra.Command, *mocks.Engine, *bytes.Buffer) {
+	mockEngine := &mocks.Engine{}
+	mockEngine.On("Refresh", mock.Anything).Return(d, err)
+
+	out := bytes.NewBuffer([]byte(""))
+
+	cmd := newRefreshCmd(mockEngine)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+
+	return cmd, mockEngine, out
+}
+
+func TestRefreshOutputsDrift(t *testing.T) {
+	d := &shipyard.Drift{
+		Resources: []shipyard.DriftItem{
+			shipyard.DriftItem{Name: "consul", Type: config.TypeContainer, Status: config.PendingCreation, Message: "Resource no longer exists"},
+			shipyard.DriftItem{Name: "vault", Type: config.TypeHelm, Status: config.PendingModification, Message: "Dependency container.consul no longer exists"},
+		},
+	}
+
+	c, me, out := setupRefresh(t, d, nil)
+	c.SetArgs([]string{})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	me.AssertCalled(t, "Refresh", mock.Anything)
+	assert.Contains(t, out.String(), "MISSING")
+	assert.Contains(t, out.String(), "container.consul")
+	assert.Contains(t, out.String(), "RECREATE")
+	assert.Contains(t, out.String(), "Drift detected for 2 resources")
+}
+
+func TestRefreshOutputsNoDrift(t *testing.T) {
+	c, _, out := setupRefresh(t, &shipyard.Drift{}, nil)
+	c.SetArgs([]string{})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "No drift detected")
+}
+
+func TestRefreshOutputsJSON(t *testing.T) {
+	d := &shipyard.Drift{
+		Resources: []shipyard.DriftItem{
+			shipyard.DriftItem{Name: "consul", Type: config.TypeContainer, Status: config.PendingCreation, Message: "Resource no longer exists"},
+		},
+	}
+
+	c, _, out := setupRefresh(t, d, nil)
+	c.SetArgs([]string{"--json"})
+
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), `"pending_creation"`)
+}
+
+func TestRefreshWithErrorReturnsError(t *testing.T) {
+	c, _, _ := setupRefresh(t, nil, fmt.Errorf("boom"))
+	c.SetArgs([]string{})
+
+	err := c.Execute()
+	assert.Error(t, err)
+}
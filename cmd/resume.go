@@ -62,7 +62,7 @@ var resumeCmd = &cobra.Command{
 
 		// get the health checks from the config and test
 		con := config.New()
-		err = con.FromJSON(utils.StatePath())
+		err = loadState(con)
 		if err != nil {
 			l.Error("Unable to load state", "error", err)
 			os.Exit(1)
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/config"
 	"github.com/shipyard-run/shipyard/pkg/shipyard"
 	"github.com/shipyard-run/shipyard/pkg/utils"
 	gvm "github.com/shipyard-run/version-manager"
@@ -12,11 +14,40 @@ import (
 )
 
 var configFile = ""
+var workspace = ""
+var lockTimeout time.Duration
+var stateAddress string
+var stateLockAddress string
+var stateUnlockAddress string
 
 var rootCmd = &cobra.Command{
 	Use:   "shipyard",
 	Short: "Modern cloud native development environments",
 	Long:  `Shipyard is a tool that helps you create and run development, demo, and tutorial environments`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if workspace != "" {
+			utils.SetWorkspace(workspace)
+		}
+
+		engine.SetLockTimeout(lockTimeout)
+
+		err := utils.ValidateWorkspace(utils.Workspace())
+		if err != nil {
+			return err
+		}
+
+		// the state flags override the backend configured in the blueprint
+		if stateAddress != "" {
+			b, err := stateBackend()
+			if err != nil {
+				return err
+			}
+
+			engine.SetStateBackend(b)
+		}
+
+		return nil
+	},
 }
 
 var engine shipyard.Engine
@@ -38,12 +69,20 @@ func init() {
 	//cobra.OnInitialize(configure)
 
 	//rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is $HOME/.shipyard/config)")
+	rootCmd.PersistentFlags().DurationVarP(&lockTimeout, "lock-timeout", "", 0, "Time to wait for the state lock when the state is locked by another command, i.e. 30s")
+	rootCmd.PersistentFlags().StringVarP(&stateAddress, "state-address", "", "", "Address of the http state backend, when set the state is stored remotely rather than in the local state file")
+	rootCmd.PersistentFlags().StringVarP(&stateLockAddress, "state-lock-address", "", "", "Address used to lock the state for the http state backend, state locking is disabled when not set")
+	rootCmd.PersistentFlags().StringVarP(&stateUnlockAddress, "state-unlock-address", "", "", "Address used to unlock the state for the http state backend, defaults to the lock address")
+	rootCmd.PersistentFlags().StringVarP(&workspace, "workspace", "", "", "Workspace to use for the command, overrides "+utils.WorkspaceEnvName+" and the selected workspace")
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(checkCmd)
 	rootCmd.AddCommand(outputCmd)
 	rootCmd.AddCommand(newEnvCmd(engine))
 	rootCmd.AddCommand(newRunCmd(engine, engineClients.Getter, engineClients.HTTP, engineClients.Browser, vm, engineClients.Connector, logger))
+	rootCmd.AddCommand(newPlanCmd(engine, engineClients.Getter))
+	rootCmd.AddCommand(newGraphCmd(engine, engineClients.Getter))
+	rootCmd.AddCommand(newRefreshCmd(engine))
 	rootCmd.AddCommand(newTestCmd(engine, engineClients.Getter, engineClients.HTTP, engineClients.Browser, logger))
 	rootCmd.AddCommand(pauseCmd)
 	rootCmd.AddCommand(resumeCmd)
@@ -55,6 +94,8 @@ func init() {
 	rootCmd.AddCommand(newExecCmd(engineClients.ContainerTasks))
 	rootCmd.AddCommand(newVersionCmd(vm))
 	rootCmd.AddCommand(uninstallCmd)
+	rootCmd.AddCommand(newWorkspaceCmd())
+	rootCmd.AddCommand(newStateCmd())
 	rootCmd.AddCommand(newPushCmd(engineClients.ContainerTasks, engineClients.Kubernetes, engineClients.HTTP, engineClients.Nomad, logger))
 
 	// add the server commands
@@ -111,11 +152,15 @@ func createEngine(l hclog.Logger) (shipyard.Engine, gvm.Versions) {
 // Execute the root command
 func Execute(v, c, d string) error {
 	version = v
+	config.Version = v
 	commit = c
 	date = d
 
-	err := rootCmd.Execute()
+	// stop any plugins started by the engine, deferred so
+	// that plugins are also stopped when a command panics
+	defer closePlugins()
 
+	err := rootCmd.Execute()
 	if err != nil {
 		fmt.Println(discordHelp)
 	}
@@ -123,6 +168,14 @@ func Execute(v, c, d string) error {
 	return err
 }
 
+// closePlugins stops any plugins started by the engine, this must be called
+// before os.Exit in commands which use the engine as deferred functions do not run
+func closePlugins() {
+	if engineClients.Plugins != nil {
+		engineClients.Plugins.Close()
+	}
+}
+
 var discordHelp = `
 ### For help and support join our community on Discord: https://discord.gg/ZuEFPJU69D ###
 `
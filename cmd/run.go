@@ -31,6 +31,7 @@ func newRunCmd(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc clients
 	var runVersion string
 	var variables []string
 	var variablesFile string
+	var options shipyard.ApplyOptions
 
 	runCmd := &cobra.Command{
 		Use:   "run [file] [directory] ...",
@@ -45,9 +46,18 @@ func newRunCmd(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc clients
 
   # Create a stack from a blueprint in GitHub
   shipyard run github.com/shipyard-run/blueprints//vault-k8s
+
+  # Create a single resource and its dependencies
+  shipyard run --target container.api ./my-stack
+
+  # Remove any resources created by the run if it fails
+  shipyard run --rollback-on-failure ./my-stack
+
+  # Create any resources which have been removed outside of Shipyard
+  shipyard run --refresh ./my-stack
 	`,
 		Args:         cobra.ArbitraryArgs,
-		RunE:         newRunCmdFunc(e, bp, hc, bc, vm, cc, &noOpen, &force, &runVersion, &y, &variables, &variablesFile, l),
+		RunE:         newRunCmdFunc(e, bp, hc, bc, vm, cc, &noOpen, &force, &runVersion, &y, &variables, &variablesFile, &options, l),
 		SilenceUsage: true,
 	}
 
@@ -57,11 +67,14 @@ func newRunCmd(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc clients
 	runCmd.Flags().BoolVarP(&force, "force-update", "", false, "When set to true Shipyard ignores cached images or files and will download all resources")
 	runCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
 	runCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+	runCmd.Flags().StringSliceVarP(&options.Targets, "target", "", nil, "Only apply the given resource and its dependencies, resources are specified as type.name, e.g --target container.api. Can be specified multiple times")
+	runCmd.Flags().BoolVarP(&options.RollbackOnFailure, "rollback-on-failure", "", false, "When set to true Shipyard destroys the resources created by the run if any resource fails, resources which existed before the run are not changed")
+	runCmd.Flags().BoolVarP(&options.Refresh, "refresh", "", false, "When set to true Shipyard checks the resources in the state still exist before applying, missing resources are created again")
 
 	return runCmd
 }
 
-func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc clients.System, vm gvm.Versions, cc clients.Connector, noOpen *bool, force *bool, runVersion *string, autoApprove *bool, variables *[]string, variablesFile *string, l hclog.Logger) func(cmd *cobra.Command, args []string) error {
+func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc clients.System, vm gvm.Versions, cc clients.Connector, noOpen *bool, force *bool, runVersion *string, autoApprove *bool, variables *[]string, variablesFile *string, options *shipyard.ApplyOptions, l hclog.Logger) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		// create the shipyard and sub folders in the users home directory
 		utils.CreateFolders()
@@ -71,14 +84,7 @@ func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc cli
 			e.GetClients().ContainerTasks.SetForcePull(true)
 		}
 
-		// parse the vars into a map
-		vars := map[string]string{}
-		for _, v := range *variables {
-			parts := strings.Split(v, "=")
-			if len(parts) == 2 {
-				vars[parts[0]] = parts[1]
-			}
-		}
+		vars := parseVariables(*variables)
 
 		// Check the system to see if Docker is running and everything is installed
 		s, err := bc.Preflight()
@@ -91,8 +97,9 @@ func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc cli
 
 		// check the variables file exists
 		if variablesFile != nil && *variablesFile != "" {
-			if _, err := os.Stat(*variablesFile); err != nil {
-				return fmt.Errorf("Variables file %s, does not exist", *variablesFile)
+			err := checkVariablesFile(*variablesFile)
+			if err != nil {
+				return err
 			}
 		} else {
 			vf := ""
@@ -144,14 +151,9 @@ func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc cli
 			cmd.Println("Running configuration from: ", dst)
 			cmd.Println("")
 
-			if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) {
-				// fetch the remote server from github
-				err := bp.Get(dst, utils.GetBlueprintLocalFolder(dst))
-				if err != nil {
-					return fmt.Errorf("Unable to retrieve blueprint: %s", err)
-				}
-
-				dst = utils.GetBlueprintLocalFolder(dst)
+			dst, err = getBlueprint(bp, dst)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -177,18 +179,17 @@ func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc cli
 			}
 		}
 
-		// update status every 30s to let people know we are still running
-		statusUpdate := time.NewTicker(15 * time.Second)
-		startTime := time.Now()
+		// cancel the apply when the user hits Ctrl-C, resources which
+		// are being created are stopped and the state is saved
+		ctx, stop := interruptContext(l)
 
-		go func() {
-			for range statusUpdate.C {
-				elapsedTime := time.Now().Sub(startTime).String()
-				logger.Info("Please wait, still creating resources [Elapsed Time: " + elapsedTime + "]")
-			}
-		}()
+		// show the progress for each resource
+		unsubscribe := e.Subscribe(newProgressObserver(cmd.OutOrStdout()))
+
+		res, err := e.ApplyWithOptions(ctx, dst, vars, *variablesFile, *options)
+		unsubscribe()
+		stop()
 
-		res, err := e.ApplyWithVariables(dst, vars, *variablesFile)
 		if err != nil {
 			return fmt.Errorf("Unable to apply blueprint: %s", err)
 		}
@@ -283,9 +284,6 @@ func newRunCmdFunc(e shipyard.Engine, bp clients.Getter, hc clients.HTTP, bc cli
 			l.Debug("Browser windows open")
 		}
 
-		// kill the timer
-		statusUpdate.Stop()
-
 		// if we have a blueprint show the header
 		if e.Blueprint() != nil {
 			cmd.Println("")
@@ -338,13 +336,13 @@ func buildBrowserPath(n, p string, t config.ResourceType, path string) string {
 		ty = config.TypeIngress
 	}
 
-	return fmt.Sprintf("http://%s.%s.shipyard.run:%s%s", n, ty, p, path)
+	return fmt.Sprintf("http://%s:%s%s", utils.FQDN(n, string(ty)), p, path)
 }
 
 func bluePrintInState() bool {
 	//load the state
 	sc := config.New()
-	sc.FromJSON(utils.StatePath())
+	loadState(sc)
 
 	return sc.Blueprint != nil
 }
@@ -77,8 +77,9 @@ func setupRun(t *testing.T, timeout string) (*cobra.Command, *runMocks) {
 
 	mockEngine := &mocks.Engine{}
 	mockEngine.On("ParseConfigWithVariables", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-	mockEngine.On("ApplyWithVariables", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	mockEngine.On("ApplyWithOptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 	mockEngine.On("GetClients", mock.Anything).Return(clients)
+	mockEngine.On("Subscribe", mock.Anything).Return(func() {})
 	mockEngine.On("ResourceCountForType", mock.Anything).Return(0)
 
 	bp := config.Blueprint{BrowserWindows: []string{"http://localhost", "http://localhost2"}}
@@ -232,7 +233,37 @@ func TestRunSetsDestinationFromArgsWhenPresent(t *testing.T) {
 	err := rf.Execute()
 	assert.NoError(t, err)
 
-	rm.engine.AssertCalled(t, "ApplyWithVariables", "/tmp", mock.Anything, mock.Anything)
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, "/tmp", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunSetsTargetsWhenPresent(t *testing.T) {
+	rf, rm := setupRun(t, "")
+	rf.SetArgs([]string{"--target", "container.api", "--target", "helm.vault", "/tmp"})
+
+	err := rf.Execute()
+	assert.NoError(t, err)
+
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, "/tmp", mock.Anything, mock.Anything, shipyard.ApplyOptions{Targets: []string{"container.api", "helm.vault"}})
+}
+
+func TestRunSetsRefreshWhenPresent(t *testing.T) {
+	rf, rm := setupRun(t, "")
+	rf.SetArgs([]string{"--refresh", "/tmp"})
+
+	err := rf.Execute()
+	assert.NoError(t, err)
+
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, "/tmp", mock.Anything, mock.Anything, shipyard.ApplyOptions{Refresh: true})
+}
+
+func TestRunSetsRollbackWhenPresent(t *testing.T) {
+	rf, rm := setupRun(t, "")
+	rf.SetArgs([]string{"--rollback-on-failure", "/tmp"})
+
+	err := rf.Execute()
+	assert.NoError(t, err)
+
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, "/tmp", mock.Anything, mock.Anything, shipyard.ApplyOptions{RollbackOnFailure: true})
 }
 
 func TestRunSetsVariablesFileReturnsErrorWhenMissing(t *testing.T) {
@@ -257,7 +288,7 @@ func TestRunSetsVariablesFileWhenPresent(t *testing.T) {
 	err = rf.Execute()
 	assert.NoError(t, err)
 
-	rm.engine.AssertCalled(t, "ApplyWithVariables", "/tmp", mock.Anything, tmpFile.Name())
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, "/tmp", mock.Anything, tmpFile.Name(), mock.Anything)
 }
 
 func TestRunSetsDestinationToDownloadedBlueprintFromArgsWhenRemote(t *testing.T) {
@@ -267,7 +298,7 @@ func TestRunSetsDestinationToDownloadedBlueprintFromArgsWhenRemote(t *testing.T)
 	err := rf.Execute()
 	assert.NoError(t, err)
 
-	rm.engine.AssertCalled(t, "ApplyWithVariables", filepath.Join(utils.ShipyardHome(), "blueprints/github.com/shipyard-run/blueprints/vault-k8s"), mock.Anything, mock.Anything)
+	rm.engine.AssertCalled(t, "ApplyWithOptions", mock.Anything, filepath.Join(utils.ShipyardHome(), "blueprints/github.com/shipyard-run/blueprints/vault-k8s"), mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestRunFetchesBlueprint(t *testing.T) {
@@ -339,7 +370,7 @@ func TestRunOpensBrowserWindowForResources(t *testing.T) {
 	rf, rm := setupRun(t, "")
 	rf.SetArgs([]string{"/tmp"})
 
-	removeOn(&rm.engine.Mock, "ApplyWithVariables")
+	removeOn(&rm.engine.Mock, "ApplyWithOptions")
 
 	// should open
 	d := config.NewDocs("test")
@@ -367,7 +398,7 @@ func TestRunOpensBrowserWindowForResources(t *testing.T) {
 	// should not be opened
 	d2 := config.NewDocs("test2")
 
-	rm.engine.On("ApplyWithVariables", mock.Anything, mock.Anything, mock.Anything).Return(
+	rm.engine.On("ApplyWithOptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
 		[]config.Resource{d, i, c, d2, i2, c2},
 		nil,
 	)
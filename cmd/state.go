@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newStateCmd() *cobra.Command {
+	stateCmd := &cobra.Command{
+		Use:   "state",
+		Short: "Manage the state for the current workspace",
+	}
+
+	stateCmd.AddCommand(newStateUnlockCmd())
+
+	return stateCmd
+}
+
+func newStateUnlockCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unlock",
+		Short: "Remove the lock for the state",
+		Long: `Remove the lock for the state.
+	The state is locked while commands such as run and destroy are changing it, when
+	a command exits without releasing the lock, i.e. the process was killed, the
+	lock must be removed before the state can be changed again.
+	Only remove the lock when the process holding it is no longer running`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := stateBackend()
+			if err != nil {
+				return err
+			}
+
+			h, err := b.ForceUnlock()
+			if err != nil {
+				return err
+			}
+
+			if h.ID == "" {
+				cmd.Println("Removed state lock")
+				return nil
+			}
+
+			cmd.Printf("Removed state lock %s held by %s (%s) since %s\n", h.ID, h.Who, h.Operation, h.Created.Format(time.RFC3339))
+			return nil
+		},
+		SilenceUsage: true,
+	}
+}
+
+// stateBackend returns the backend set with the state flags, or the
+// backend for the current workspace when the flags are not set
+func stateBackend() (config.StateBackend, error) {
+	if stateAddress == "" {
+		return config.DefaultStateBackend()
+	}
+
+	sc := &config.StateBackendConfig{
+		Backend:       config.StateBackendHTTP,
+		Address:       stateAddress,
+		LockAddress:   stateLockAddress,
+		UnlockAddress: stateUnlockAddress,
+	}
+
+	return sc.StateBackend()
+}
+
+// loadState reads the state for the current workspace into c
+func loadState(c *config.Config) error {
+	b, err := stateBackend()
+	if err != nil {
+		return err
+	}
+
+	return c.Load(b)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/spf13/cobra"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupStateCmd(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+	t.Cleanup(func() {
+		os.Setenv(utils.HomeEnvName(), home)
+	})
+
+	out := bytes.NewBuffer([]byte(""))
+
+	cmd := newStateCmd()
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+
+	return cmd, out
+}
+
+func TestStateUnlockRemovesLock(t *testing.T) {
+	c, out := setupStateCmd(t)
+
+	_, err := config.LockState(utils.StatePath(), 0)
+	assert.NoError(t, err)
+
+	c.SetArgs([]string{"unlock"})
+	err = c.Execute()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Removed state lock")
+	assert.Contains(t, out.String(), "held by")
+
+	h, err := config.ReadStateLock(utils.StatePath())
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
+
+func TestStateUnlockWhenNotLockedReturnsError(t *testing.T) {
+	c, _ := setupStateCmd(t)
+
+	c.SetArgs([]string{"unlock"})
+	err := c.Execute()
+	assert.Error(t, err)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/hokaccha/go-prettyjson"
 	"github.com/shipyard-run/shipyard/pkg/config"
-	"github.com/shipyard-run/shipyard/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -37,7 +36,7 @@ var statusCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// load the stack
 		c := config.New()
-		err := c.FromJSON(utils.StatePath())
+		err := loadState(c)
 		if err != nil {
 			fmt.Println("Unable to load state", err)
 			os.Exit(1)
@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/shipyard-run/shipyard/pkg/config"
-	"github.com/shipyard-run/shipyard/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -17,31 +15,43 @@ var taintCmd = &cobra.Command{
 	shipyard taint container.test
 	`,
 	Args: cobra.ArbitraryArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			fmt.Println("The resource to taint must be specified as an argument")
-			os.Exit(1)
+			return fmt.Errorf("The resource to taint must be specified as an argument")
 		}
 
+		// hold the lock until the state has been written, errors are returned
+		// rather than exiting so that the lock is always released
+		b, err := stateBackend()
+		if err != nil {
+			return err
+		}
+
+		l, err := b.Lock(lockTimeout)
+		if err != nil {
+			return fmt.Errorf("Unable to lock state: %s", err)
+		}
+		defer l.Unlock()
+
 		c := config.New()
-		err := c.FromJSON(utils.StatePath())
+		err = c.Load(b)
 		if err != nil {
-			fmt.Println("Unable to load state", err)
-			os.Exit(1)
+			return fmt.Errorf("Unable to load state: %s", err)
 		}
 
 		r, err := c.FindResource(args[0])
 		if err != nil || r == nil {
-			fmt.Println("Unable to locate resource in the state", args[0])
-			os.Exit(1)
+			return fmt.Errorf("Unable to locate resource in the state: %s", args[0])
 		}
 
 		r.Info().Status = config.PendingModification
 
-		err = c.ToJSON(utils.StatePath())
+		err = c.Save(b)
 		if err != nil {
-			fmt.Println("Unable to save state", err)
-			os.Exit(1)
+			return fmt.Errorf("Unable to save state: %s", err)
 		}
+
+		return nil
 	},
+	SilenceUsage: true,
 }
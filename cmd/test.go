@@ -148,6 +148,7 @@ func (cr *CucumberRunner) start() {
 		Options:             opts,
 	}.Run()
 
+	closePlugins()
 	os.Exit(status)
 }
 
@@ -262,6 +263,7 @@ func (cr *CucumberRunner) iRunApplyAtPathWithVersion(fp, version string) error {
 
 	noOpen := true
 	approve := true
+	options := shipyard.ApplyOptions{}
 
 	// re-use the run command
 	rc := newRunCmdFunc(
@@ -277,6 +279,7 @@ func (cr *CucumberRunner) iRunApplyAtPathWithVersion(fp, version string) error {
 		&approve,
 		&cr.variables,
 		&cr.variablesFile,
+		&options,
 		cr.l,
 	)
 
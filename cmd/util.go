@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	"github.com/shipyard-run/shipyard/pkg/utils"
 )
 
 func createLogger() hclog.Logger {
@@ -17,3 +24,71 @@ func createLogger() hclog.Logger {
 
 	return hclog.New(opts)
 }
+
+// parseVariables returns the variables set with the --var flag,
+// variables are specified as a key and value i.e. key=value
+func parseVariables(variables []string) map[string]string {
+	vars := map[string]string{}
+	for _, v := range variables {
+		parts := strings.Split(v, "=")
+		if len(parts) == 2 {
+			vars[parts[0]] = parts[1]
+		}
+	}
+
+	return vars
+}
+
+// checkVariablesFile returns an error when the file set with
+// the --vars-file flag does not exist
+func checkVariablesFile(variablesFile string) error {
+	if variablesFile == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(variablesFile); err != nil {
+		return fmt.Errorf("Variables file %s, does not exist", variablesFile)
+	}
+
+	return nil
+}
+
+// getBlueprint returns the local path for the blueprint at dst,
+// remote blueprints i.e. from GitHub are downloaded with bp
+func getBlueprint(bp clients.Getter, dst string) (string, error) {
+	if utils.IsLocalFolder(dst) || utils.IsHCLFile(dst) {
+		return dst, nil
+	}
+
+	// fetch the remote server from github
+	err := bp.Get(dst, utils.GetBlueprintLocalFolder(dst))
+	if err != nil {
+		return "", fmt.Errorf("Unable to retrieve blueprint: %s", err)
+	}
+
+	return utils.GetBlueprintLocalFolder(dst), nil
+}
+
+// interruptContext returns a context which is cancelled when the process receives
+// an interrupt or terminate signal such as Ctrl-C. The returned function must be
+// called to stop listening for signals once the operation completes.
+func interruptContext(l hclog.Logger) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigs:
+			l.Info("Interrupt received, waiting for running operations to stop and saving state")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
+}
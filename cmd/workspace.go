@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceCmd() *cobra.Command {
+	workspaceCmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage workspaces",
+		Long: `Manage workspaces.
+	Each workspace has its own state, cluster config, and Docker objects so that
+	multiple stacks can run at the same time. The workspace for a command can be set
+	with the --workspace flag or the SHIPYARD_WORKSPACE environment variable, otherwise
+	the workspace chosen with "shipyard workspace select" is used`,
+	}
+
+	workspaceCmd.AddCommand(newWorkspaceListCmd())
+	workspaceCmd.AddCommand(newWorkspaceSelectCmd())
+	workspaceCmd.AddCommand(newWorkspaceDeleteCmd())
+
+	return workspaceCmd
+}
+
+func newWorkspaceListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the workspaces, the current workspace is marked with *",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ws, err := utils.Workspaces()
+			if err != nil {
+				return err
+			}
+
+			current := utils.Workspace()
+			for _, w := range ws {
+				if w == current {
+					cmd.Printf("* %s\n", w)
+					continue
+				}
+
+				cmd.Printf("  %s\n", w)
+			}
+
+			// the current workspace may have been set but not yet used
+			if !contains(ws, current) {
+				cmd.Printf("* %s\n", current)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+}
+
+func newWorkspaceSelectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "select [name]",
+		Short: "Select the workspace used by future commands, the workspace is created if it does not exist",
+		Example: `
+  # Select the payments workspace
+  shipyard workspace select payments
+
+  # Return to the default workspace
+  shipyard workspace select default
+	`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := utils.SelectWorkspace(args[0])
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Selected workspace %s\n", args[0])
+
+			if w := os.Getenv(utils.WorkspaceEnvName); w != "" && w != args[0] {
+				cmd.Printf("The %s environment variable is set, commands will use the workspace %s\n", utils.WorkspaceEnvName, w)
+			}
+
+			return nil
+		},
+		SilenceUsage: true,
+	}
+}
+
+func newWorkspaceDeleteCmd() *cobra.Command {
+	var force bool
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a workspace and its state",
+		Long: `Delete a workspace and its state.
+	Workspaces which contain resources can only be deleted with --force, the
+	resources are not destroyed and must be removed manually`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if h, err := config.ReadStateLock(utils.WorkspaceStatePath(name)); err == nil && h != nil && !force {
+				return fmt.Errorf("Unable to delete workspace %s: %s", name, config.StateLockedError{Holder: h})
+			}
+
+			if _, err := os.Stat(utils.WorkspaceStatePath(name)); err == nil && !force {
+				c := config.New()
+				err := c.FromJSON(utils.WorkspaceStatePath(name))
+				if err != nil {
+					return fmt.Errorf("Unable to load state for workspace %s: %s", name, err)
+				}
+
+				if len(c.Resources) > 0 {
+					return fmt.Errorf(
+						"Workspace %s contains %d resources, destroy the resources with \"shipyard destroy --workspace %s\" or use --force to delete the workspace",
+						name,
+						len(c.Resources),
+						name,
+					)
+				}
+			}
+
+			err := utils.DeleteWorkspace(name)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Deleted workspace %s\n", name)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	deleteCmd.Flags().BoolVarP(&force, "force", "", false, "Delete the workspace even when it contains resources")
+
+	return deleteCmd
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/spf13/cobra"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupWorkspace(t *testing.T) (*cobra.Command, *bytes.Buffer) {
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+
+	env := os.Getenv(utils.WorkspaceEnvName)
+	os.Unsetenv(utils.WorkspaceEnvName)
+
+	t.Cleanup(func() {
+		os.Setenv(utils.HomeEnvName(), home)
+		os.Setenv(utils.WorkspaceEnvName, env)
+	})
+
+	out := bytes.NewBuffer([]byte(""))
+
+	cmd := newWorkspaceCmd()
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+
+	return cmd, out
+}
+
+func TestWorkspaceSelectAndList(t *testing.T) {
+	c, out := setupWorkspace(t)
+
+	c.SetArgs([]string{"select", "payments"})
+	err := c.Execute()
+	assert.NoError(t, err)
+
+	out.Reset()
+	c.SetArgs([]string{"list"})
+	err = c.Execute()
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "  default")
+	assert.Contains(t, out.String(), "* payments")
+}
+
+func TestWorkspaceDeleteRemovesWorkspace(t *testing.T) {
+	c, _ := setupWorkspace(t)
+
+	c.SetArgs([]string{"select", "payments"})
+	assert.NoError(t, c.Execute())
+
+	c.SetArgs([]string{"select", "default"})
+	assert.NoError(t, c.Execute())
+
+	c.SetArgs([]string{"delete", "payments"})
+	assert.NoError(t, c.Execute())
+
+	ws, err := utils.Workspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, ws)
+}
+
+func TestWorkspaceDeleteWithResourcesReturnsError(t *testing.T) {
+	c, _ := setupWorkspace(t)
+
+	sp := utils.WorkspaceStatePath("payments")
+	os.MkdirAll(filepath.Dir(sp), os.ModePerm)
+	err := ioutil.WriteFile(sp, []byte(workspaceState), 0644)
+	assert.NoError(t, err)
+
+	c.SetArgs([]string{"delete", "payments"})
+	err = c.Execute()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "contains 1 resources")
+
+	c.SetArgs([]string{"delete", "--force", "payments"})
+	err = c.Execute()
+	assert.NoError(t, err)
+}
+
+var workspaceState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "applied",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	}
+  ]
+}
+`
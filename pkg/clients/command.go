@@ -1,8 +1,10 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"time"
@@ -21,6 +23,9 @@ type CommandConfig struct {
 	RunInBackground  bool
 	LogFilePath      string
 	Timeout          time.Duration
+	// CheckExitCode returns an error when a command which is not run
+	// in the background exits with a non zero exit code
+	CheckExitCode bool
 }
 
 type Command interface {
@@ -32,11 +37,33 @@ type Command interface {
 type CommandImpl struct {
 	timeout time.Duration
 	log     hclog.Logger
+	ctx     context.Context
 }
 
 // NewCommand creates a new command with the given logger and maximum command time
 func NewCommand(maxCommandTime time.Duration, l hclog.Logger) Command {
-	return &CommandImpl{maxCommandTime, l}
+	return &CommandImpl{maxCommandTime, l, context.Background()}
+}
+
+// WithContext returns a copy of the client which uses the given context,
+// when the context is cancelled any running commands are stopped
+func (c *CommandImpl) WithContext(ctx context.Context) Command {
+	cc := *c
+	cc.ctx = ctx
+
+	return &cc
+}
+
+// CommandWithContext returns a copy of the given Command client which uses ctx,
+// if the implementation does not support cancellation c is returned unchanged
+func CommandWithContext(ctx context.Context, c Command) Command {
+	if cc, ok := c.(interface {
+		WithContext(context.Context) Command
+	}); ok {
+		return cc.WithContext(ctx)
+	}
+
+	return c
 }
 
 type done struct {
@@ -65,11 +92,16 @@ func (c *CommandImpl) Execute(config CommandConfig) (int, error) {
 	// done chan
 	doneCh := make(chan done)
 
-	timeout := c.timeout
+	// the deadline of the context replaces the default timeout
+	timeout := contextTimeout(c.ctx, c.timeout)
 	if config.Timeout != (0 * time.Millisecond) {
 		timeout = config.Timeout
 	}
 
+	if config.CheckExitCode && !config.RunInBackground {
+		return c.executeAndWait(config, timeout)
+	}
+
 	// wait for timeout
 	t := time.After(timeout)
 	var pidfile string
@@ -121,11 +153,65 @@ func (c *CommandImpl) Execute(config CommandConfig) (int, error) {
 		lp.Stop(pidfile)
 		mutex.Unlock()
 		return pid, ErrorCommandTimeout
+	case <-c.ctx.Done():
+		mutex.Lock()
+		lp.Stop(pidfile)
+		mutex.Unlock()
+		return pid, c.ctx.Err()
 	case d := <-doneCh:
 		return d.pid, d.err
 	}
 }
 
+// executeAndWait runs the command in the foreground and returns an error
+// if the command does not exit with a zero exit code
+func (c *CommandImpl) executeAndWait(config CommandConfig, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, config.Command, config.Args...)
+	cmd.Env = append(os.Environ(), config.Env...)
+	cmd.Dir = config.WorkingDirectory
+
+	if config.LogFilePath != "" {
+		f, err := os.Create(config.LogFilePath)
+		if err != nil {
+			return 0, fmt.Errorf("Unable to create log file %s: %s", config.LogFilePath, err)
+		}
+		defer f.Close()
+
+		cmd.Stdout = f
+		cmd.Stderr = f
+	}
+
+	c.log.Debug(
+		"Running command",
+		"cmd", config.Command,
+		"args", config.Args,
+		"dir", config.WorkingDirectory,
+		"env", config.Env,
+		"log_file", config.LogFilePath,
+	)
+
+	err := cmd.Start()
+	if err != nil {
+		return 0, err
+	}
+
+	pid := cmd.Process.Pid
+
+	err = cmd.Wait()
+	if ctx.Err() == context.DeadlineExceeded {
+		return pid, ErrorCommandTimeout
+	}
+
+	if err != nil {
+		return pid, fmt.Errorf("Command %s exited with error: %s", config.Command, err)
+	}
+
+	return pid, nil
+}
+
 // Kill a process with the given pid
 func (c *CommandImpl) Kill(pid int) error {
 	lp := gohup.LocalProcess{}
@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"runtime"
 	"testing"
 	"time"
@@ -129,3 +130,74 @@ func TestKillRemovesProcessWhenRunning(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestExecuteWithCheckExitCodeReturnsErrorOnNonZeroExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh")
+	}
+
+	e := setupExecute(t)
+
+	_, err := e.Execute(CommandConfig{
+		Command:       "sh",
+		Args:          []string{"-c", "exit 3"},
+		CheckExitCode: true,
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+}
+
+func TestExecuteWithCheckExitCodeReturnsNoErrorOnSuccess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh")
+	}
+
+	e := setupExecute(t)
+
+	p, err := e.Execute(CommandConfig{
+		Command:       "sh",
+		Args:          []string{"-c", "exit 0"},
+		CheckExitCode: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Greater(t, p, 1)
+}
+
+func TestExecuteWithCheckExitCodeTimesOut(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh")
+	}
+
+	e := setupExecute(t)
+
+	_, err := e.Execute(CommandConfig{
+		Command:       "sh",
+		Args:          []string{"-c", "sleep 10"},
+		Timeout:       100 * time.Millisecond,
+		CheckExitCode: true,
+	})
+
+	assert.Equal(t, ErrorCommandTimeout, err)
+}
+
+func TestExecuteWithCancelledContextStopsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires sh")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := CommandWithContext(ctx, setupExecute(t))
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	st := time.Now()
+	_, err := e.Execute(CommandConfig{
+		Command: "sh",
+		Args:    []string{"-c", "sleep 10"},
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, time.Since(st).Seconds(), float64(3))
+}
@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"io"
 
 	"github.com/shipyard-run/shipyard/pkg/config"
@@ -77,3 +78,16 @@ type ContainerTasks interface {
 	// CreateShell in the running container and attach
 	CreateShell(id string, command []string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error
 }
+
+// ContainerTasksWithContext returns a copy of the given ContainerTasks which uses ctx
+// for all operations, cancelling the context aborts any in flight operations.
+// If the implementation does not support cancellation ct is returned unchanged.
+func ContainerTasksWithContext(ctx context.Context, ct ContainerTasks) ContainerTasks {
+	if c, ok := ct.(interface {
+		WithContext(context.Context) ContainerTasks
+	}); ok {
+		return c.WithContext(ctx)
+	}
+
+	return ct
+}
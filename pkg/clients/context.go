@@ -0,0 +1,16 @@
+package clients
+
+import (
+	"context"
+	"time"
+)
+
+// contextTimeout returns the time until the deadline of ctx, when
+// ctx does not have a deadline the default d is returned
+func contextTimeout(ctx context.Context, d time.Duration) time.Duration {
+	if dl, ok := ctx.Deadline(); ok {
+		return time.Until(dl)
+	}
+
+	return d
+}
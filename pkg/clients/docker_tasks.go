@@ -41,11 +41,22 @@ type DockerTasks struct {
 	il    ImageLog
 	force bool
 	l     hclog.Logger
+	ctx   context.Context
 }
 
 // NewDockerTasks creates a DockerTasks with the given Docker client
 func NewDockerTasks(c Docker, il ImageLog, l hclog.Logger) *DockerTasks {
-	return &DockerTasks{c: c, il: il, l: l}
+	return &DockerTasks{c: c, il: il, l: l, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the DockerTasks which uses the given context
+// for all calls to the Docker SDK, when the context is cancelled any in flight
+// calls are aborted
+func (d *DockerTasks) WithContext(ctx context.Context) ContainerTasks {
+	dt := *d
+	dt.ctx = ctx
+
+	return &dt
 }
 
 // SetForcePull sets a global override for the DockerTasks, when set to true
@@ -217,7 +228,7 @@ func (d *DockerTasks) CreateContainer(c *config.Container) (string, error) {
 	}
 
 	cont, err := d.c.ContainerCreate(
-		context.Background(),
+		d.ctx,
 		dc,
 		hc,
 		nc,
@@ -231,7 +242,7 @@ func (d *DockerTasks) CreateContainer(c *config.Container) (string, error) {
 	// all containers should have custom networks
 	// only add networks if we are not adding the container network
 	if len(c.Networks) > 0 && !hc.NetworkMode.IsContainer() {
-		err := d.c.NetworkDisconnect(context.Background(), "bridge", cont.ID, true)
+		err := d.c.NetworkDisconnect(d.ctx, "bridge", cont.ID, true)
 		if err != nil {
 			return "", xerrors.Errorf("Unable to remove container from the default bridge network: %w", err)
 		}
@@ -261,7 +272,7 @@ func (d *DockerTasks) CreateContainer(c *config.Container) (string, error) {
 		}
 	}
 
-	err = d.c.ContainerStart(context.Background(), cont.ID, types.ContainerStartOptions{})
+	err = d.c.ContainerStart(d.ctx, cont.ID, types.ContainerStartOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -271,7 +282,7 @@ func (d *DockerTasks) CreateContainer(c *config.Container) (string, error) {
 
 // ContainerInfo returns the Docker container info
 func (d *DockerTasks) ContainerInfo(id string) (interface{}, error) {
-	cj, err := d.c.ContainerInspect(context.Background(), id)
+	cj, err := d.c.ContainerInspect(d.ctx, id)
 	if err != nil {
 		return nil, xerrors.Errorf("Unable to read information about Docker container %s: %w", id, err)
 	}
@@ -289,7 +300,7 @@ func (d *DockerTasks) PullImage(image config.Image, force bool) error {
 	// only pull if image is not in current registry so check to see if the image is present
 	// if force then skil this check
 	if !force && !d.force {
-		sum, err := d.c.ImageList(context.Background(), types.ImageListOptions{Filters: args})
+		sum, err := d.c.ImageList(d.ctx, types.ImageListOptions{Filters: args})
 		if err != nil {
 			return xerrors.Errorf("unable to list images in local Docker cache: %w", err)
 		}
@@ -312,7 +323,7 @@ func (d *DockerTasks) PullImage(image config.Image, force bool) error {
 
 	d.l.Debug("Pulling image", "image", image.Name)
 
-	out, err := d.c.ImagePull(context.Background(), in, ipo)
+	out, err := d.c.ImagePull(d.ctx, in, ipo)
 	if err != nil {
 		return xerrors.Errorf("Error pulling image: %w", err)
 	}
@@ -340,7 +351,7 @@ func (d *DockerTasks) FindContainerIDs(containerName string, typeName config.Res
 
 	opts := types.ContainerListOptions{Filters: args, All: true}
 
-	cl, err := d.c.ContainerList(context.Background(), opts)
+	cl, err := d.c.ContainerList(d.ctx, opts)
 	if err != nil || cl == nil {
 		return nil, err
 	}
@@ -360,11 +371,11 @@ func (d *DockerTasks) FindContainerIDs(containerName string, typeName config.Res
 // RemoveContainer with the given id
 func (d *DockerTasks) RemoveContainer(id string) error {
 	// try and shutdown graceful
-	err := d.c.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: false, RemoveVolumes: true})
+	err := d.c.ContainerRemove(d.ctx, id, types.ContainerRemoveOptions{Force: false, RemoveVolumes: true})
 
 	// unable to shutdown graceful try force
 	if err != nil {
-		return d.c.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+		return d.c.ContainerRemove(d.ctx, id, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
 	}
 
 	return nil
@@ -379,7 +390,7 @@ func (d *DockerTasks) BuildContainer(config *config.Container, force bool) (stri
 
 	// check if the image already exists, if so do not rebuild unless force
 	if !force && !d.force {
-		sum, err := d.c.ImageList(context.Background(), types.ImageListOptions{Filters: args})
+		sum, err := d.c.ImageList(d.ctx, types.ImageListOptions{Filters: args})
 		if err != nil {
 			return "", xerrors.Errorf("unable to list images in local Docker cache: %w", err)
 		}
@@ -405,7 +416,7 @@ func (d *DockerTasks) BuildContainer(config *config.Container, force bool) (stri
 
 	buildCtx, _ := archive.TarWithOptions(config.Build.Context, &archive.TarOptions{})
 
-	resp, err := d.c.ImageBuild(context.Background(), buildCtx, buildOpts)
+	resp, err := d.c.ImageBuild(d.ctx, buildCtx, buildOpts)
 	if err != nil {
 		return "", err
 	}
@@ -431,7 +442,7 @@ func (d *DockerTasks) CreateVolume(name string) (string, error) {
 	args := filters.NewArgs()
 	// By default Docker will wildcard searches, use regex to return the absolute
 	args.Add("name", vn)
-	ops, err := d.c.VolumeList(context.Background(), args)
+	ops, err := d.c.VolumeList(d.ctx, args)
 	if err != nil {
 		return "", fmt.Errorf("unable to lookup volume [%s] for cluster [%s]\n%+v", vn, name, err)
 	}
@@ -449,7 +460,7 @@ func (d *DockerTasks) CreateVolume(name string) (string, error) {
 		DriverOpts: map[string]string{},
 	}
 
-	vol, err := d.c.VolumeCreate(context.Background(), volumeCreateOptions)
+	vol, err := d.c.VolumeCreate(d.ctx, volumeCreateOptions)
 	if err != nil {
 		return "", fmt.Errorf("failed to create image volume [%s] for cluster [%s]\n%+v", vn, name, err)
 	}
@@ -462,19 +473,19 @@ func (d *DockerTasks) RemoveVolume(name string) error {
 	vn := utils.FQDNVolumeName(name)
 	d.l.Debug("Deleting Volume", "ref", name, "name", vn)
 
-	return d.c.VolumeRemove(context.Background(), vn, true)
+	return d.c.VolumeRemove(d.ctx, vn, true)
 }
 
 // ContainerLogs streams the logs for the container to the returned io.ReadCloser
 func (d *DockerTasks) ContainerLogs(id string, stdOut, stdErr bool) (io.ReadCloser, error) {
-	return d.c.ContainerLogs(context.Background(), id, types.ContainerLogsOptions{ShowStderr: stdErr, ShowStdout: stdOut})
+	return d.c.ContainerLogs(d.ctx, id, types.ContainerLogsOptions{ShowStderr: stdErr, ShowStdout: stdOut})
 }
 
 // CopyFromContainer copies a file from a container
 func (d *DockerTasks) CopyFromContainer(id, src, dst string) error {
 	d.l.Debug("Copying file from", "id", id, "src", src, "dst", dst)
 
-	reader, _, err := d.c.CopyFromContainer(context.Background(), id, src)
+	reader, _, err := d.c.CopyFromContainer(d.ctx, id, src)
 	if err != nil {
 		return fmt.Errorf("Couldn't copy kubeconfig.yaml from server container %s\n%+v", id, err)
 	}
@@ -646,7 +657,7 @@ func (d *DockerTasks) CopyFileToContainer(containerID, filename, path string) er
 	// reset the file seek so we can copy to the container
 	tmpTarFile.Seek(0, 0)
 
-	err = d.c.CopyToContainer(context.Background(), containerID, path, tmpTarFile, types.CopyToContainerOptions{})
+	err = d.c.CopyToContainer(d.ctx, containerID, path, tmpTarFile, types.CopyToContainerOptions{})
 	if err != nil {
 		return xerrors.Errorf("unable to copy file to container: %w", err)
 	}
@@ -659,7 +670,7 @@ func (d *DockerTasks) CopyFileToContainer(containerID, filename, path string) er
 // command is a slice of strings to execute
 // writer [optional] will be used to write any output from the command execution.
 func (d *DockerTasks) ExecuteCommand(id string, command []string, env []string, workingDir string, writer io.Writer) error {
-	execid, err := d.c.ContainerExecCreate(context.Background(), id, types.ExecConfig{
+	execid, err := d.c.ContainerExecCreate(d.ctx, id, types.ExecConfig{
 		Cmd:          command,
 		AttachStdout: true,
 		AttachStderr: true,
@@ -672,14 +683,14 @@ func (d *DockerTasks) ExecuteCommand(id string, command []string, env []string,
 	}
 
 	// get logs from an attach
-	stream, err := d.c.ContainerExecAttach(context.Background(), execid.ID, types.ExecStartCheck{})
+	stream, err := d.c.ContainerExecAttach(d.ctx, execid.ID, types.ExecStartCheck{})
 	if err != nil {
 		return xerrors.Errorf("unable to attach logging to exec process: %w", err)
 	}
 
 	defer stream.Close()
 
-	streamContext, cancelStream := context.WithCancel(context.Background())
+	streamContext, cancelStream := context.WithCancel(d.ctx)
 	// if we have a writer stream the logs from the container to the writer
 	if writer != nil {
 
@@ -705,7 +716,7 @@ func (d *DockerTasks) ExecuteCommand(id string, command []string, env []string,
 		}
 	}
 
-	err = d.c.ContainerExecStart(context.Background(), execid.ID, types.ExecStartCheck{})
+	err = d.c.ContainerExecStart(d.ctx, execid.ID, types.ExecStartCheck{})
 	if err != nil {
 		cancelStream()
 		return xerrors.Errorf("unable to start exec process: %w", err)
@@ -713,7 +724,7 @@ func (d *DockerTasks) ExecuteCommand(id string, command []string, env []string,
 
 	// loop until the container finishes execution
 	for {
-		i, err := d.c.ContainerExecInspect(context.Background(), execid.ID)
+		i, err := d.c.ContainerExecInspect(d.ctx, execid.ID)
 		if err != nil {
 			cancelStream()
 			return xerrors.Errorf("unable to determine status of exec process: %w", err)
@@ -738,7 +749,7 @@ func (d *DockerTasks) ExecuteCommand(id string, command []string, env []string,
 // CreateShell creates an interactive shell inside a container
 // https://github.com/docker/cli/blob/ae1618713f83e7da07317d579d0675f578de22fa/cli/command/container/exec.go
 func (d *DockerTasks) CreateShell(id string, command []string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error {
-	execid, err := d.c.ContainerExecCreate(context.Background(), id, types.ExecConfig{
+	execid, err := d.c.ContainerExecCreate(d.ctx, id, types.ExecConfig{
 		Cmd:          command,
 		WorkingDir:   "/",
 		AttachStdin:  true,
@@ -756,7 +767,7 @@ func (d *DockerTasks) CreateShell(id string, command []string, stdin io.ReadClos
 	// 	return xerrors.Errorf("unable to start exec process: %w", err)
 	// }
 
-	resp, err := d.c.ContainerExecAttach(context.Background(), execid.ID, types.ExecStartCheck{Tty: true})
+	resp, err := d.c.ContainerExecAttach(d.ctx, execid.ID, types.ExecStartCheck{Tty: true})
 	if err != nil {
 		return err
 	}
@@ -770,7 +781,7 @@ func (d *DockerTasks) CreateShell(id string, command []string, stdin io.ReadClos
 
 	errCh := make(chan error, 1)
 
-	streamContext, streamCancel := context.WithCancel(context.Background())
+	streamContext, streamCancel := context.WithCancel(d.ctx)
 
 	go func() {
 		defer close(errCh)
@@ -796,7 +807,7 @@ func (d *DockerTasks) CreateShell(id string, command []string, stdin io.ReadClos
 
 	// loop until the container finishes execution
 	for {
-		i, err := d.c.ContainerExecInspect(context.Background(), execid.ID)
+		i, err := d.c.ContainerExecInspect(d.ctx, execid.ID)
 		if err != nil {
 			streamCancel()
 			return xerrors.Errorf("unable to determine status of exec process: %w", err)
@@ -849,7 +860,7 @@ func (d *DockerTasks) resizeTTY(id string, out *streams.Out) error {
 	}
 
 	// resize the contiainer
-	err := d.c.ContainerExecResize(context.Background(), id, options)
+	err := d.c.ContainerExecResize(d.ctx, id, options)
 	if err != nil {
 		return err
 	}
@@ -858,6 +869,7 @@ func (d *DockerTasks) resizeTTY(id string, out *streams.Out) error {
 }
 
 func (d *DockerTasks) AttachNetwork(net, containerid string, aliases []string, ipaddress string) error {
+	net = utils.DockerNetworkName(net)
 	d.l.Debug("Attaching container to network", "ref", containerid, "network", net)
 	es := &network.EndpointSettings{NetworkID: net}
 
@@ -872,7 +884,7 @@ func (d *DockerTasks) AttachNetwork(net, containerid string, aliases []string, i
 		es.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: ipaddress}
 	}
 
-	return d.c.NetworkConnect(context.Background(), net, containerid, es)
+	return d.c.NetworkConnect(d.ctx, net, containerid, es)
 }
 
 // ListNetworks lists the networks a container is attached to
@@ -885,8 +897,8 @@ func (d *DockerTasks) ListNetworks(id string) []config.NetworkAttachment {
 // tasks which depend on the network being removed may fail in the future
 // we need to check it has been removed before returning
 func (d *DockerTasks) DetachNetwork(network, containerid string) error {
-	network = strings.Replace(network, "network.", "", -1)
-	err := d.c.NetworkDisconnect(context.Background(), network, containerid, true)
+	network = utils.DockerNetworkName(network)
+	err := d.c.NetworkDisconnect(d.ctx, network, containerid, true)
 
 	// Hacky hack for now
 	//time.Sleep(1000 * time.Millisecond)
@@ -1002,7 +1014,7 @@ func makeImageCanonical(image string) string {
 // it is the responsibility of the caller to remove the temporary file
 func (d *DockerTasks) saveImageToTempFile(image, filename string) (string, error) {
 	// save the image to a local temp file
-	ir, err := d.c.ImageSave(context.Background(), []string{image})
+	ir, err := d.c.ImageSave(d.ctx, []string{image})
 	if err != nil {
 		return "", xerrors.Errorf("unable to save images: %w", err)
 	}
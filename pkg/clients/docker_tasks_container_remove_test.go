@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -36,3 +37,18 @@ func TestContainerRemoveFailsCallsRemoveForcefully(t *testing.T) {
 
 	md.AssertNumberOfCalls(t, "ContainerRemove", 2)
 }
+
+func TestContainerRemoveWithContextUsesContext(t *testing.T) {
+	md := &mocks.MockDocker{}
+	mic := &clients.ImageLog{}
+	dt := NewDockerTasks(md, mic, hclog.NewNullLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	md.On("ContainerRemove", ctx, "test", mock.Anything).Return(nil)
+
+	ContainerTasksWithContext(ctx, dt).RemoveContainer("test")
+
+	md.AssertCalled(t, "ContainerRemove", ctx, "test", mock.Anything)
+}
@@ -1,17 +1,20 @@
 package clients
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/hashicorp/go-hclog"
 	"golang.org/x/xerrors"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 var helmLock sync.Mutex
@@ -25,14 +28,42 @@ func init() {
 type Helm interface {
 	Create(kubeConfig, name, namespace string, createNamespace bool, chartPath, valuesPath string, valuesString map[string]string) error
 	Destroy(kubeConfig, name, namespace string) error
+	// Upgrade an existing release with the given chart and values
+	Upgrade(kubeConfig, name, namespace string, chartPath, valuesPath string, valuesString map[string]string) error
+	// Status returns the status of the release i.e. deployed or failed, an empty
+	// string is returned when the release does not exist
+	Status(kubeConfig, name, namespace string) (string, error)
 }
 
 type HelmImpl struct {
 	log hclog.Logger
+	ctx context.Context
 }
 
 func NewHelm(l hclog.Logger) Helm {
-	return &HelmImpl{l}
+	return &HelmImpl{l, context.Background()}
+}
+
+// WithContext returns a copy of the client which uses the given context. Helm
+// does not support cancellation, operations are not started once the context is
+// cancelled and the Helm timeout is set from the deadline of the context
+func (h *HelmImpl) WithContext(ctx context.Context) Helm {
+	hc := *h
+	hc.ctx = ctx
+
+	return &hc
+}
+
+// HelmWithContext returns a copy of the given Helm client which uses ctx,
+// if the implementation does not support cancellation h is returned unchanged
+func HelmWithContext(ctx context.Context, h Helm) Helm {
+	if c, ok := h.(interface {
+		WithContext(context.Context) Helm
+	}); ok {
+		return c.WithContext(ctx)
+	}
+
+	return h
 }
 
 // Create a new install of the chart
@@ -52,6 +83,125 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 	client.ReleaseName = name
 	client.Namespace = namespace
 	client.CreateNamespace = createNamespace
+	client.Timeout = contextTimeout(h.ctx, 0)
+
+	chartRequested, vals, err := h.loadChart(&client.ChartPathOptions, name, chartPath, valuesPath, valuesString)
+	if err != nil {
+		return err
+	}
+
+	h.log.Debug("Run chart", "ref", name)
+	err = h.run(func() error {
+		_, err := client.Run(chartRequested, vals)
+		return err
+	})
+	if err != nil {
+		return xerrors.Errorf("Error running chart: %w", err)
+	}
+
+	return nil
+}
+
+// Destroy removes an installed Helm chart from the system
+func (h *HelmImpl) Destroy(kubeConfig, name, namespace string) error {
+	s := kube.GetConfig(kubeConfig, "default", namespace)
+	cfg := &action.Configuration{}
+	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
+		h.log.Debug("Helm debug message", "message", fmt.Sprintf(format, v...))
+	})
+
+	//settings := cli.EnvSettings{}
+	//p := getter.All(&settings)
+	//vo := values.Options{}
+	client := action.NewUninstall(cfg)
+	client.Timeout = contextTimeout(h.ctx, 0)
+
+	err = h.run(func() error {
+		_, err := client.Run(name)
+		return err
+	})
+	if err != nil {
+		h.log.Debug("Unable to remove chart, exit silently", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// Upgrade an existing release, the chart and values replace those used
+// when the release was installed
+func (h *HelmImpl) Upgrade(kubeConfig, name, namespace string, chartPath, valuesPath string, valuesString map[string]string) error {
+	s := kube.GetConfig(kubeConfig, "default", namespace)
+	cfg := &action.Configuration{}
+	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
+		h.log.Debug("Helm debug message", "message", fmt.Sprintf(format, v...))
+	})
+
+	if err != nil {
+		return xerrors.Errorf("unalbe to iniailize Helm: %w", err)
+	}
+
+	client := action.NewUpgrade(cfg)
+	client.Namespace = namespace
+	client.Timeout = contextTimeout(h.ctx, 0)
+
+	chartRequested, vals, err := h.loadChart(&client.ChartPathOptions, name, chartPath, valuesPath, valuesString)
+	if err != nil {
+		return err
+	}
+
+	h.log.Debug("Upgrade chart", "ref", name)
+	err = h.run(func() error {
+		_, err := client.Run(name, chartRequested, vals)
+		return err
+	})
+	if err != nil {
+		return xerrors.Errorf("Error upgrading chart: %w", err)
+	}
+
+	return nil
+}
+
+// Status returns the status of an installed release
+func (h *HelmImpl) Status(kubeConfig, name, namespace string) (string, error) {
+	s := kube.GetConfig(kubeConfig, "default", namespace)
+	cfg := &action.Configuration{}
+	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
+		h.log.Debug("Helm debug message", "message", fmt.Sprintf(format, v...))
+	})
+
+	if err != nil {
+		return "", xerrors.Errorf("unalbe to iniailize Helm: %w", err)
+	}
+
+	client := action.NewStatus(cfg)
+	rel, err := client.Run(name)
+	if err != nil {
+		if xerrors.Is(err, driver.ErrReleaseNotFound) {
+			return "", nil
+		}
+
+		return "", xerrors.Errorf("Unable to get release status: %w", err)
+	}
+
+	return rel.Info.Status.String(), nil
+}
+
+// run calls f when the context has not been cancelled, Helm does not support
+// cancellation so f is always allowed to complete and is bounded by the Helm timeout
+func (h *HelmImpl) run(f func() error) error {
+	if err := h.ctx.Err(); err != nil {
+		return err
+	}
+
+	return f()
+}
+
+// loadChart locates and validates the chart and merges the values
+func (h *HelmImpl) loadChart(
+	cpo *action.ChartPathOptions,
+	name, chartPath, valuesPath string,
+	valuesString map[string]string) (*chart.Chart, map[string]interface{}, error) {
 
 	settings := cli.EnvSettings{}
 	p := getter.All(&settings)
@@ -69,54 +219,27 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 	}
 
 	h.log.Debug("Creating chart from config", "ref", name, "path", chartPath)
-	cp, err := client.ChartPathOptions.LocateChart(chartPath, &settings)
+	cp, err := cpo.LocateChart(chartPath, &settings)
 	if err != nil {
-		return xerrors.Errorf("Error locating chart: %w", err)
+		return nil, nil, xerrors.Errorf("Error locating chart: %w", err)
 	}
 
 	h.log.Debug("Loading chart", "ref", name, "path", cp)
 	chartRequested, err := loader.Load(cp)
 	if err != nil {
-		return xerrors.Errorf("Error loading chart: %w", err)
+		return nil, nil, xerrors.Errorf("Error loading chart: %w", err)
 	}
 
 	vals, err := vo.MergeValues(p)
 	if err != nil {
-		return xerrors.Errorf("Error merging Helm values: %w", err)
+		return nil, nil, xerrors.Errorf("Error merging Helm values: %w", err)
 	}
 
 	h.log.Debug("Validate chart", "ref", name)
 	err = chartRequested.Validate()
 	if err != nil {
-		return xerrors.Errorf("Error validating chart: %w", err)
-	}
-
-	h.log.Debug("Run chart", "ref", name)
-	_, err = client.Run(chartRequested, vals)
-	if err != nil {
-		return xerrors.Errorf("Error running chart: %w", err)
-	}
-
-	return nil
-}
-
-// Destroy removes an installed Helm chart from the system
-func (h *HelmImpl) Destroy(kubeConfig, name, namespace string) error {
-	s := kube.GetConfig(kubeConfig, "default", namespace)
-	cfg := &action.Configuration{}
-	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
-		h.log.Debug("Helm debug message", "message", fmt.Sprintf(format, v...))
-	})
-
-	//settings := cli.EnvSettings{}
-	//p := getter.All(&settings)
-	//vo := values.Options{}
-	client := action.NewUninstall(cfg)
-	_, err = client.Run(name)
-	if err != nil {
-		h.log.Debug("Unable to remove chart, exit silently", "err", err)
-		return err
+		return nil, nil, xerrors.Errorf("Error validating chart: %w", err)
 	}
 
-	return nil
+	return chartRequested, vals, nil
 }
@@ -34,19 +34,44 @@ type KubernetesImpl struct {
 	configPath string
 	timeout    time.Duration
 	l          hclog.Logger
+	ctx        context.Context
 }
 
 // NewKubernetes creates a new client for interacting with Kubernetes clusters
 func NewKubernetes(t time.Duration, l hclog.Logger) Kubernetes {
-	return &KubernetesImpl{timeout: t, l: l}
+	return &KubernetesImpl{timeout: t, l: l, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the client which uses the given context
+// for all requests to the Kubernetes API, when the context is cancelled
+// any in flight requests are aborted
+func (k *KubernetesImpl) WithContext(ctx context.Context) Kubernetes {
+	kc := *k
+	kc.ctx = ctx
+
+	return &kc
+}
+
+// KubernetesWithContext returns a copy of the given Kubernetes client which uses
+// ctx, if the implementation does not support cancellation k is returned unchanged
+func KubernetesWithContext(ctx context.Context, k Kubernetes) Kubernetes {
+	if c, ok := k.(interface {
+		WithContext(context.Context) Kubernetes
+	}); ok {
+		return c.WithContext(ctx)
+	}
+
+	return k
 }
 
 // SetConfig for the Kubernetes cluster and clones the client
 func (k *KubernetesImpl) SetConfig(kubeconfig string) (Kubernetes, error) {
 	kc := NewKubernetes(k.timeout, k.l).(*KubernetesImpl)
 
+	kc.ctx = k.ctx
 	kc.configPath = kubeconfig
 	kc.l = kc.l.With("config", kc.configPath)
+	timeout := contextTimeout(kc.ctx, kc.timeout)
 	st := time.Now()
 	for {
 		err := kc.setConfig()
@@ -54,9 +79,13 @@ func (k *KubernetesImpl) SetConfig(kubeconfig string) (Kubernetes, error) {
 			break
 		}
 
-		if time.Now().Sub(st) > kc.timeout {
+		if time.Now().Sub(st) > timeout {
 			return nil, xerrors.Errorf("Error waiting for kubeclient: %w", err)
 		}
+
+		if kc.ctx.Err() != nil {
+			return nil, xerrors.Errorf("Error waiting for kubeclient: %w", kc.ctx.Err())
+		}
 	}
 
 	return kc, nil
@@ -93,7 +122,7 @@ func (k *KubernetesImpl) GetPods(selector string) (*v1.PodList, error) {
 	lo := metav1.ListOptions{
 		LabelSelector: selector,
 	}
-	pl, err := k.client.Pods("").List(context.Background(), lo)
+	pl, err := k.client.Pods("").List(k.ctx, lo)
 	if err != nil {
 		return nil, err
 	}
@@ -112,8 +141,13 @@ func (k *KubernetesImpl) Apply(files []string, waitUntilReady bool) error {
 	s := kube.GetConfig(k.configPath, "default", "default")
 	kc := kube.New(s)
 
-	// process the files
+	// process the files, the Kubernetes client does not support
+	// cancellation so stop between files when the context is cancelled
 	for _, f := range allFiles {
+		if err := k.ctx.Err(); err != nil {
+			return err
+		}
+
 		k.l.Debug("Applying Kubernetes config", "file", f)
 		err := applyFile(f, waitUntilReady, kc)
 		if err != nil {
@@ -136,6 +170,10 @@ func (k *KubernetesImpl) Delete(files []string) error {
 
 	// process the files
 	for _, f := range allFiles {
+		if err := k.ctx.Err(); err != nil {
+			return err
+		}
+
 		k.l.Debug("Removing Kubernetes config", "file", f)
 
 		err := deleteFile(f, kc)
@@ -173,6 +211,10 @@ func (k *KubernetesImpl) healthCheckSingle(selector string, timeout time.Duratio
 			return fmt.Errorf("Timeout waiting for pods %s to start", selector)
 		}
 
+		if err := k.ctx.Err(); err != nil {
+			return err
+		}
+
 		// GetPods may return an error if the API server is not available
 		pl, err := k.GetPods(selector)
 		if err != nil {
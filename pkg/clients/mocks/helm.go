@@ -19,3 +19,15 @@ func (h *MockHelm) Destroy(kubeConfig, name, namespace string) error {
 
 	return args.Error(0)
 }
+
+func (h *MockHelm) Upgrade(kubeConfig, name, namespace string, chartPath, valuesPath string, valueString map[string]string) error {
+	args := h.Called(kubeConfig, name, namespace, chartPath, valuesPath, valueString)
+
+	return args.Error(0)
+}
+
+func (h *MockHelm) Status(kubeConfig, name, namespace string) (string, error) {
+	args := h.Called(kubeConfig, name, namespace)
+
+	return args.String(0), args.Error(1)
+}
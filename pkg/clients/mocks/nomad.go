@@ -41,6 +41,12 @@ func (m *MockNomad) JobRunning(job string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockNomad) JobStatus(file string) (string, error) {
+	args := m.Called(file)
+
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockNomad) Endpoints(job, group, task string) ([]map[string]string, error) {
 	args := m.Called(job, group, task)
 
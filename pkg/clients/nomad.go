@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -25,6 +26,9 @@ type Nomad interface {
 	ParseJob(file string) ([]byte, error)
 	// JobRunning returns true if all allocations for a job are running
 	JobRunning(job string) (bool, error)
+	// JobStatus returns the status of the job defined in the given file,
+	// an empty string is returned when the job does not exist
+	JobStatus(file string) (string, error)
 	// HealthCheckAPI uses the Nomad API to check that all servers and nodes
 	// are ready. The function will block until either all nodes are healthy or the
 	// timeout period elapses.
@@ -40,11 +44,34 @@ type NomadImpl struct {
 	c          *utils.ClusterConfig
 	backoff    time.Duration
 	context    string
+	ctx        context.Context
 }
 
 // NewNomad creates a new Nomad client
 func NewNomad(c HTTP, backoff time.Duration, l hclog.Logger) Nomad {
-	return &NomadImpl{httpClient: c, l: l, backoff: backoff}
+	return &NomadImpl{httpClient: c, l: l, backoff: backoff, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the client which uses the given context
+// for all requests to the Nomad API, when the context is cancelled any
+// in flight requests are aborted
+func (n *NomadImpl) WithContext(ctx context.Context) Nomad {
+	nc := *n
+	nc.ctx = ctx
+
+	return &nc
+}
+
+// NomadWithContext returns a copy of the given Nomad client which uses ctx,
+// if the implementation does not support cancellation n is returned unchanged
+func NomadWithContext(ctx context.Context, n Nomad) Nomad {
+	if c, ok := n.(interface {
+		WithContext(context.Context) Nomad
+	}); ok {
+		return c.WithContext(ctx)
+	}
+
+	return n
 }
 
 type validateRequest struct {
@@ -79,12 +106,16 @@ func (n *NomadImpl) HealthCheckAPI(timeout time.Duration) error {
 			return fmt.Errorf("Timeout waiting for Nomad healthcheck %s", address)
 		}
 
+		if err := n.ctx.Err(); err != nil {
+			return err
+		}
+
 		rq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/nodes", address), nil)
 		if err != nil {
 			return err
 		}
 
-		resp, err := n.httpClient.Do(rq)
+		resp, err := n.httpClient.Do(rq.WithContext(n.ctx))
 		if err == nil && resp.StatusCode == 200 {
 			nodes := []map[string]interface{}{}
 			// check number of nodes
@@ -163,7 +194,7 @@ func (n *NomadImpl) Create(files []string) error {
 			return xerrors.Errorf("Unable to create http request: %w", err)
 		}
 
-		resp, err := n.httpClient.Do(r)
+		resp, err := n.httpClient.Do(r.WithContext(n.ctx))
 		if err != nil {
 			return xerrors.Errorf("Unable to submit job: %w", err)
 		}
@@ -193,7 +224,7 @@ func (n *NomadImpl) Stop(files []string) error {
 			return xerrors.Errorf("Unable to create http request: %w", err)
 		}
 
-		resp, err := n.httpClient.Do(r)
+		resp, err := n.httpClient.Do(r.WithContext(n.ctx))
 		if err != nil {
 			return xerrors.Errorf("Unable to submit job: %w", err)
 		}
@@ -227,7 +258,7 @@ func (n *NomadImpl) ParseJob(file string) ([]byte, error) {
 		return nil, xerrors.Errorf("Unable to create http request: %w", err)
 	}
 
-	resp, err := n.httpClient.Do(r)
+	resp, err := n.httpClient.Do(r.WithContext(n.ctx))
 	if err != nil {
 		return nil, xerrors.Errorf("Unable to validate job: %w", err)
 	}
@@ -267,6 +298,46 @@ func (n *NomadImpl) JobRunning(job string) (bool, error) {
 	return true, nil
 }
 
+// JobStatus returns the status of the job defined in the given file i.e. running or dead,
+// if the job has not been registered with the cluster an empty string is returned
+func (n *NomadImpl) JobStatus(file string) (string, error) {
+	id, err := n.getJobID(file)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/job/%s", n.c.APIAddress(utils.Context(n.context)), id), nil)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to create http request: %w", err)
+	}
+
+	resp, err := n.httpClient.Do(r.WithContext(n.ctx))
+	if err != nil {
+		return "", xerrors.Errorf("Unable to query job: %w", err)
+	}
+
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("Error querying job, got status code %d", resp.StatusCode)
+	}
+
+	job := map[string]interface{}{}
+	err = json.NewDecoder(resp.Body).Decode(&job)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to read job status: %w", err)
+	}
+
+	status, _ := job["Status"].(string)
+	return status, nil
+}
+
 // Endpoints returns a list of endpoints for a cluster
 func (n *NomadImpl) Endpoints(job, group, task string) ([]map[string]string, error) {
 	jobs, err := n.getJobAllocations(job)
@@ -283,7 +354,7 @@ func (n *NomadImpl) Endpoints(job, group, task string) ([]map[string]string, err
 			return nil, xerrors.Errorf("Unable to create http request: %w", err)
 		}
 
-		resp, err := n.httpClient.Do(r)
+		resp, err := n.httpClient.Do(r.WithContext(n.ctx))
 		if err != nil {
 			return nil, xerrors.Errorf("Unable to get allocation: %w", err)
 		}
@@ -363,7 +434,7 @@ func (n *NomadImpl) getJobAllocations(job string) ([]map[string]interface{}, err
 		return nil, xerrors.Errorf("Unable to create http request: %w", err)
 	}
 
-	resp, err := n.httpClient.Do(r)
+	resp, err := n.httpClient.Do(r.WithContext(n.ctx))
 	if err != nil {
 		return nil, xerrors.Errorf("Unable to query job: %w", err)
 	}
@@ -2,6 +2,7 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -102,6 +103,22 @@ func TestNomadCreateSubmitsJob(t *testing.T) {
 	mh.AssertNumberOfCalls(t, "Do", 2)
 }
 
+func TestNomadCreateWithContextSendsRequestsWithContext(t *testing.T) {
+	fp, _, mh := setupNomadTests(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NomadWithContext(ctx, NewNomad(mh, 1*time.Millisecond, hclog.NewNullLogger()))
+	c.SetConfig(fp, "local")
+
+	err := c.Create([]string{"../../examples/nomad/app_config/example.nomad"})
+	assert.NoError(t, err)
+
+	r := getCalls(&mh.Mock, "Do")[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, ctx, r.Context())
+}
+
 func TestNomadCreateSubmitErrorReturnsError(t *testing.T) {
 	fp, _, mh := setupNomadTests(t)
 
@@ -249,6 +266,62 @@ func TestNomadJobStatusReturnsStatus(t *testing.T) {
 	assert.True(t, s)
 }
 
+func TestNomadJobStatusReturnsStatusForJobInFile(t *testing.T) {
+	fp, _, mh := setupNomadTests(t)
+
+	removeOn(&mh.Mock, "Do")
+	mh.On("Do", mock.Anything, mock.Anything, mock.Anything).Once().Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(validateResponse))),
+		},
+		nil,
+	)
+	mh.On("Do", mock.Anything, mock.Anything, mock.Anything).Once().Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"ID": "example_1", "Status": "running"}`))),
+		},
+		nil,
+	)
+
+	c := NewNomad(mh, 1*time.Millisecond, hclog.NewNullLogger())
+	c.SetConfig(fp, "local")
+
+	s, err := c.JobStatus("../../examples/nomad/app_config/example.nomad")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "running", s)
+}
+
+func TestNomadJobStatusReturnsEmptyWhenJobNotFound(t *testing.T) {
+	fp, _, mh := setupNomadTests(t)
+
+	removeOn(&mh.Mock, "Do")
+	mh.On("Do", mock.Anything, mock.Anything, mock.Anything).Once().Return(
+		&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(validateResponse))),
+		},
+		nil,
+	)
+	mh.On("Do", mock.Anything, mock.Anything, mock.Anything).Once().Return(
+		&http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte("job not found"))),
+		},
+		nil,
+	)
+
+	c := NewNomad(mh, 1*time.Millisecond, hclog.NewNullLogger())
+	c.SetConfig(fp, "local")
+
+	s, err := c.JobStatus("../../examples/nomad/app_config/example.nomad")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", s)
+}
+
 func TestNomadHealthCallsAPI(t *testing.T) {
 	fp, _, mh := setupNomadTests(t)
 
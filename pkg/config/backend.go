@@ -0,0 +1,370 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclparse"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+)
+
+// StateBackendLocal stores the state in a file, this is the default backend
+const StateBackendLocal = "local"
+
+// StateBackendHTTP stores the state on a remote server using the
+// same protocol as the Terraform http backend
+const StateBackendHTTP = "http"
+
+// StateBackend stores the state and the lock which protects it
+type StateBackend interface {
+	// Read returns the stored state, nil is returned when no state exists
+	Read() ([]byte, error)
+	// Write replaces the stored state
+	Write(d []byte) error
+	// Delete removes the stored state
+	Delete() error
+	// Lock acquires the state lock, when the state is locked by another process
+	// the lock is retried until the timeout elapses. A StateLockedError is
+	// returned if the lock can not be acquired
+	Lock(timeout time.Duration) (*StateLock, error)
+	// Unlock releases the given lock
+	Unlock(l *StateLock) error
+	// ForceUnlock removes the lock regardless of the holder and returns the
+	// holder of the lock when it is known
+	ForceUnlock() (*StateLock, error)
+}
+
+// StateBackendConfig defines where the state is stored, the backend can be
+// configured in the blueprint with a state block or with the state flags
+type StateBackendConfig struct {
+	// Backend is the type of backend, local or http
+	Backend string `hcl:"backend" json:"backend"`
+
+	// Path of the state file for the local backend
+	Path string `hcl:"path,optional" json:"path,omitempty"`
+
+	// Address of the state for the http backend
+	Address string `hcl:"address,optional" json:"address,omitempty"`
+	// UpdateMethod is the HTTP method used to write the state, default POST
+	UpdateMethod string `hcl:"update_method,optional" json:"update_method,omitempty"`
+	// LockAddress is the address used to lock the state, locking is
+	// disabled when not set
+	LockAddress string `hcl:"lock_address,optional" json:"lock_address,omitempty"`
+	// LockMethod is the HTTP method used to lock the state, default LOCK
+	LockMethod string `hcl:"lock_method,optional" json:"lock_method,omitempty"`
+	// UnlockAddress is the address used to unlock the state
+	UnlockAddress string `hcl:"unlock_address,optional" json:"unlock_address,omitempty"`
+	// UnlockMethod is the HTTP method used to unlock the state, default UNLOCK
+	UnlockMethod string `hcl:"unlock_method,optional" json:"unlock_method,omitempty"`
+	// Username for HTTP basic authentication
+	Username string `hcl:"username,optional" json:"username,omitempty"`
+	// Password for HTTP basic authentication
+	Password string `hcl:"password,optional" json:"password,omitempty"`
+}
+
+// StateBackend creates the backend defined by the config
+func (s *StateBackendConfig) StateBackend() (StateBackend, error) {
+	switch s.Backend {
+	case StateBackendLocal:
+		if s.Path == "" {
+			return NewLocalStateBackend(utils.StatePath()), nil
+		}
+
+		return NewLocalStateBackend(s.Path), nil
+	case StateBackendHTTP:
+		if s.Address == "" {
+			return nil, fmt.Errorf("The address must be set for the http state backend")
+		}
+
+		if s.UnlockAddress == "" {
+			s.UnlockAddress = s.LockAddress
+		}
+
+		return NewHTTPStateBackend(*s), nil
+	}
+
+	return nil, fmt.Errorf("Unknown state backend %s, valid backends are %s and %s", s.Backend, StateBackendLocal, StateBackendHTTP)
+}
+
+// DefaultStateBackend returns the backend for the current workspace, this is the
+// backend saved with SaveStateBackendConfig or the local state file
+func DefaultStateBackend() (StateBackend, error) {
+	sc, err := LoadStateBackendConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if sc == nil {
+		return NewLocalStateBackend(utils.StatePath()), nil
+	}
+
+	return sc.StateBackend()
+}
+
+// SaveStateBackendConfig saves the backend config for the current workspace so
+// that commands which do not read the blueprint use the same backend
+func SaveStateBackendConfig(sc *StateBackendConfig) error {
+	_, err := sc.StateBackend()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(utils.StateDir(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("Unable to create state folder: %s", err)
+	}
+
+	d, err := json.Marshal(sc)
+	if err != nil {
+		return err
+	}
+
+	// the config can contain credentials
+	err = ioutil.WriteFile(stateBackendConfigPath(), d, 0600)
+	if err != nil {
+		return fmt.Errorf("Unable to save state backend config: %s", err)
+	}
+
+	return nil
+}
+
+// LoadStateBackendConfig returns the backend config for the current
+// workspace, nil is returned when no config has been saved
+func LoadStateBackendConfig() (*StateBackendConfig, error) {
+	d, err := ioutil.ReadFile(stateBackendConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Unable to read state backend config: %s", err)
+	}
+
+	sc := &StateBackendConfig{}
+	err = json.Unmarshal(d, sc)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read state backend config: %s", err)
+	}
+
+	return sc, nil
+}
+
+// RemoveStateBackendConfig removes the saved backend config for the current workspace
+func RemoveStateBackendConfig() error {
+	err := os.Remove(stateBackendConfigPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Unable to remove state backend config: %s", err)
+	}
+
+	return nil
+}
+
+func stateBackendConfigPath() string {
+	return filepath.Join(utils.StateDir(), "/backend.json")
+}
+
+// ParseStateBackendConfig reads the state block from the blueprint in the given folder,
+// nil is returned when the blueprint does not configure a backend.
+// The state block can be in any of the .yard or .hcl files in the folder, only one
+// state block can be defined.
+// The backend is read before the rest of the config so variables can not be used,
+// functions such as env can be used to set credentials.
+func ParseStateBackendConfig(folder string) (*StateBackendConfig, error) {
+	if utils.IsHCLFile(folder) {
+		return nil, nil
+	}
+
+	files := []string{}
+	for _, ext := range []string{"*.yard", "*.hcl"} {
+		f, err := filepath.Glob(filepath.Join(folder, ext))
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, f...)
+	}
+
+	parser := hclparse.NewParser()
+
+	var state *hcl.Block
+	for _, file := range files {
+		f, diag := parser.ParseHCLFile(file)
+		if diag.HasErrors() {
+			return nil, errors.New(diag.Error())
+		}
+
+		content, _, diag := f.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: "state"}},
+		})
+		if diag.HasErrors() {
+			return nil, errors.New(diag.Error())
+		}
+
+		for _, b := range content.Blocks {
+			if state != nil {
+				d := &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate state block",
+					Detail:   fmt.Sprintf("The state backend has already been configured at %s, only one state block can be defined.", state.DefRange),
+					Subject:  b.DefRange.Ptr(),
+				}
+
+				return nil, errors.New(d.Error())
+			}
+
+			state = b
+		}
+	}
+
+	if state == nil {
+		return nil, nil
+	}
+
+	sc := &StateBackendConfig{}
+
+	diag := gohcl.DecodeBody(state.Body, buildContext(), sc)
+	if diag.HasErrors() {
+		return nil, errors.New(diag.Error())
+	}
+
+	// relative paths are relative to the blueprint
+	if sc.Path != "" && !filepath.IsAbs(sc.Path) {
+		sc.Path = filepath.Join(folder, sc.Path)
+	}
+
+	_, err := sc.StateBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+// LocalStateBackend stores the state in a file, the file is written atomically
+// and the previous versions are kept as backups
+type LocalStateBackend struct {
+	path string
+}
+
+// NewLocalStateBackend creates a backend which stores the state at path
+func NewLocalStateBackend(path string) *LocalStateBackend {
+	return &LocalStateBackend{path}
+}
+
+// Read the state file, nil is returned when the file does not exist
+func (l *LocalStateBackend) Read() ([]byte, error) {
+	d, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Unable to read state: %s", err)
+	}
+
+	return d, nil
+}
+
+// Write the state to a temporary file which replaces the existing state
+// so that the state is never partially written, the previous state is
+// kept as a backup
+func (l *LocalStateBackend) Write(d []byte) error {
+	dir := filepath.Dir(l.path)
+
+	// if it does not exist create the state folder
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("Unable to create state folder: %s", err)
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(l.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("Unable to create temporary state file: %s", err)
+	}
+
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	_, err = f.Write(d)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to write state: %s", err)
+	}
+
+	err = backupState(l.path)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, l.path)
+}
+
+// Delete the state file, backups are not removed
+func (l *LocalStateBackend) Delete() error {
+	return os.RemoveAll(l.path)
+}
+
+// Lock the state by creating a lock file next to the state file
+func (l *LocalStateBackend) Lock(timeout time.Duration) (*StateLock, error) {
+	return lockWithRetry(timeout, func() (*StateLock, error) {
+		return tryLockState(l.path)
+	})
+}
+
+// Unlock removes the lock file when it is still held by the given lock
+func (l *LocalStateBackend) Unlock(sl *StateLock) error {
+	return unlockState(l.path, sl)
+}
+
+// ForceUnlock removes the lock file regardless of the holder
+func (l *LocalStateBackend) ForceUnlock() (*StateLock, error) {
+	return UnlockState(l.path)
+}
+
+// backupState copies the state at path to path.1, existing backups
+// are rotated and the oldest backup is removed
+func backupState(path string) error {
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("Unable to read state for backup: %s", err)
+	}
+
+	os.Remove(backupPath(path, StateBackups))
+
+	for i := StateBackups - 1; i > 0; i-- {
+		err := os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Unable to rotate state backups: %s", err)
+		}
+	}
+
+	err = ioutil.WriteFile(backupPath(path, 1), d, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to write state backup: %s", err)
+	}
+
+	return nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// HTTPStateBackend stores the state on a remote server using the same protocol
+// as the Terraform http backend. The state is read with GET and written with POST
+// to the address, the state is locked and unlocked by sending the lock with the
+// LOCK and UNLOCK methods to the lock address. A locked state returns the status
+// 423 or 409 along with the current holder of the lock.
+type HTTPStateBackend struct {
+	config StateBackendConfig
+	client *http.Client
+
+	mutex  sync.Mutex
+	lockID string
+}
+
+// NewHTTPStateBackend creates a backend which stores the state on a remote server
+func NewHTTPStateBackend(c StateBackendConfig) *HTTPStateBackend {
+	if c.UpdateMethod == "" {
+		c.UpdateMethod = http.MethodPost
+	}
+
+	if c.LockMethod == "" {
+		c.LockMethod = "LOCK"
+	}
+
+	if c.UnlockMethod == "" {
+		c.UnlockMethod = "UNLOCK"
+	}
+
+	return &HTTPStateBackend{config: c, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Read the state from the remote server, nil is returned when the server
+// does not have any state
+func (h *HTTPStateBackend) Read() ([]byte, error) {
+	resp, err := h.do(http.MethodGet, h.config.Address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read state: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		d, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read state: %s", err)
+		}
+
+		if len(d) == 0 {
+			return nil, nil
+		}
+
+		return d, nil
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("Unable to read state from %s, server returned status %d", h.config.Address, resp.StatusCode)
+}
+
+// Write the state to the remote server, when the state is locked the
+// ID of the lock is sent with the request
+func (h *HTTPStateBackend) Write(d []byte) error {
+	addr := h.config.Address
+
+	h.mutex.Lock()
+	if h.lockID != "" {
+		addr = fmt.Sprintf("%s?ID=%s", addr, url.QueryEscape(h.lockID))
+	}
+	h.mutex.Unlock()
+
+	resp, err := h.do(h.config.UpdateMethod, addr, d)
+	if err != nil {
+		return fmt.Errorf("Unable to write state: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+
+	return fmt.Errorf("Unable to write state to %s, server returned status %d", h.config.Address, resp.StatusCode)
+}
+
+// Delete the state from the remote server
+func (h *HTTPStateBackend) Delete() error {
+	resp, err := h.do(http.MethodDelete, h.config.Address, nil)
+	if err != nil {
+		return fmt.Errorf("Unable to delete state: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+
+	return fmt.Errorf("Unable to delete state at %s, server returned status %d", h.config.Address, resp.StatusCode)
+}
+
+// Lock the state on the remote server, when no lock address has been
+// configured the state is not locked
+func (h *HTTPStateBackend) Lock(timeout time.Duration) (*StateLock, error) {
+	if h.config.LockAddress == "" {
+		return &StateLock{backend: h}, nil
+	}
+
+	return lockWithRetry(timeout, h.tryLock)
+}
+
+func (h *HTTPStateBackend) tryLock() (*StateLock, error) {
+	l := newStateLock(h, h.config.Address)
+
+	d, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.do(h.config.LockMethod, h.config.LockAddress, d)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to lock state: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		h.mutex.Lock()
+		h.lockID = l.ID
+		h.mutex.Unlock()
+
+		return l, nil
+	case http.StatusLocked, http.StatusConflict:
+		// the holder is informational, the server may not return it
+		holder := &StateLock{}
+		json.NewDecoder(resp.Body).Decode(holder)
+
+		return nil, StateLockedError{holder}
+	}
+
+	return nil, fmt.Errorf("Unable to lock state at %s, server returned status %d", h.config.LockAddress, resp.StatusCode)
+}
+
+// Unlock the state on the remote server
+func (h *HTTPStateBackend) Unlock(l *StateLock) error {
+	if h.config.UnlockAddress == "" || l.ID == "" {
+		return nil
+	}
+
+	d, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	err = h.unlock(d)
+	if err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	if h.lockID == l.ID {
+		h.lockID = ""
+	}
+	h.mutex.Unlock()
+
+	return nil
+}
+
+// ForceUnlock removes the lock on the remote server regardless of the holder,
+// the unlock request is sent without a lock so the holder is not known
+func (h *HTTPStateBackend) ForceUnlock() (*StateLock, error) {
+	if h.config.UnlockAddress == "" {
+		return nil, fmt.Errorf("State locking is not configured for the http state backend")
+	}
+
+	err := h.unlock(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StateLock{}, nil
+}
+
+func (h *HTTPStateBackend) unlock(d []byte) error {
+	resp, err := h.do(h.config.UnlockMethod, h.config.UnlockAddress, d)
+	if err != nil {
+		return fmt.Errorf("Unable to unlock state: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unable to unlock state at %s, server returned status %d", h.config.UnlockAddress, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (h *HTTPStateBackend) do(method, addr string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, addr, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if h.config.Username != "" {
+		req.SetBasicAuth(h.config.Username, h.config.Password)
+	}
+
+	return h.client.Do(req)
+}
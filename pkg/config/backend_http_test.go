@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+// testStateServer is a stand-in for a remote state server which
+// implements the Terraform http backend protocol
+type testStateServer struct {
+	mutex sync.Mutex
+	state []byte
+	lock  *StateLock
+}
+
+func (s *testStateServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if u, p, ok := r.BasicAuth(); ok && (u != "nic" || p != "secret") {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if s.state == nil {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		rw.Write(s.state)
+	case http.MethodPost:
+		if s.lock != nil && r.URL.Query().Get("ID") != s.lock.ID {
+			rw.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		s.state, _ = ioutil.ReadAll(r.Body)
+	case http.MethodDelete:
+		s.state = nil
+	case "LOCK":
+		if s.lock != nil {
+			rw.WriteHeader(http.StatusLocked)
+			json.NewEncoder(rw).Encode(s.lock)
+			return
+		}
+
+		s.lock = &StateLock{}
+		json.NewDecoder(r.Body).Decode(s.lock)
+	case "UNLOCK":
+		l := &StateLock{}
+		json.NewDecoder(r.Body).Decode(l)
+
+		// an unlock without a lock id is a force unlock
+		if s.lock != nil && l.ID != "" && l.ID != s.lock.ID {
+			rw.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		s.lock = nil
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func setupHTTPBackend(t *testing.T) (*HTTPStateBackend, *testStateServer) {
+	s := &testStateServer{}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	b := NewHTTPStateBackend(StateBackendConfig{
+		Backend:       StateBackendHTTP,
+		Address:       ts.URL + "/state",
+		LockAddress:   ts.URL + "/state/lock",
+		UnlockAddress: ts.URL + "/state/lock",
+	})
+
+	return b, s
+}
+
+func TestHTTPBackendReadWithNoStateReturnsNil(t *testing.T) {
+	b, _ := setupHTTPBackend(t)
+
+	d, err := b.Read()
+	assert.NoError(t, err)
+	assert.Nil(t, d)
+
+	c := New()
+	err = c.Load(b)
+	assert.Equal(t, StateNotFoundError, err)
+}
+
+func TestHTTPBackendSavesAndLoadsState(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+
+	c := New()
+	c.AddResource(NewNetwork("cloud"))
+
+	err := c.Save(b)
+	assert.NoError(t, err)
+	assert.Contains(t, string(s.state), `"version":1`)
+
+	c2 := New()
+	err = c2.Load(b)
+	assert.NoError(t, err)
+
+	_, err = c2.FindResource("network.cloud")
+	assert.NoError(t, err)
+}
+
+func TestHTTPBackendDeletesState(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+	s.state = []byte("{}")
+
+	err := b.Delete()
+	assert.NoError(t, err)
+	assert.Nil(t, s.state)
+}
+
+func TestHTTPBackendLockSendsIDWithWrite(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+
+	l, err := b.Lock(0)
+	assert.NoError(t, err)
+	assert.NotNil(t, s.lock)
+	assert.Equal(t, l.ID, s.lock.ID)
+
+	// the server rejects writes without the lock id
+	err = New().Save(b)
+	assert.NoError(t, err)
+
+	err = l.Unlock()
+	assert.NoError(t, err)
+	assert.Nil(t, s.lock)
+}
+
+func TestHTTPBackendLockWhenLockedReturnsError(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+	s.lock = &StateLock{ID: "abc", Operation: "shipyard run", Who: "nic@laptop"}
+
+	_, err := b.Lock(0)
+	assert.Error(t, err)
+	assert.IsType(t, StateLockedError{}, err)
+	assert.Equal(t, "nic@laptop", err.(StateLockedError).Holder.Who)
+
+	// writes are rejected by the server while another process holds the lock
+	err = New().Save(b)
+	assert.Error(t, err)
+}
+
+func TestHTTPBackendForceUnlockRemovesLock(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+	s.lock = &StateLock{ID: "abc"}
+
+	_, err := b.ForceUnlock()
+	assert.NoError(t, err)
+	assert.Nil(t, s.lock)
+}
+
+func TestHTTPBackendWithoutLockAddressDoesNotLock(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+	b.config.LockAddress = ""
+
+	l, err := b.Lock(0)
+	assert.NoError(t, err)
+	assert.Nil(t, s.lock)
+
+	err = l.Unlock()
+	assert.NoError(t, err)
+}
+
+func TestHTTPBackendSendsBasicAuth(t *testing.T) {
+	b, s := setupHTTPBackend(t)
+	s.state = []byte("{}")
+
+	b.config.Username = "nic"
+	b.config.Password = "wrong"
+
+	_, err := b.Read()
+	assert.Error(t, err)
+
+	b.config.Password = "secret"
+
+	d, err := b.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(d))
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupBackendTests(t *testing.T) {
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+
+	t.Cleanup(func() {
+		os.Setenv(utils.HomeEnvName(), home)
+	})
+}
+
+func TestLocalBackendReadWithNoStateReturnsNil(t *testing.T) {
+	b := NewLocalStateBackend(filepath.Join(t.TempDir(), "state.json"))
+
+	d, err := b.Read()
+	assert.NoError(t, err)
+	assert.Nil(t, d)
+}
+
+func TestLocalBackendDeletesState(t *testing.T) {
+	sp := filepath.Join(t.TempDir(), "state.json")
+	b := NewLocalStateBackend(sp)
+
+	err := b.Write([]byte("{}"))
+	assert.NoError(t, err)
+
+	err = b.Delete()
+	assert.NoError(t, err)
+	assert.NoFileExists(t, sp)
+}
+
+func TestStateBackendConfigWithUnknownBackendReturnsError(t *testing.T) {
+	sc := &StateBackendConfig{Backend: "s3"}
+
+	_, err := sc.StateBackend()
+	assert.Error(t, err)
+}
+
+func TestStateBackendConfigHTTPWithoutAddressReturnsError(t *testing.T) {
+	sc := &StateBackendConfig{Backend: StateBackendHTTP}
+
+	_, err := sc.StateBackend()
+	assert.Error(t, err)
+}
+
+func TestStateBackendConfigHTTPDefaultsUnlockAddress(t *testing.T) {
+	sc := &StateBackendConfig{Backend: StateBackendHTTP, Address: "http://localhost/state", LockAddress: "http://localhost/lock"}
+
+	b, err := sc.StateBackend()
+	assert.NoError(t, err)
+
+	hb := b.(*HTTPStateBackend)
+	assert.Equal(t, "http://localhost/lock", hb.config.UnlockAddress)
+	assert.Equal(t, "LOCK", hb.config.LockMethod)
+	assert.Equal(t, "POST", hb.config.UpdateMethod)
+}
+
+func TestDefaultStateBackendIsLocalStateFile(t *testing.T) {
+	setupBackendTests(t)
+
+	b, err := DefaultStateBackend()
+	assert.NoError(t, err)
+	assert.Equal(t, utils.StatePath(), b.(*LocalStateBackend).path)
+}
+
+func TestDefaultStateBackendUsesSavedConfig(t *testing.T) {
+	setupBackendTests(t)
+
+	err := SaveStateBackendConfig(&StateBackendConfig{Backend: StateBackendHTTP, Address: "http://localhost/state"})
+	assert.NoError(t, err)
+
+	b, err := DefaultStateBackend()
+	assert.NoError(t, err)
+	assert.IsType(t, &HTTPStateBackend{}, b)
+
+	err = RemoveStateBackendConfig()
+	assert.NoError(t, err)
+
+	b, err = DefaultStateBackend()
+	assert.NoError(t, err)
+	assert.IsType(t, &LocalStateBackend{}, b)
+}
+
+func TestSaveStateBackendConfigWithInvalidConfigReturnsError(t *testing.T) {
+	setupBackendTests(t)
+
+	err := SaveStateBackendConfig(&StateBackendConfig{Backend: StateBackendHTTP})
+	assert.Error(t, err)
+	assert.NoFileExists(t, stateBackendConfigPath())
+}
+
+func TestParseStateBackendConfigReadsBlueprint(t *testing.T) {
+	os.Setenv("STATE_PASSWORD", "secret")
+	defer os.Unsetenv("STATE_PASSWORD")
+
+	dir, cleanup := createTestFiles(t, backendResources)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", backendBlueprint)
+
+	sc, err := ParseStateBackendConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, StateBackendHTTP, sc.Backend)
+	assert.Equal(t, "http://localhost:8080/state", sc.Address)
+	assert.Equal(t, "http://localhost:8080/state/lock", sc.LockAddress)
+	assert.Equal(t, "secret", sc.Password)
+}
+
+func TestParseStateBackendConfigWithoutStateBlockReturnsNil(t *testing.T) {
+	dir, cleanup := createTestFiles(t, backendResources)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", `title = "Test"`)
+
+	sc, err := ParseStateBackendConfig(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, sc)
+}
+
+func TestParseStateBackendConfigResolvesLocalPath(t *testing.T) {
+	dir, cleanup := createTestFiles(t, backendResources)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", backendLocalBlueprint)
+
+	sc, err := ParseStateBackendConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "state/state.json"), sc.Path)
+}
+
+func TestParseStateBackendConfigReadsHCLFiles(t *testing.T) {
+	dir, cleanup := createTestFiles(t, backendResources+backendLocalBlueprint)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", `title = "Test"`)
+
+	sc, err := ParseStateBackendConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, StateBackendLocal, sc.Backend)
+
+	// the state block is ignored when parsing the resources
+	c := New()
+	err = ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.NoError(t, err)
+
+	_, err = c.FindResource("network.cloud")
+	assert.NoError(t, err)
+}
+
+func TestParseStateBackendConfigWithMultipleStateBlocksReturnsError(t *testing.T) {
+	dir, cleanup := createTestFiles(t, backendLocalBlueprint)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", backendBlueprint)
+
+	_, err := ParseStateBackendConfig(dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Duplicate state block")
+}
+
+func TestParseBlueprintWithStateBlock(t *testing.T) {
+	dir, cleanup := createTestFiles(t, backendResources)
+	defer cleanup()
+	createNamedFile(t, dir, "*.yard", backendBlueprint)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Remote state", c.Blueprint.Title)
+	assert.Equal(t, StateBackendHTTP, c.Blueprint.State.Backend)
+}
+
+var backendBlueprint = `
+title = "Remote state"
+
+state {
+  backend      = "http"
+  address      = "http://localhost:8080/state"
+  lock_address = "http://localhost:8080/state/lock"
+  username     = "nic"
+  password     = env("STATE_PASSWORD")
+}
+`
+
+var backendLocalBlueprint = `
+state {
+  backend = "local"
+  path    = "state/state.json"
+}
+`
+
+var backendResources = `
+network "cloud" {
+  subnet = "10.0.0.0/16"
+}
+`
@@ -15,6 +15,10 @@ type Blueprint struct {
 	HealthCheckTimeout string   `hcl:"health_check_timeout,optional" json:"health_check_timeout,omitempty" mapstructure:"health_check_timeout"`
 	Environment        []KV     `hcl:"env,block" json:"environment,omitempty"`
 	ShipyardVersion    string   `hcl:"shipyard_version,optional" json:"shipyard_version,omitempty"`
+
+	// State configures the backend used to store the state, the backend
+	// config is not saved in the state as it can contain credentials
+	State *StateBackendConfig `hcl:"state,block" json:"-"`
 }
 
 // Validate the Blueprint and return errors
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform/dag"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Status defines the current state of a resource
@@ -14,6 +15,8 @@ type Status string
 type ResourceType string
 
 // Applied means the resource has been successfully created
+// if the action is Apply then the resource will be ignored with the next run
+// if the action is Delete then the resource will be removed with the next run
 const Applied Status = "applied"
 
 // PendingCreation means the resource has not yet been created
@@ -25,8 +28,9 @@ const PendingCreation Status = "pending_creation"
 // if the action is Delete then the resource will be removed with the next run
 const PendingModification Status = "pending_modification"
 
-// PendingUpdate means the resource has been requested to be updated
-// if the action is Apply then the resource will be ignored with the next run
+// PendingUpdate means the resource has been created but the config
+// has changed since it was applied
+// if the action is Apply then the resource will be re-created with the next run
 // if the action is Delete then the resource will be removed with the next run
 const PendingUpdate Status = "pending_update"
 
@@ -61,9 +65,25 @@ type ResourceInfo struct {
 	Module string `json:"module,omitempty"`
 	// Enabled determines if a resource is enabled and should be processed
 	Disabled bool `hcl:"disabled,optional" json:"disabled,omitempty"`
+	// Retry defines the policy for retrying the resource when it fails to be created
+	Retry *Retry `hcl:"retry,block" json:"retry,omitempty"`
+	// Timeouts defines the maximum time to create or destroy the resource
+	Timeouts *Timeouts `hcl:"timeouts,block" json:"timeouts,omitempty"`
+	// Lifecycle defines commands which are run after the resource is created and before it is destroyed
+	Lifecycle *Lifecycle `hcl:"lifecycle,block" json:"lifecycle,omitempty"`
+	// Hash is a hash of the config used to create the resource, this is used to detect
+	// changes to the config between runs
+	Hash string `json:"hash,omitempty"`
 
 	// parent container
 	Config *Config `json:"-"`
+
+	// references are the references to other resources from the config
+	references []resourceReference
+
+	// deferred holds the parts of the config which reference other resources,
+	// these are decoded when the resource is applied
+	deferred *deferredBody
 }
 
 func (r *ResourceInfo) Info() *ResourceInfo {
@@ -84,6 +104,8 @@ func (r *ResourceInfo) AddChild(c Resource) {
 
 // Config defines the stack config
 type Config struct {
+	// Version of the state schema, set when the config is saved as state
+	Version   int        `json:"version"`
 	Blueprint *Blueprint `json:"blueprint"`
 	Resources []Resource `json:"resources"`
 }
@@ -151,6 +173,41 @@ func (c *Config) FindResource(name string) (Resource, error) {
 		}
 	}
 
+	// the keys for instances created with for_each can
+	// be specified without quotes i.e. k8s_config.app[dev]
+	if b, k, ok := splitInstanceName(n); ok && k.Type() == cty.String && instanceName(b, k) != n {
+		return c.FindResource(fmt.Sprintf("%s.%s", typ, instanceName(b, k)))
+	}
+
+	return nil, ResourceNotFoundError{name}
+}
+
+// FindResourceInstances returns the resource for the given name, when the
+// resource is defined using count or for_each all the instances are returned
+//
+// e.g. to find all instances of a container with count
+// r, err := c.FindResourceInstances("container.consul")
+func (c *Config) FindResourceInstances(name string) ([]Resource, error) {
+	r, err := c.FindResource(name)
+	if err == nil {
+		return []Resource{r}, nil
+	}
+
+	parts := strings.Split(name, ".")
+	typ := parts[0]
+	n := strings.Join(parts[1:], ".")
+
+	resources := []Resource{}
+	for _, r := range c.Resources {
+		if r.Info().Type == ResourceType(typ) && isInstanceOf(r.Info().Name, n) {
+			resources = append(resources, r)
+		}
+	}
+
+	if len(resources) > 0 {
+		return resources, nil
+	}
+
 	return nil, ResourceNotFoundError{name}
 }
 
@@ -229,12 +286,12 @@ func (c *Config) DoYaLikeDAGs() (*dag.AcyclicGraph, error) {
 					return nil, err
 				}
 			} else {
-				// find dependencies for direct resources
-				r, err := c.FindResource(d)
+				// find dependencies for direct resources or all
+				// instances of resources created with count or for_each
+				dependencies, err = c.FindResourceInstances(d)
 				if err != nil {
 					return nil, err
 				}
-				dependencies = append(dependencies, r)
 			}
 
 			for _, d := range dependencies {
@@ -31,6 +31,14 @@ type Container struct {
 	HealthCheck *HealthCheck `hcl:"health_check,block" json:"health_check,omitempty" mapstructure:"health_check"`
 
 	MaxRestartCount int `hcl:"max_restart_count,optional" json:"max_restart_count,omitempty" mapstructure:"max_restart_count"`
+
+	// output parameters, these are set when the container is created and
+	// can be referenced by other resources i.e. resource.container.db.ip_address
+
+	// IPAddress is the address of the container on the first network it is attached to
+	IPAddress string `json:"ip_address" mapstructure:"ip_address" state:"true"`
+	// AssignedPorts maps the ports in the container to the ports assigned on the host
+	AssignedPorts map[string]string `json:"assigned_ports" mapstructure:"assigned_ports" state:"true"`
 }
 
 // NewContainer returns a new Container resource with the correct default options
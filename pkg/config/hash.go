@@ -0,0 +1,88 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fields from ResourceInfo which are set by Shipyard or which control how the
+// engine processes the resource rather than the resource itself, these are
+// ignored when generating the hash
+var hashIgnoredFields = []string{"status", "hash", "depends_on", "disabled", "retry", "timeouts", "lifecycle"}
+
+// HashResource generates a canonical hash of the configuration for the
+// given resource. Only the values defined in the HCL config are used,
+// fields which store state such as the status or fields tagged with
+// `state:"true"` are ignored.
+//
+// The hash is used to detect changes between the config stored in the state
+// and the config which is being applied.
+func HashResource(r Resource) (string, error) {
+	d, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(d, &fields)
+	if err != nil {
+		return "", fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	for _, f := range hashIgnoredFields {
+		delete(fields, f)
+	}
+
+	// remove any fields which are used to store state
+	t := reflect.TypeOf(r).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("state") == "true" {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			delete(fields, name)
+		}
+	}
+
+	// attributes which reference other resources are not set until the
+	// referenced resources have been applied, use the config instead
+	if d := r.Info().deferred; d != nil {
+		names := d.names()
+		removeFields(t, names, fields)
+
+		if a, ok := fields["attributes"].(map[string]interface{}); ok {
+			for n := range names {
+				delete(a, n)
+			}
+		}
+
+		fields["deferred"] = d.hash
+	}
+
+	// json.Marshal sorts map keys so the output is canonical
+	d, err = json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(d)), nil
+}
+
+// removeFields removes the fields with the given hcl names
+func removeFields(t reflect.Type, names map[string]bool, fields map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		// the resource info is embedded
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			removeFields(f.Type, names, fields)
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("hcl"), ",")[0]
+		if name != "" && names[name] {
+			delete(fields, strings.Split(f.Tag.Get("json"), ",")[0])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestHashResourceIsStable(t *testing.T) {
+	c := NewContainer("test")
+	c.EnvVar = map[string]string{"a": "1", "b": "2", "c": "3"}
+
+	h1, err := HashResource(c)
+	assert.NoError(t, err)
+
+	c2 := NewContainer("test")
+	c2.EnvVar = map[string]string{"c": "3", "b": "2", "a": "1"}
+
+	h2, err := HashResource(c2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, h1, h2)
+}
+
+func TestHashResourceChangesWithConfig(t *testing.T) {
+	c := NewContainer("test")
+	h1, _ := HashResource(c)
+
+	c.EnvVar = map[string]string{"a": "1"}
+	h2, _ := HashResource(c)
+
+	assert.NotEqual(t, h1, h2)
+}
+
+func TestHashResourceIgnoresStatusAndStateFields(t *testing.T) {
+	i := NewIngress("test")
+	h1, _ := HashResource(i)
+
+	i.Status = Applied
+	i.Id = "abc123"
+	i.DependsOn = []string{"container.test"}
+	h2, _ := HashResource(i)
+
+	assert.Equal(t, h1, h2)
+}
+
+func TestHashResourceChangesWithDeferredAttributes(t *testing.T) {
+	c := parseReferencesConfig(t, hashDeferredConfig)
+	r, _ := c.FindResource("container.web")
+	h1, err := HashResource(r)
+	assert.NoError(t, err)
+
+	c = parseReferencesConfig(t, hashDeferredConfig)
+	r, _ = c.FindResource("container.web")
+	h2, _ := HashResource(r)
+	assert.Equal(t, h1, h2)
+
+	// edit a key which does not reference another resource
+	c = parseReferencesConfig(t, strings.Replace(hashDeferredConfig, `"debug"`, `"info"`, 1))
+	r, _ = c.FindResource("container.web")
+	h3, _ := HashResource(r)
+	assert.NotEqual(t, h1, h3)
+}
+
+func TestHashResourceDoesNotChangeWhenReferencesResolved(t *testing.T) {
+	c := parseReferencesConfig(t, hashDeferredConfig)
+	r, _ := c.FindResource("container.web")
+	h1, _ := HashResource(r)
+
+	db, _ := c.FindResource("container.db")
+	db.(*Container).IPAddress = "10.5.0.2"
+
+	err := c.ResolveReferences(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.5.0.2", r.(*Container).EnvVar["DB_ADDR"])
+
+	h2, _ := HashResource(r)
+	assert.Equal(t, h1, h2)
+}
+
+const hashDeferredConfig = `
+container "db" {
+  image {
+    name = "postgres:12"
+  }
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env_var = {
+    LOG     = "debug"
+    DB_ADDR = resource.container.db.ip_address
+  }
+}
+`
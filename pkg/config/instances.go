@@ -0,0 +1,307 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	countAttribute   = "count"
+	forEachAttribute = "for_each"
+
+	// maxInstances is the maximum number of instances
+	// which can be created from a single block
+	maxInstances = 1000
+)
+
+// instanceNameRegex matches the names of instances created
+// with count or for_each i.e. consul[0] or consul["dc1"]
+var instanceNameRegex = regexp.MustCompile(`^(.+)\[(.+)\]$`)
+
+// blockInstance is an instance of a resource block, blocks which set count or
+// for_each are expanded into one instance for each element
+type blockInstance struct {
+	block *hclsyntax.Block
+	// vars are the count or each variables for the instance
+	vars map[string]cty.Value
+}
+
+// instanceName returns the name of an instance of a resource created with
+// count or for_each, count uses the index i.e. consul[0], for_each uses
+// the key i.e. consul["dc1"]
+func instanceName(name string, key cty.Value) string {
+	if key.Type() == cty.Number {
+		i, _ := key.AsBigFloat().Int64()
+		return fmt.Sprintf("%s[%d]", name, i)
+	}
+
+	return fmt.Sprintf("%s[%q]", name, key.AsString())
+}
+
+// splitInstanceName returns the name of the resource block and the key for
+// an instance created with count or for_each. The key is a number for count
+// and a string for for_each, ok is false when the name is not an instance.
+func splitInstanceName(name string) (string, cty.Value, bool) {
+	m := instanceNameRegex.FindStringSubmatch(name)
+	if m == nil {
+		return "", cty.NilVal, false
+	}
+
+	if i, err := strconv.Atoi(m[2]); err == nil {
+		return m[1], cty.NumberIntVal(int64(i)), true
+	}
+
+	if k, err := strconv.Unquote(m[2]); err == nil {
+		return m[1], cty.StringVal(k), true
+	}
+
+	// keys can be written without quotes on the command line
+	return m[1], cty.StringVal(m[2]), true
+}
+
+// expandBlock returns the instances for a resource block, blocks which set the
+// count or for_each meta-arguments return an instance for each element, all
+// other blocks return a single instance. The meta-arguments are removed from
+// the body of the instances.
+func expandBlock(ctx *hcl.EvalContext, b *hclsyntax.Block) ([]blockInstance, error) {
+	count, hasCount := b.Body.Attributes[countAttribute]
+	forEach, hasForEach := b.Body.Attributes[forEachAttribute]
+
+	if !hasCount && !hasForEach {
+		return []blockInstance{blockInstance{block: b}}, nil
+	}
+
+	if hasCount && hasForEach {
+		return nil, metaArgumentError(forEach, "Invalid combination of \"count\" and \"for_each\"", "The \"count\" and \"for_each\" meta-arguments are mutually-exclusive, only one should be used.")
+	}
+
+	switch ResourceType(b.Type) {
+	case TypeVariable, TypeOutput, TypeModule:
+		a := count
+		if hasForEach {
+			a = forEach
+		}
+
+		return nil, metaArgumentError(a, "Invalid meta-argument", fmt.Sprintf("The %s meta-argument can not be used in %s blocks.", a.Name, b.Type))
+	}
+
+	// copy the block and remove the meta-arguments
+	body := *b.Body
+	body.Attributes = hclsyntax.Attributes{}
+	for k, v := range b.Body.Attributes {
+		if k != countAttribute && k != forEachAttribute {
+			body.Attributes[k] = v
+		}
+	}
+
+	instance := func(key cty.Value, vars map[string]cty.Value) blockInstance {
+		ib := *b
+		ib.Body = &body
+		ib.Labels = []string{instanceName(b.Labels[0], key)}
+
+		return blockInstance{block: &ib, vars: vars}
+	}
+
+	instances := []blockInstance{}
+
+	if hasCount {
+		n, err := evaluateCount(ctx, count)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < n; i++ {
+			idx := cty.NumberIntVal(int64(i))
+			instances = append(instances, instance(idx, map[string]cty.Value{
+				countAttribute: cty.ObjectVal(map[string]cty.Value{"index": idx}),
+			}))
+		}
+
+		return instances, nil
+	}
+
+	elements, err := evaluateForEach(ctx, forEach)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for k := range elements {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		instances = append(instances, instance(cty.StringVal(k), map[string]cty.Value{
+			"each": cty.ObjectVal(map[string]cty.Value{
+				"key":   cty.StringVal(k),
+				"value": elements[k],
+			}),
+		}))
+	}
+
+	return instances, nil
+}
+
+// context returns the context for the instance, the count or each
+// variables are only set for instances of expanded blocks
+func (bi blockInstance) context(ctx *hcl.EvalContext) *hcl.EvalContext {
+	if bi.vars == nil {
+		return ctx
+	}
+
+	ic := ctx.NewChild()
+	ic.Variables = bi.vars
+
+	return ic
+}
+
+// evaluateCount returns the number of instances for the count meta-argument
+func evaluateCount(ctx *hcl.EvalContext, a *hclsyntax.Attribute) (int, error) {
+	v, diags := a.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return 0, errors.New(diags.Error())
+	}
+
+	if v.IsNull() || !v.IsKnown() || v.Type() != cty.Number {
+		return 0, metaArgumentError(a, "Invalid count argument", "The count argument must be a whole number.")
+	}
+
+	bf := v.AsBigFloat()
+	if !bf.IsInt() || bf.Sign() < 0 || bf.Cmp(big.NewFloat(float64(maxInstances))) > 0 {
+		return 0, metaArgumentError(a, "Invalid count argument", fmt.Sprintf("The count argument must be a whole number between 0 and %d.", maxInstances))
+	}
+
+	n, _ := bf.Int64()
+	return int(n), nil
+}
+
+// evaluateForEach returns the elements for the for_each meta-argument keyed by
+// the each.key value, maps and objects use the map keys, sets and lists of
+// strings use the strings as both the key and the value
+func evaluateForEach(ctx *hcl.EvalContext, a *hclsyntax.Attribute) (map[string]cty.Value, error) {
+	v, diags := a.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, errors.New(diags.Error())
+	}
+
+	invalid := metaArgumentError(a, "Invalid for_each argument", "The for_each argument must be a map, or a set or list of strings.")
+
+	if v.IsNull() || !v.IsKnown() {
+		return nil, invalid
+	}
+
+	ty := v.Type()
+	elements := map[string]cty.Value{}
+
+	switch {
+	case ty.IsMapType() || ty.IsObjectType():
+		for k, e := range v.AsValueMap() {
+			elements[k] = e
+		}
+
+	case ty.IsSetType() || ty.IsListType() || ty.IsTupleType():
+		for _, e := range v.AsValueSlice() {
+			if !e.IsKnown() || e.IsNull() || e.Type() != cty.String {
+				return nil, invalid
+			}
+
+			if _, ok := elements[e.AsString()]; ok {
+				return nil, metaArgumentError(a, "Invalid for_each argument", fmt.Sprintf("The for_each argument contains the duplicate key %q.", e.AsString()))
+			}
+
+			elements[e.AsString()] = e
+		}
+
+	default:
+		return nil, invalid
+	}
+
+	return elements, nil
+}
+
+func metaArgumentError(a *hclsyntax.Attribute, summary, detail string) error {
+	d := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  a.SrcRange.Ptr(),
+	}
+
+	return errors.New(d.Error())
+}
+
+// instanceValues groups the values of the instances of resources created with
+// count or for_each, instances created with count are a tuple ordered by index,
+// instances created with for_each are an object keyed by each.key. Missing
+// count instances are unknown so that the tuple is indexed by count.index.
+func instanceValues(vals map[string]cty.Value) map[string]cty.Value {
+	out := map[string]cty.Value{}
+	counts := map[string]map[int64]cty.Value{}
+	keys := map[string]map[string]cty.Value{}
+
+	for n, v := range vals {
+		name, key, ok := splitInstanceName(n)
+		if !ok {
+			out[n] = v
+			continue
+		}
+
+		if key.Type() == cty.Number {
+			i, _ := key.AsBigFloat().Int64()
+			if counts[name] == nil {
+				counts[name] = map[int64]cty.Value{}
+			}
+
+			counts[name][i] = v
+			continue
+		}
+
+		if keys[name] == nil {
+			keys[name] = map[string]cty.Value{}
+		}
+
+		keys[name][key.AsString()] = v
+	}
+
+	for n, c := range counts {
+		max := int64(-1)
+		for i := range c {
+			if i > max {
+				max = i
+			}
+		}
+
+		t := []cty.Value{}
+		for i := int64(0); i <= max; i++ {
+			v, ok := c[i]
+			if !ok {
+				v = cty.DynamicVal
+			}
+
+			t = append(t, v)
+		}
+
+		out[n] = cty.TupleVal(t)
+	}
+
+	for n, k := range keys {
+		out[n] = cty.ObjectVal(k)
+	}
+
+	return out
+}
+
+// isInstanceOf returns true when the name is an instance of the resource block with the given name
+func isInstanceOf(name, block string) bool {
+	n, _, ok := splitInstanceName(name)
+	return ok && n == block
+}
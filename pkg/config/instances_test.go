@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func parseInstancesConfig(t *testing.T, contents string) (*Config, error) {
+	dir, cleanup := createTestFiles(t, contents)
+	t.Cleanup(cleanup)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+
+	return c, err
+}
+
+func TestCountCreatesIndexedResources(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		r, err := c.FindResource(fmt.Sprintf("container.consul[%d]", i))
+		assert.NoError(t, err)
+
+		assert.Equal(t, fmt.Sprintf("consul[%d]", i), r.Info().Name)
+		assert.Equal(t, fmt.Sprintf("%d", i), r.(*Container).EnvVar["INDEX"])
+	}
+
+	_, err = c.FindResource("container.consul[3]")
+	assert.Error(t, err)
+}
+
+func TestForEachCreatesKeyedResources(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource(`k8s_config.app["dev"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", filepath.Base(r.(*K8sConfig).Paths[0]))
+
+	r, err = c.FindResource(`k8s_config.app["prod"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", filepath.Base(r.(*K8sConfig).Paths[0]))
+}
+
+func TestForEachWithSetUsesValuesAsKeys(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource(`network.zone["a"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/16", r.(*Network).Subnet)
+}
+
+func TestFindResourceWithUnquotedKeyReturnsInstance(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("k8s_config.app[dev]")
+	assert.NoError(t, err)
+	assert.Equal(t, `app["dev"]`, r.Info().Name)
+}
+
+func TestFindResourceInstancesReturnsAllInstances(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	r, err := c.FindResourceInstances("container.consul")
+	assert.NoError(t, err)
+	assert.Len(t, r, 3)
+
+	r, err = c.FindResourceInstances("container.consul[1]")
+	assert.NoError(t, err)
+	assert.Len(t, r, 1)
+
+	_, err = c.FindResourceInstances("container.nomad")
+	assert.Error(t, err)
+}
+
+func TestReferencesToInstancesAreAddedAsDependencies(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+
+	web := r.(*Container)
+	assert.Equal(t, []string{"container.consul[0]", "container.consul[1]"}, web.DependsOn)
+
+	worker, err := c.FindResource("container.worker")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"container.consul"}, worker.Info().DependsOn)
+
+	err = ParseReferences(c)
+	assert.NoError(t, err)
+
+	// the cluster depends on the image cache which is added by the engine
+	err = c.AddResource(NewImageCache("docker-cache"))
+	assert.NoError(t, err)
+
+	d, err := c.DoYaLikeDAGs()
+	assert.NoError(t, err)
+
+	// depending on a resource created with count depends on all instances
+	for i := 0; i < 3; i++ {
+		cr, _ := c.FindResource(fmt.Sprintf("container.consul[%d]", i))
+		assert.Contains(t, d.DownEdges(cr).List(), worker)
+	}
+}
+
+func TestResolveReferencesForInstances(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		r, _ := c.FindResource(fmt.Sprintf("container.consul[%d]", i))
+		r.(*Container).IPAddress = fmt.Sprintf("10.5.0.%d", i+2)
+	}
+
+	r, _ := c.FindResource("container.web")
+	err = c.ResolveReferences(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10.5.0.3", r.(*Container).EnvVar["CONSUL_ADDR"])
+	assert.Equal(t, "container.consul[0]", r.(*Container).EnvVar["CONSUL"])
+}
+
+func TestInstancesAreSavedInState(t *testing.T) {
+	c, err := parseInstancesConfig(t, instancesConfig)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	err = c.ToJSON(path)
+	assert.NoError(t, err)
+
+	sc := New()
+	err = sc.FromJSON(path)
+	assert.NoError(t, err)
+
+	_, err = sc.FindResource("container.consul[2]")
+	assert.NoError(t, err)
+
+	_, err = sc.FindResource(`k8s_config.app["prod"]`)
+	assert.NoError(t, err)
+}
+
+func TestCountAndForEachReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, `
+container "consul" {
+  count    = 2
+  for_each = ["a"]
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "mutually-exclusive")
+}
+
+func TestCountWithInvalidValueReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, `
+container "consul" {
+  count = -1
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ":3,3-13: Invalid count argument")
+}
+
+func TestForEachWithInvalidValueReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, `
+container "consul" {
+  for_each = 2
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid for_each argument")
+}
+
+func TestCountOnModuleReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, `
+module "consul" {
+  count  = 2
+  source = "./consul"
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "can not be used in module blocks")
+}
+
+func TestCountIndexOutsideCountReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, `
+container "consul" {
+  count = 1
+}
+
+container "web" {
+  image {
+    name = "web:${count.index}"
+  }
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "count")
+}
+
+var instancesConfig = `
+variable "namespaces" {
+  default = {
+    dev  = "./dev"
+    prod = "./prod"
+  }
+}
+
+network "zone" {
+  for_each = ["a"]
+  subnet   = "10.0.0.0/16"
+}
+
+container "consul" {
+  count = 3
+
+  image {
+    name = "consul:1.8.1"
+  }
+
+  env_var = {
+    INDEX = count.index
+  }
+}
+
+k8s_cluster "k3s" {
+  driver = "k3s"
+}
+
+k8s_config "app" {
+  for_each = var.namespaces
+
+  cluster = "k8s_cluster.k3s"
+  paths   = [each.value]
+
+  wait_until_ready = true
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env_var = {
+    CONSUL      = container.consul[0]
+    CONSUL_ADDR = resource.container.consul[1].ip_address
+  }
+}
+
+container "worker" {
+  image {
+    name = "worker:1.0"
+  }
+
+  depends_on = [container.consul]
+}
+`
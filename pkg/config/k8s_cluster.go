@@ -22,6 +22,12 @@ type K8sCluster struct {
 	PortRanges []PortRange `hcl:"port_range,block" json:"port_ranges,omitempty" mapstructure:"port_range"` // range of ports to expose
 
 	EnvVar map[string]string `hcl:"env_var,optional" json:"env_var,omitempty" mapstructure:"env_var"` // environment variables to set when starting the container
+
+	// output parameters, these are set when the cluster is created and
+	// can be referenced by other resources i.e. resource.k8s_cluster.k3s.api_address
+
+	// APIAddress is the address of the Kubernetes API server
+	APIAddress string `json:"api_address" mapstructure:"api_address" state:"true"`
 }
 
 // NewK8sCluster creates new Cluster config with the correct defaults
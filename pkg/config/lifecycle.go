@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lifecycle is an internal block which can be added to any resource, it defines
+// commands which are run after the resource has been created and before it is destroyed.
+// Hooks are run in the order they are defined, when Target is set the command is run
+// inside the container for the target resource, otherwise it is run on the local machine.
+// example config:
+//
+//	lifecycle {
+//	  post_create {
+//	    command = ["consul", "kv", "put", "seed", "true"]
+//	    target  = "container.consul"
+//	  }
+//
+//	  pre_destroy {
+//	    command = ["./export_logs.sh"]
+//	    timeout = "2m"
+//	  }
+//	}
+type Lifecycle struct {
+	PostCreate []Hook `hcl:"post_create,block" json:"post_create,omitempty" mapstructure:"post_create"`
+	PreDestroy []Hook `hcl:"pre_destroy,block" json:"pre_destroy,omitempty" mapstructure:"pre_destroy"`
+}
+
+// Hook is a command which is run at a point in the lifecycle of a resource
+type Hook struct {
+	// Command and arguments to run
+	Command []string `hcl:"command" json:"command"`
+	// Target is the resource to run the command in i.e. container.consul, k8s_cluster.k3s,
+	// when not set the command is run locally
+	Target string `hcl:"target,optional" json:"target,omitempty"`
+	// WorkingDirectory for the command
+	WorkingDirectory string `hcl:"working_directory,optional" json:"working_directory,omitempty" mapstructure:"working_directory"`
+	// Timeout for the command, the command is also limited by the resource timeouts
+	Timeout string `hcl:"timeout,optional" json:"timeout,omitempty"`
+}
+
+// Targets returns the resources which are targeted by the hooks
+func (l *Lifecycle) Targets() []string {
+	if l == nil {
+		return nil
+	}
+
+	targets := []string{}
+	for _, h := range append(l.PostCreate, l.PreDestroy...) {
+		if h.Target != "" {
+			targets = append(targets, h.Target)
+		}
+	}
+
+	return targets
+}
+
+// TimeoutDuration returns the timeout for the hook, 0 is returned
+// when no timeout has been set
+func (h Hook) TimeoutDuration() (time.Duration, error) {
+	if h.Timeout == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid hook timeout %s: %s", h.Timeout, err)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleParsesHooks(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, lifecycleConfig)
+	defer cleanup()
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+
+	l := r.Info().Lifecycle
+	assert.Len(t, l.PostCreate, 2)
+	assert.Equal(t, []string{"./seed.sh"}, l.PostCreate[0].Command)
+	assert.Equal(t, "container.consul", l.PostCreate[1].Target)
+	assert.Equal(t, "/tmp", l.PostCreate[1].WorkingDirectory)
+
+	assert.Len(t, l.PreDestroy, 1)
+	assert.Equal(t, "2m", l.PreDestroy[0].Timeout)
+}
+
+func TestLifecycleTargetsAddDependencies(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, lifecycleConfig)
+	defer cleanup()
+
+	r, err := c.FindResource("k8s_cluster.k3s")
+	assert.NoError(t, err)
+	assert.Contains(t, r.Info().DependsOn, "container.consul")
+
+	// hooks which target the resource itself do not add a dependency
+	r, err = c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.NotContains(t, r.Info().DependsOn, "container.consul")
+}
+
+func TestLifecycleDoesNotChangeHash(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, lifecycleConfig)
+	defer cleanup()
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+
+	h1, err := HashResource(r)
+	assert.NoError(t, err)
+
+	r.Info().Lifecycle = nil
+
+	h2, err := HashResource(r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, h1, h2)
+}
+
+func TestHookReturnsTimeout(t *testing.T) {
+	d, err := Hook{Timeout: "2m"}.TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, err = Hook{}.TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	_, err = Hook{Timeout: "abc"}.TimeoutDuration()
+	assert.Error(t, err)
+}
+
+var lifecycleConfig = `
+container "consul" {
+	image {
+		name = "consul:1.8.1"
+	}
+
+	lifecycle {
+		post_create {
+			command = ["./seed.sh"]
+		}
+
+		post_create {
+			command           = ["consul", "kv", "put", "seed", "true"]
+			target            = "container.consul"
+			working_directory = "/tmp"
+		}
+
+		pre_destroy {
+			command = ["./export_logs.sh"]
+			timeout = "2m"
+		}
+	}
+}
+
+k8s_cluster "k3s" {
+	driver = "k3s"
+
+	lifecycle {
+		pre_destroy {
+			command = ["consul", "snapshot", "save", "/tmp/backup.snap"]
+			target  = "container.consul"
+		}
+	}
+}
+`
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/utils"
+)
+
+// lockRetryInterval is the time to wait between attempts to acquire a held lock
+var lockRetryInterval = 250 * time.Millisecond
+
+// Version of Shipyard which is recorded in the state lock,
+// this is set by the cmd package when the CLI starts
+var Version = "dev"
+
+// StateLock is an advisory lock which is held while the state is read, modified,
+// and written. The lock is a file next to the state file which records the
+// process holding the lock.
+// The fields are the same as the Terraform LockInfo so that servers which
+// implement the Terraform http backend can read the lock.
+type StateLock struct {
+	// ID uniquely identifies the lock, remote backends use the ID to
+	// ensure that only the holder of the lock can change the state
+	ID string
+	// Operation which is holding the lock i.e. shipyard run ./stack
+	Operation string
+	// Info contains extra details about the holder such as the process id
+	Info string
+	// Who is holding the lock i.e. nic@laptop
+	Who string
+	// Version of Shipyard holding the lock
+	Version string
+	// Created is the time the lock was acquired
+	Created time.Time
+	// Path of the state which is locked
+	Path string
+
+	backend StateBackend
+}
+
+func newStateLock(b StateBackend, path string) *StateLock {
+	now := time.Now()
+
+	who := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		who = u.Username
+	}
+
+	return &StateLock{
+		ID:        fmt.Sprintf("%d-%d", os.Getpid(), now.UnixNano()),
+		Operation: strings.Join(os.Args, " "),
+		Info:      fmt.Sprintf("pid %d", os.Getpid()),
+		Who:       fmt.Sprintf("%s@%s", who, utils.GetHostname()),
+		Version:   Version,
+		Created:   now,
+		Path:      path,
+		backend:   b,
+	}
+}
+
+// StateLockedError is returned when the state is locked by another process
+type StateLockedError struct {
+	Holder *StateLock
+}
+
+func (e StateLockedError) Error() string {
+	if e.Holder == nil || e.Holder.ID == "" {
+		return "State is locked by another process, if the process is no longer running remove the lock with \"shipyard state unlock\""
+	}
+
+	return fmt.Sprintf(
+		"State is locked by %s (%s) since %s, if the process is no longer running remove the lock with \"shipyard state unlock\"",
+		e.Holder.Who,
+		e.Holder.Operation,
+		e.Holder.Created.Format(time.RFC3339),
+	)
+}
+
+// LockPath returns the path of the lock file for the given state file
+func LockPath(statePath string) string {
+	return statePath + ".lock"
+}
+
+// LockState acquires the lock for the state file at path, when the state is locked by
+// another process the lock is retried until the timeout elapses, a StateLockedError
+// is returned if the lock can not be acquired
+func LockState(path string, timeout time.Duration) (*StateLock, error) {
+	return NewLocalStateBackend(path).Lock(timeout)
+}
+
+// lockWithRetry calls lock until the lock is acquired or the timeout elapses,
+// only StateLockedError is retried, other errors are returned immediately
+func lockWithRetry(timeout time.Duration, lock func() (*StateLock, error)) (*StateLock, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		l, err := lock()
+		if err == nil {
+			return l, nil
+		}
+
+		if _, ok := err.(StateLockedError); !ok || time.Now().After(deadline) {
+			return nil, err
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func tryLockState(path string) (*StateLock, error) {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create state folder: %s", err)
+	}
+
+	// creating the file fails when it already exists so only one process can hold the lock
+	f, err := os.OpenFile(LockPath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("Unable to create state lock: %s", err)
+		}
+
+		h, err := ReadStateLock(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, StateLockedError{h}
+	}
+	defer f.Close()
+
+	l := newStateLock(NewLocalStateBackend(path), path)
+
+	err = json.NewEncoder(f).Encode(l)
+	if err != nil {
+		os.Remove(LockPath(path))
+		return nil, fmt.Errorf("Unable to write state lock: %s", err)
+	}
+
+	return l, nil
+}
+
+// Unlock releases the lock, the lock is only removed
+// when it is still held by this lock
+func (l *StateLock) Unlock() error {
+	if l.backend == nil {
+		return nil
+	}
+
+	return l.backend.Unlock(l)
+}
+
+// unlockState removes the lock file for the state at path when
+// it is still held by the given lock
+func unlockState(path string, l *StateLock) error {
+	h, err := ReadStateLock(path)
+	if err != nil {
+		return err
+	}
+
+	// the lock has been removed with state unlock
+	if h == nil || h.ID != l.ID {
+		return nil
+	}
+
+	err = os.Remove(LockPath(path))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Unable to remove state lock: %s", err)
+	}
+
+	return nil
+}
+
+// ReadStateLock returns the holder of the lock for the state file at
+// path, nil is returned when the state is not locked
+func ReadStateLock(path string) (*StateLock, error) {
+	d, err := ioutil.ReadFile(LockPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Unable to read state lock: %s", err)
+	}
+
+	l := &StateLock{backend: NewLocalStateBackend(path)}
+
+	// the lock file is created before the holder is written
+	if len(strings.TrimSpace(string(d))) == 0 {
+		return l, nil
+	}
+
+	err = json.Unmarshal(d, l)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read state lock: %s", err)
+	}
+
+	return l, nil
+}
+
+// UnlockState removes the lock for the state file at path regardless of the holder,
+// this is used to remove locks held by processes which are no longer running
+func UnlockState(path string) (*StateLock, error) {
+	h, err := ReadStateLock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if h == nil {
+		return nil, fmt.Errorf("State is not locked")
+	}
+
+	err = os.Remove(LockPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to remove state lock: %s", err)
+	}
+
+	return h, nil
+}
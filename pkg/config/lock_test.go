@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupLockTest(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "state", "state.json")
+}
+
+func TestLockStateCreatesLockWithHolder(t *testing.T) {
+	sp := setupLockTest(t)
+
+	l, err := LockState(sp, 0)
+	assert.NoError(t, err)
+
+	h, err := ReadStateLock(sp)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("pid %d", os.Getpid()), h.Info)
+	assert.Equal(t, sp, h.Path)
+	assert.NotEmpty(t, h.ID)
+	assert.NotEmpty(t, h.Operation)
+	assert.NotEmpty(t, h.Who)
+
+	err = l.Unlock()
+	assert.NoError(t, err)
+
+	h, err = ReadStateLock(sp)
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
+
+func TestLockStateWhenLockedReturnsError(t *testing.T) {
+	sp := setupLockTest(t)
+
+	l, err := LockState(sp, 0)
+	assert.NoError(t, err)
+	defer l.Unlock()
+
+	_, err = LockState(sp, 0)
+	assert.Error(t, err)
+	assert.IsType(t, StateLockedError{}, err)
+	assert.Contains(t, err.Error(), "shipyard state unlock")
+}
+
+func TestLockStateWaitsForTimeout(t *testing.T) {
+	sp := setupLockTest(t)
+
+	l, err := LockState(sp, 0)
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l.Unlock()
+	}()
+
+	l2, err := LockState(sp, 5*time.Second)
+	assert.NoError(t, err)
+	l2.Unlock()
+}
+
+func TestUnlockDoesNotRemoveLockHeldByAnotherProcess(t *testing.T) {
+	sp := setupLockTest(t)
+
+	l, err := LockState(sp, 0)
+	assert.NoError(t, err)
+
+	// the lock is removed and acquired by another process
+	_, err = UnlockState(sp)
+	assert.NoError(t, err)
+
+	l2, err := LockState(sp, 0)
+	assert.NoError(t, err)
+	defer l2.Unlock()
+
+	err = l.Unlock()
+	assert.NoError(t, err)
+
+	h, err := ReadStateLock(sp)
+	assert.NoError(t, err)
+	assert.NotNil(t, h)
+}
+
+func TestUnlockStateWhenNotLockedReturnsError(t *testing.T) {
+	sp := setupLockTest(t)
+
+	_, err := UnlockState(sp)
+	assert.Error(t, err)
+}
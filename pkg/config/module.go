@@ -1,5 +1,7 @@
 package config
 
+import "github.com/zclconf/go-cty/cty"
+
 // TypeModule is the resource string for a Module resource
 const TypeModule ResourceType = "module"
 
@@ -11,6 +13,10 @@ type Module struct {
 	Depends []string `hcl:"depends_on,optional" json:"depends,omitempty"`
 
 	Source string `hcl:"source" json:"source"`
+
+	// Variables are the values for the variables defined in the module,
+	// these are only set for the resources in this instance of the module
+	Variables cty.Value `hcl:"variables,optional" json:"-"`
 }
 
 // NewModule creates a new Module config resource
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +30,243 @@ module "testing" {
 	source = "../../examples/single_file"
 }
 `
+
+func setupModuleVariablesConfig(t *testing.T, contents string) (*Config, *moduleContext, string, error) {
+	dir, cleanup := createTestFiles(t)
+	t.Cleanup(cleanup)
+
+	for name, m := range map[string]string{"greeting": greetingModule, "database": databaseModule} {
+		md := filepath.Join(dir, name)
+		os.MkdirAll(md, os.ModePerm)
+		createNamedFile(t, md, "*.hcl", m)
+	}
+
+	f := createNamedFile(t, dir, "*.hcl", contents)
+
+	c := New()
+	mc := newModuleContext()
+	err := parseFolder(mc, dir, c, false, "", false, []string{}, nil, "")
+
+	return c, mc, f, err
+}
+
+func TestModuleVariablesAreScopedToTheModule(t *testing.T) {
+	c, mc, _, err := setupModuleVariablesConfig(t, moduleVariables)
+	assert.NoError(t, err)
+
+	o, err := c.FindResource("output.english.greeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", o.(*Output).Value)
+	assert.Equal(t, "english", o.Info().Module)
+
+	// values set for other instances of the module are not used,
+	// variables set by the parent are used by the module
+	o, err = c.FindResource("output.default.greeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "hi world", o.(*Output).Value)
+
+	// variables and defaults set for a module are not set for the parent
+	_, ok := mc.ctx.Variables["var"].AsValueMap()["prefix"]
+	assert.False(t, ok)
+
+	_, ok = mc.ctx.Variables["var"].AsValueMap()["repeat"]
+	assert.False(t, ok)
+}
+
+func TestModuleFileFunctionsAreRelativeToTheModule(t *testing.T) {
+	c, _, f, err := setupModuleVariablesConfig(t, moduleVariables)
+	assert.NoError(t, err)
+
+	o, err := c.FindResource("output.english.dir")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(f), "greeting"), o.(*Output).Value)
+
+	// resources in the parent are not changed by the module
+	o, err = c.FindResource("output.dir")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Dir(f), o.(*Output).Value)
+}
+
+func TestModuleVariablesAreConvertedToType(t *testing.T) {
+	c, _, _, err := setupModuleVariablesConfig(t, moduleVariables)
+	assert.NoError(t, err)
+
+	o, err := c.FindResource("output.english.repeat")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", o.(*Output).Value)
+}
+
+func TestModuleVariablesFailingValidationReportsModuleLocation(t *testing.T) {
+	_, _, f, err := setupModuleVariablesConfig(t, `
+module "english" {
+  source = "./greeting"
+
+  variables = {
+    repeat = 9
+  }
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:6,", f))
+	assert.Contains(t, err.Error(), "Repeat must be less than 5")
+	assert.Contains(t, err.Error(), "the module english")
+}
+
+func TestModuleVariablesWithInvalidValueReturnsError(t *testing.T) {
+	_, _, _, err := setupModuleVariablesConfig(t, `
+module "english" {
+  source    = "./greeting"
+  variables = "hello"
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid module variables")
+}
+
+func TestModuleOutputsCanBeReferencedByParent(t *testing.T) {
+	c, _, _, err := setupModuleVariablesConfig(t, moduleVariables)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+
+	web := r.(*Container)
+	assert.Equal(t, "hello world", web.Environment[0].Value)
+	assert.Contains(t, web.DependsOn, "output.english.greeting")
+
+	o, err := c.FindResource("output.message")
+	assert.NoError(t, err)
+	assert.Equal(t, "hi world!", o.(*Output).Value)
+}
+
+func TestModuleOutputsReferencingResourcesAreResolvedWhenApplied(t *testing.T) {
+	c, _, _, err := setupModuleVariablesConfig(t, moduleVariables)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+
+	web := r.(*Container)
+	assert.Empty(t, web.EnvVar)
+	assert.Contains(t, web.DependsOn, "output.db.address")
+
+	err = ParseReferences(c)
+	assert.NoError(t, err)
+
+	db, err := c.FindResource("container.db")
+	assert.NoError(t, err)
+	assert.Equal(t, "db", db.Info().Module)
+	db.(*Container).IPAddress = "10.5.0.2"
+
+	o, _ := c.FindResource("output.db.address")
+	err = c.ResolveReferences(o)
+	assert.NoError(t, err)
+
+	err = c.ResolveReferences(web)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.5.0.2", web.EnvVar["DB_ADDR"])
+}
+
+func TestModuleOutputToMissingOutputReturnsError(t *testing.T) {
+	_, _, _, err := setupModuleVariablesConfig(t, `
+module "english" {
+  source = "./greeting"
+}
+
+output "message" {
+  value = module.english.output.missing
+}
+`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+}
+
+const moduleVariables = `
+variable "subject" {
+  default = "world"
+}
+
+module "english" {
+  source = "./greeting"
+
+  variables = {
+    prefix = "hello"
+    repeat = "3"
+  }
+}
+
+module "default" {
+  source = "./greeting"
+}
+
+module "db" {
+  source = "./database"
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env {
+    key   = "GREETING"
+    value = module.english.output.greeting
+  }
+
+  env_var = {
+    DB_ADDR = module.db.output.address
+  }
+}
+
+output "message" {
+  value = "${module.default.output.greeting}!"
+}
+
+output "dir" {
+  value = file_dir()
+}
+`
+
+const greetingModule = `
+variable "prefix" {
+  default = "hi"
+}
+
+variable "repeat" {
+  type    = number
+  default = 1
+
+  validation {
+    condition     = var.repeat < 5
+    error_message = "Repeat must be less than 5."
+  }
+}
+
+variable "subject" {
+  default = "everyone"
+}
+
+output "greeting" {
+  value = "${var.prefix} ${var.subject}"
+}
+
+output "repeat" {
+  value = var.repeat
+}
+
+output "dir" {
+  value = file_dir()
+}
+`
+
+const databaseModule = `
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+output "address" {
+  value = resource.container.db.ip_address
+}
+`
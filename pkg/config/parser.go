@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -10,20 +11,42 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/gernest/front"
 	"github.com/hashicorp/go-getter"
+	"github.com/hashicorp/hcl2/ext/typeexpr"
 	"github.com/hashicorp/hcl2/gohcl"
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcldec"
 	"github.com/hashicorp/hcl2/hclparse"
 	"github.com/shipyard-run/shipyard/pkg/utils"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 	"golang.org/x/xerrors"
 )
 
-var ctx *hcl.EvalContext
+// moduleContext is the state used to evaluate the expressions in a blueprint or
+// module, every module is parsed with its own context so that the variables
+// set for a module do not leak to the parent or to other modules
+type moduleContext struct {
+	// ctx contains the var and module variables, the functions
+	// are inherited from the context of the parent
+	ctx *hcl.EvalContext
+	// sources records where the values for variables were set so
+	// that invalid values can be reported with their location
+	sources map[string]variableSource
+}
+
+type variableSource struct {
+	// Range is the location of the value when set in a file
+	Range *hcl.Range
+	// Origin describes how the value was set
+	Origin string
+}
 
 type ResourceTypeNotExistError struct {
 	Type string
@@ -35,8 +58,7 @@ func (r ResourceTypeNotExistError) Error() string {
 }
 
 func ParseSingleFile(file string, c *Config, variables map[string]string, variablesFile string) error {
-	ctx = buildContext()
-	return parseFile(file, c, variables, variablesFile)
+	return parseFile(newModuleContext(), file, c, variables, variablesFile)
 }
 
 // ParseFolder for Resource, Blueprint, and Variable files
@@ -46,6 +68,8 @@ func ParseSingleFile(file string, c *Config, variables map[string]string, variab
 //  has the disabled flag set
 // only reads resource files and will ignore Blueprint and Variable files.
 // This is useful when recursively parsing such as when reading Modules
+// Every call is parsed with its own evaluation context, it is safe to
+// parse multiple folders concurrently
 func ParseFolder(
 	folder string,
 	c *Config,
@@ -56,8 +80,8 @@ func ParseFolder(
 	variables map[string]string,
 	variablesFile string) error {
 
-	ctx = buildContext()
 	return parseFolder(
+		newModuleContext(),
 		folder,
 		c,
 		onlyResources,
@@ -69,21 +93,26 @@ func ParseFolder(
 	)
 }
 
-func parseFile(file string, c *Config, variables map[string]string, variablesFile string) error {
-	SetVariables(variables)
+func parseFile(mc *moduleContext, file string, c *Config, variables map[string]string, variablesFile string) error {
+	mc.setVariables(variables)
 	if variablesFile != "" {
-		err := LoadValuesFile(variablesFile)
+		err := mc.loadValuesFile(variablesFile)
 		if err != nil {
 			return err
 		}
 	}
 
-	err := parseVariableFile(file, c)
+	err := parseVariableFile(mc, file, c)
 	if err != nil {
 		return err
 	}
 
-	err = parseHCLFile(file, c, "", false, []string{})
+	err = parseModuleFile(mc, file, c, false)
+	if err != nil {
+		return err
+	}
+
+	err = parseHCLFile(mc, file, c, "", false, []string{})
 	if err != nil {
 		return err
 	}
@@ -92,6 +121,7 @@ func parseFile(file string, c *Config, variables map[string]string, variablesFil
 }
 
 func parseFolder(
+	mc *moduleContext,
 	folder string,
 	c *Config,
 	onlyResources bool,
@@ -111,7 +141,7 @@ func parseFolder(
 		}
 
 		for _, f := range variableFiles {
-			err := LoadValuesFile(f)
+			err := mc.loadValuesFile(f)
 			if err != nil {
 				return err
 			}
@@ -119,14 +149,14 @@ func parseFolder(
 
 		// load variables from any custom files set on the command line
 		if variablesFile != "" {
-			err := LoadValuesFile(variablesFile)
+			err := mc.loadValuesFile(variablesFile)
 			if err != nil {
 				return err
 			}
 		}
 
 		// setup any variables which are passed as environment variables or in the collection
-		SetVariables(variables)
+		mc.setVariables(variables)
 
 		// pick up the blueprint file
 		yardFilesHCL, err := filepath.Glob(path.Join(abs, "*.yard"))
@@ -144,7 +174,7 @@ func parseFolder(
 		yardFiles = append(yardFiles, yardFilesMD...)
 
 		if len(yardFiles) > 0 {
-			err := parseYardFile(yardFiles[0], c)
+			err := parseYardFile(mc, yardFiles[0], c)
 			if err != nil {
 				return err
 			}
@@ -153,19 +183,26 @@ func parseFolder(
 
 	// We need to do a two pass parsing, first we check if there are any
 	// default variables which should be added to the collection
-	err := parseVariables(abs, c)
+	err := parseVariables(mc, abs, c)
+	if err != nil {
+		return err
+	}
+
+	// Modules are parsed before the resources so that the module
+	// outputs can be referenced by the resources in this folder
+	err = parseModules(mc, abs, c, disabled)
 	if err != nil {
 		return err
 	}
 
 	// Parse Resource files from the current folder
-	err = parseResources(abs, c, moduleName, disabled, dependsOn)
+	err = parseResources(mc, abs, c, moduleName, disabled, dependsOn)
 	if err != nil {
 		return err
 	}
 
 	// Finally parse the outputs
-	err = parseOutputs(abs, disabled, c)
+	err = parseOutputs(mc, abs, moduleName, disabled, c)
 	if err != nil {
 		return err
 	}
@@ -174,16 +211,16 @@ func parseFolder(
 }
 
 // ParseYardFile parses a blueprint configuration file
-func parseYardFile(file string, c *Config) error {
+func parseYardFile(mc *moduleContext, file string, c *Config) error {
 	if filepath.Ext(file) == ".yard" {
-		return parseYardHCL(file, c)
+		return parseYardHCL(mc, file, c)
 	}
 
 	return parseYardMarkdown(file, c)
 }
 
-// LoadValuesFile loads variable values from a file
-func LoadValuesFile(path string) error {
+// loadValuesFile loads variable values from a file
+func (m *moduleContext) loadValuesFile(path string) error {
 	parser := hclparse.NewParser()
 
 	f, diag := parser.ParseHCLFile(path)
@@ -191,43 +228,44 @@ func LoadValuesFile(path string) error {
 		return errors.New(diag.Error())
 	}
 
-	// add the file functions to the context with a reference to the
-	// current file
-	ctx.Functions["file_path"] = getFilePathFunc(path)
-	ctx.Functions["file_dir"] = getFileDirFunc(path)
+	ctx := m.fileContext(path)
 
 	attrs, _ := f.Body.JustAttributes()
 	for name, attr := range attrs {
 		val, _ := attr.Expr.Value(ctx)
 
-		setContextVariable(name, val)
+		m.setVariable(name, val)
+		m.sources[name] = variableSource{attr.Range.Ptr(), fmt.Sprintf("the values file %s", path)}
 	}
 
 	return nil
 }
 
-// SetVariables allow variables to be set from a collection or environment variables
+// setVariables allow variables to be set from a collection or environment variables
 // Precedence should be file, env, vars
-func SetVariables(vars map[string]string) {
+func (m *moduleContext) setVariables(vars map[string]string) {
 	// first any vars defined as environment variables
 	for _, e := range os.Environ() {
 		if strings.HasPrefix(e, "SY_VAR_") {
 			parts := strings.Split(e, "=")
-			setContextVariable(strings.Replace(parts[0], "SY_VAR_", "", -1), parts[1])
+			name := strings.Replace(parts[0], "SY_VAR_", "", -1)
+
+			m.setVariable(name, parts[1])
+			m.sources[name] = variableSource{nil, fmt.Sprintf("the environment variable %s", parts[0])}
 		}
 	}
 
 	// then set vars
 	for k, v := range vars {
-		setContextVariable(k, v)
+		m.setVariable(k, v)
+		m.sources[k] = variableSource{nil, "--var"}
 	}
 }
 
 // ParseVariableFile parses a config file for variables
-func parseVariableFile(file string, c *Config) error {
+func parseVariableFile(mc *moduleContext, file string, c *Config) error {
 	parser := hclparse.NewParser()
-	ctx.Functions["file_path"] = getFilePathFunc(file)
-	ctx.Functions["file_dir"] = getFileDirFunc(file)
+	ctx := mc.fileContext(file)
 
 	f, diag := parser.ParseHCLFile(file)
 	if diag.HasErrors() {
@@ -244,13 +282,18 @@ func parseVariableFile(file string, c *Config) error {
 		case string(TypeVariable):
 			v := NewVariable(b.Labels[0])
 
-			err := decodeBody(file, b, v)
+			err := decodeBody(ctx, f.Bytes, b, v)
 			if err != nil {
 				return err
 			}
 
 			val, _ := v.Default.(*hcl.Attribute).Expr.Value(ctx)
-			setContextVariableIfMissing(v.Name, val)
+			mc.setVariableIfMissing(v.Name, val)
+
+			err = mc.checkVariable(ctx, v)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -258,10 +301,9 @@ func parseVariableFile(file string, c *Config) error {
 }
 
 // parseHCLFile parses a config file and adds it to the config
-func parseHCLFile(file string, c *Config, moduleName string, disabled bool, dependsOn []string) error {
+func parseHCLFile(mc *moduleContext, file string, c *Config, moduleName string, disabled bool, dependsOn []string) error {
 	parser := hclparse.NewParser()
-	ctx.Functions["file_path"] = getFilePathFunc(file)
-	ctx.Functions["file_dir"] = getFileDirFunc(file)
+	ctx := mc.fileContext(file)
 
 	f, diag := parser.ParseHCLFile(file)
 	if diag.HasErrors() {
@@ -273,8 +315,21 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 		return errors.New("Error getting body")
 	}
 
+	// blocks which set count or for_each are expanded
+	// into an instance for each element
+	instances := []blockInstance{}
 	for _, b := range body.Blocks {
-		//fmt.Printf("Parsing: %s, type: %s\n", file, b.Type)
+		bi, err := expandBlock(ctx, b)
+		if err != nil {
+			return err
+		}
+
+		instances = append(instances, bi...)
+	}
+
+	for _, bi := range instances {
+		b := bi.block
+		bctx := bi.context(ctx)
 
 		switch b.Type {
 		case string(TypeVariable):
@@ -287,12 +342,17 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			// stop the resource not found error
 			continue
 
+		case "state":
+			// the state backend is read with ParseStateBackendConfig
+			// before the config is parsed
+			continue
+
 		case string(TypeK8sCluster):
 			cl := NewK8sCluster(b.Labels[0])
 			cl.Info().Module = moduleName
 			cl.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, cl)
+			err := decodeBody(bctx, f.Bytes, b, cl)
 			if err != nil {
 				return err
 			}
@@ -321,7 +381,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			h.Info().Module = moduleName
 			h.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, h)
+			err := decodeBody(bctx, f.Bytes, b, h)
 			if err != nil {
 				return err
 			}
@@ -349,7 +409,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			h.Info().Module = moduleName
 			h.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, h)
+			err := decodeBody(bctx, f.Bytes, b, h)
 			if err != nil {
 				return err
 			}
@@ -387,7 +447,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			i.Info().Module = moduleName
 			i.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, i)
+			err := decodeBody(bctx, f.Bytes, b, i)
 			if err != nil {
 				return err
 			}
@@ -410,7 +470,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			cl.Info().Module = moduleName
 			cl.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, cl)
+			err := decodeBody(bctx, f.Bytes, b, cl)
 			if err != nil {
 				return err
 			}
@@ -439,7 +499,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			h.Info().Module = moduleName
 			h.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, h)
+			err := decodeBody(bctx, f.Bytes, b, h)
 			if err != nil {
 				return err
 			}
@@ -467,7 +527,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			i.Info().Module = moduleName
 			i.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, i)
+			err := decodeBody(bctx, f.Bytes, b, i)
 			if err != nil {
 				return err
 			}
@@ -490,7 +550,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			n.Info().Module = moduleName
 			n.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, n)
+			err := decodeBody(bctx, f.Bytes, b, n)
 			if err != nil {
 				return err
 			}
@@ -520,7 +580,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			i.Info().Module = moduleName
 			i.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, i)
+			err := decodeBody(bctx, f.Bytes, b, i)
 			if err != nil {
 				return err
 			}
@@ -543,7 +603,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			co.Info().Module = moduleName
 			co.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, co)
+			err := decodeBody(bctx, f.Bytes, b, co)
 			if err != nil {
 				return err
 			}
@@ -579,7 +639,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			i.Info().Module = moduleName
 			i.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, i)
+			err := decodeBody(bctx, f.Bytes, b, i)
 			if err != nil {
 				return err
 			}
@@ -602,7 +662,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			s.Info().Module = moduleName
 			s.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, s)
+			err := decodeBody(bctx, f.Bytes, b, s)
 			if err != nil {
 				return err
 			}
@@ -629,7 +689,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			do.Info().Module = moduleName
 			do.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, do)
+			err := decodeBody(bctx, f.Bytes, b, do)
 			if err != nil {
 				return err
 			}
@@ -654,7 +714,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			h.Info().Module = moduleName
 			h.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, h)
+			err := decodeBody(bctx, f.Bytes, b, h)
 			if err != nil {
 				return err
 			}
@@ -677,7 +737,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			h.Info().Module = moduleName
 			h.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, h)
+			err := decodeBody(bctx, f.Bytes, b, h)
 			if err != nil {
 				return err
 			}
@@ -706,7 +766,7 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			i.Info().Module = moduleName
 			i.Info().DependsOn = dependsOn
 
-			err := decodeBody(file, b, i)
+			err := decodeBody(bctx, f.Bytes, b, i)
 			if err != nil {
 				return err
 			}
@@ -727,50 +787,37 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 			}
 
 		case string(TypeModule):
-			moduleName := b.Labels[0]
-			m := NewModule(moduleName)
-			m.Info().Module = moduleName
-
-			err := decodeBody(file, b, m)
-			if err != nil {
-				return err
-			}
-
-			// import the source files for this module
-			if !utils.IsLocalFolder(ensureAbsolute(m.Source, file)) {
-				// get the details
-				dst := utils.GetBlueprintLocalFolder(m.Source)
-				err := getFiles(m.Source, dst)
-				if err != nil {
-					return err
-				}
+			// do nothing modules are parsed
+			// before the other resources
+			continue
 
-				// set the source to the local folder
-				m.Source = dst
+		default:
+			spec, ok := pluginTypeSpec(ResourceType(b.Type))
+			if !ok {
+				return ResourceTypeNotExistError{string(b.Type), file}
 			}
 
-			// set the absolute path
-			m.Source = ensureAbsolute(m.Source, file)
-
-			// if the module is disabled ensure
-			setDisabled(m, disabled)
+			pr := NewPluginResource(ResourceType(b.Type), b.Labels[0])
+			pr.Info().Module = moduleName
+			pr.Info().DependsOn = dependsOn
 
-			// recursively parse references for the module
-			// ensure we do load the values which might be in module folders
-			err = parseFolder(m.Source, c, true, moduleName, m.Disabled, m.Depends, nil, "")
+			err := decodePluginBody(bctx, f.Bytes, b, spec, pr)
 			if err != nil {
 				return err
 			}
 
-			// modules will reset the context file path as they recurse
-			// into other folders. They should have a separate context but
-			// for now just reset the file path to ensure any other resources
-			// parsed after the module have the correct path
-			ctx.Functions["file_path"] = getFilePathFunc(file)
-			ctx.Functions["file_dir"] = getFileDirFunc(file)
+			setDisabled(pr, disabled)
 
-		default:
-			return ResourceTypeNotExistError{string(b.Type), file}
+			err = c.AddResource(pr)
+			if err != nil {
+				return fmt.Errorf(
+					"Unable to add resource %s.%s in file %s: %s",
+					b.Type,
+					b.Labels[0],
+					file,
+					err,
+				)
+			}
 		}
 	}
 
@@ -779,6 +826,15 @@ func parseHCLFile(file string, c *Config, moduleName string, disabled bool, depe
 
 // ParseReferences links the object references in config elements
 func ParseReferences(c *Config) error {
+	err := c.checkReferences()
+	if err != nil {
+		return err
+	}
+
+	// references written as expressions i.e. network.cloud are added as dependencies
+	// when the resource is decoded, the dependencies below are still required as the
+	// same attributes can be set with a string i.e. "network.cloud" which is not an
+	// expression, and clusters depend on the image cache which is never referenced
 	for _, r := range c.Resources {
 		switch r.Info().Type {
 		case TypeContainer:
@@ -891,36 +947,159 @@ func ParseReferences(c *Config) error {
 		case TypeNomadJob:
 			c := r.(*NomadJob)
 			c.DependsOn = append(c.DependsOn, c.Cluster)
+
+		default:
+			if c, ok := r.(*PluginResource); ok {
+				c.DependsOn = append(c.DependsOn, c.Depends...)
+			}
+		}
+
+		// lifecycle hooks which run in another resource depend on that resource
+		for _, t := range r.Info().Lifecycle.Targets() {
+			if t != fmt.Sprintf("%s.%s", r.Info().Type, r.Info().Name) {
+				r.Info().DependsOn = append(r.Info().DependsOn, t)
+			}
 		}
 	}
 
 	return nil
 }
 
-func parseVariables(abs string, c *Config) error {
+func parseVariables(mc *moduleContext, abs string, c *Config) error {
 	files, err := filepath.Glob(path.Join(abs, "*.hcl"))
 	if err != nil {
 		return err
 	}
 
 	for _, f := range files {
-		err := parseVariableFile(f, c)
+		err := parseVariableFile(mc, f, c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseModules(mc *moduleContext, abs string, c *Config, disabled bool) error {
+	files, err := filepath.Glob(path.Join(abs, "*.hcl"))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err := parseModuleFile(mc, f, c, disabled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseModuleFile parses the module blocks in a config file, the resources
+// for each module are added to the config and the outputs of the module are
+// added to the context so that they can be referenced from the parent
+func parseModuleFile(mc *moduleContext, file string, c *Config, disabled bool) error {
+	parser := hclparse.NewParser()
+	ctx := mc.fileContext(file)
+
+	f, diag := parser.ParseHCLFile(file)
+	if diag.HasErrors() {
+		return errors.New(diag.Error())
+	}
+
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return errors.New("Error getting body")
+	}
+
+	for _, b := range body.Blocks {
+		if b.Type != string(TypeModule) {
+			continue
+		}
+
+		// modules do not support count or for_each
+		if _, err := expandBlock(ctx, b); err != nil {
+			return err
+		}
+
+		moduleName := b.Labels[0]
+		m := NewModule(moduleName)
+		m.Info().Module = moduleName
+
+		err := decodeBody(ctx, f.Bytes, b, m)
+		if err != nil {
+			return err
+		}
+
+		// module variables are set when the config is parsed
+		if d := m.Info().deferred; d != nil {
+			return errors.New((&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid module variables",
+				Detail:   fmt.Sprintf("The variables for module %s can not reference the attributes of resources, these are only known once the resources have been applied.", moduleName),
+				Subject:  d.refs[0].Subject.Ptr(),
+			}).Error())
+		}
+
+		// import the source files for this module
+		if !utils.IsLocalFolder(ensureAbsolute(m.Source, file)) {
+			// get the details
+			dst := utils.GetBlueprintLocalFolder(m.Source)
+			err := getFiles(m.Source, dst)
+			if err != nil {
+				return err
+			}
+
+			// set the source to the local folder
+			m.Source = dst
+		}
+
+		// set the absolute path
+		m.Source = ensureAbsolute(m.Source, file)
+
+		// if the module is disabled ensure
+		setDisabled(m, disabled)
+
+		// resources referenced by the module variables are dependencies of the module
+		dependsOn := append([]string{}, m.Depends...)
+		for _, d := range m.Info().DependsOn {
+			if !contains(dependsOn, d) {
+				dependsOn = append(dependsOn, d)
+			}
+		}
+
+		// each module is parsed with its own context, variables
+		// set by the module do not change the variables of the parent
+		child := mc.newChild()
+
+		err = child.setModuleVariables(ctx, m, b)
+		if err != nil {
+			return err
+		}
+
+		// recursively parse references for the module
+		// ensure we do load the values which might be in module folders
+		err = parseFolder(child, m.Source, c, true, moduleName, m.Disabled, dependsOn, nil, "")
 		if err != nil {
 			return err
 		}
+
+		mc.setModuleOutputs(moduleName, c)
 	}
 
 	return nil
 }
 
-func parseOutputs(abs string, disabled bool, c *Config) error {
+func parseOutputs(mc *moduleContext, abs string, moduleName string, disabled bool, c *Config) error {
 	files, err := filepath.Glob(path.Join(abs, "*.hcl"))
 	if err != nil {
 		return err
 	}
 
 	for _, f := range files {
-		err := parseOutputFile(f, disabled, c)
+		err := parseOutputFile(mc, f, moduleName, disabled, c)
 		if err != nil {
 			return err
 		}
@@ -929,10 +1108,11 @@ func parseOutputs(abs string, disabled bool, c *Config) error {
 	return nil
 }
 
-func parseOutputFile(file string, disabled bool, c *Config) error {
+// parseOutputFile parses the outputs in a config file, outputs defined
+// in a module are named [module].[name] i.e. output.consul.address
+func parseOutputFile(mc *moduleContext, file string, moduleName string, disabled bool, c *Config) error {
 	parser := hclparse.NewParser()
-	ctx.Functions["file_path"] = getFilePathFunc(file)
-	ctx.Functions["file_dir"] = getFileDirFunc(file)
+	ctx := mc.fileContext(file)
 
 	f, diag := parser.ParseHCLFile(file)
 	if diag.HasErrors() {
@@ -948,8 +1128,12 @@ func parseOutputFile(file string, disabled bool, c *Config) error {
 		switch b.Type {
 		case string(TypeOutput):
 			v := NewOutput(b.Labels[0])
+			if moduleName != "" {
+				v = NewOutput(fmt.Sprintf("%s.%s", moduleName, b.Labels[0]))
+				v.Info().Module = moduleName
+			}
 
-			err := decodeBody(file, b, v)
+			err := decodeBody(ctx, f.Bytes, b, v)
 			if err != nil {
 				return err
 			}
@@ -963,14 +1147,14 @@ func parseOutputFile(file string, disabled bool, c *Config) error {
 	return nil
 }
 
-func parseResources(abs string, c *Config, moduleName string, disabled bool, dependsOn []string) error {
+func parseResources(mc *moduleContext, abs string, c *Config, moduleName string, disabled bool, dependsOn []string) error {
 	files, err := filepath.Glob(path.Join(abs, "*.hcl"))
 	if err != nil {
 		return err
 	}
 
 	for _, f := range files {
-		err := parseHCLFile(f, c, moduleName, disabled, dependsOn)
+		err := parseHCLFile(mc, f, c, moduleName, disabled, dependsOn)
 		if err != nil {
 			return err
 		}
@@ -979,12 +1163,55 @@ func parseResources(abs string, c *Config, moduleName string, disabled bool, dep
 	return nil
 }
 
-func setContextVariable(key string, value interface{}) {
+// newModuleContext returns the context for the root of a blueprint
+func newModuleContext() *moduleContext {
+	return &moduleContext{ctx: buildContext(), sources: map[string]variableSource{}}
+}
+
+// newChild returns the context for a module, the variables of the module are
+// initialized with the variables of the parent, the outputs of the parents
+// other modules can not be referenced by the module
+func (m *moduleContext) newChild() *moduleContext {
+	ctx := m.ctx.NewChild()
+	ctx.Variables = map[string]cty.Value{moduleRoot: cty.EmptyObjectVal}
+
+	if v, ok := m.ctx.Variables["var"]; ok {
+		ctx.Variables["var"] = v
+	}
+
+	sources := map[string]variableSource{}
+	for k, v := range m.sources {
+		sources[k] = v
+	}
+
+	return &moduleContext{ctx: ctx, sources: sources}
+}
+
+// fileContext returns the context for the expressions in the given file,
+// the file functions are relative to the file
+func (m *moduleContext) fileContext(file string) *hcl.EvalContext {
+	ctx := m.ctx.NewChild()
+
+	// add the current file path to the context.
+	// this allows any functions which require absolute paths to be able to
+	// build them from relative paths.
+	ctx.Variables = map[string]cty.Value{"path": cty.StringVal(file)}
+
+	ctx.Functions = map[string]function.Function{
+		"file":      getFileFunc(file),
+		"file_path": getFilePathFunc(file),
+		"file_dir":  getFileDirFunc(file),
+	}
+
+	return ctx
+}
+
+func (m *moduleContext) setVariable(key string, value interface{}) {
 	valMap := map[string]cty.Value{}
 
 	// get the existing map
-	if m, ok := ctx.Variables["var"]; ok {
-		valMap = m.AsValueMap()
+	if v, ok := m.ctx.Variables["var"]; ok {
+		valMap = v.AsValueMap()
 	}
 
 	// if the value is string bool convert to a boolean
@@ -1005,20 +1232,187 @@ func setContextVariable(key string, value interface{}) {
 		valMap[key] = v
 	}
 
-	ctx.Variables["var"] = cty.ObjectVal(valMap)
+	m.ctx.Variables["var"] = cty.ObjectVal(valMap)
 }
 
-func setContextVariableIfMissing(key string, value interface{}) {
-	if m, ok := ctx.Variables["var"]; ok {
-		if _, ok := m.AsValueMap()[key]; ok {
+func (m *moduleContext) setVariableIfMissing(key string, value interface{}) {
+	if v, ok := m.ctx.Variables["var"]; ok {
+		if _, ok := v.AsValueMap()[key]; ok {
 			return
 		}
 	}
 
-	setContextVariable(key, value)
+	m.setVariable(key, value)
+}
+
+// setModuleVariables sets the variables defined in the module block,
+// these take precedence over the variables of the parent. The given
+// context is used to evaluate the block in the parent.
+func (m *moduleContext) setModuleVariables(ctx *hcl.EvalContext, mod *Module, b *hclsyntax.Block) error {
+	if mod.Variables.IsNull() {
+		return nil
+	}
+
+	a := b.Body.Attributes["variables"]
+
+	t := mod.Variables.Type()
+	if !t.IsObjectType() && !t.IsMapType() {
+		return errors.New((&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid module variables",
+			Detail:   fmt.Sprintf("The variables for module %s must be a map of variable names to values.", mod.Name),
+			Subject:  a.SrcRange.Ptr(),
+		}).Error())
+	}
+
+	// record the location of each value so that invalid
+	// values are reported in the module block
+	ranges := map[string]hcl.Range{}
+	if oc, ok := a.Expr.(*hclsyntax.ObjectConsExpr); ok {
+		for _, item := range oc.Items {
+			k, diags := item.KeyExpr.Value(ctx)
+			if !diags.HasErrors() && k.Type() == cty.String {
+				ranges[k.AsString()] = item.ValueExpr.Range()
+			}
+		}
+	}
+
+	for k, v := range mod.Variables.AsValueMap() {
+		rng, ok := ranges[k]
+		if !ok {
+			rng = a.SrcRange
+		}
+
+		m.setVariable(k, v)
+		m.sources[k] = variableSource{rng.Ptr(), fmt.Sprintf("the module %s", mod.Name)}
+	}
+
+	return nil
+}
+
+// setModuleOutputs adds the outputs of the module to the context
+// so that they can be referenced using module.[name].output.[key]
+func (m *moduleContext) setModuleOutputs(name string, c *Config) {
+	modules := map[string]cty.Value{}
+	if v, ok := m.ctx.Variables[moduleRoot]; ok && v.LengthInt() > 0 {
+		modules = v.AsValueMap()
+	}
+
+	modules[name] = c.moduleValue(name, true)
+	m.ctx.Variables[moduleRoot] = cty.ObjectVal(modules)
+}
+
+// checkVariable converts the value of the variable to its type and checks the
+// validation rules, errors report the location of the value when it was set in
+// a file, otherwise the location of the variable. The validation rules are
+// evaluated using the given context.
+func (m *moduleContext) checkVariable(ctx *hcl.EvalContext, v *Variable) error {
+	val := m.ctx.Variables["var"].AsValueMap()[v.Name]
+	src, hasSource := m.sources[v.Name]
+
+	// invalid values are reported at their location in a values file or at the
+	// default, values set with --var or environment variables do not have a
+	// location so the rule they break is reported
+	var subject *hcl.Range
+	switch {
+	case src.Range != nil:
+		subject = src.Range
+	case !hasSource:
+		subject = v.Default.(*hcl.Attribute).Range.Ptr()
+	}
+
+	if v.TypeConstraint != nil {
+		t, diags := typeexpr.TypeConstraint(v.TypeConstraint.Expr)
+		if diags.HasErrors() {
+			return errors.New(diags.Error())
+		}
+
+		cv, err := convertVariable(val, t)
+		if err != nil {
+			if subject == nil {
+				subject = v.TypeConstraint.Range.Ptr()
+			}
+
+			return variableError(v, src, subject, fmt.Sprintf("The value is not a valid %s: %s.", typeexpr.TypeString(t), err))
+		}
+
+		m.setVariable(v.Name, cv)
+	}
+
+	for _, vv := range v.Validation {
+		res, diags := vv.Condition.Value(ctx)
+		if diags.HasErrors() {
+			return errors.New(diags.Error())
+		}
+
+		if res.IsNull() || !res.IsKnown() || res.Type() != cty.Bool {
+			return errors.New((&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid validation condition",
+				Detail:   fmt.Sprintf("The condition for variable %s must return a bool.", v.Name),
+				Subject:  vv.Condition.Range().Ptr(),
+			}).Error())
+		}
+
+		if res.False() {
+			rng := subject
+			if rng == nil {
+				rng = vv.Condition.Range().Ptr()
+			}
+
+			return variableError(v, src, rng, vv.ErrorMessage)
+		}
+	}
+
+	return nil
+}
+
+// variableError returns an error for an invalid variable value at the given location
+func variableError(v *Variable, src variableSource, subject *hcl.Range, detail string) error {
+	if src.Origin != "" {
+		detail = fmt.Sprintf("%s The value was set by %s.", detail, src.Origin)
+	}
+
+	return errors.New((&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("Invalid value for variable %s", v.Name),
+		Detail:   detail,
+		Subject:  subject,
+	}).Error())
 }
 
-func parseYardHCL(file string, c *Config) error {
+// convertVariable converts the value to the given type, values set with --var or
+// environment variables are strings, these are parsed as HCL when the type is not
+// a string i.e. --var 'regions=["eu", "us"]'
+func convertVariable(val cty.Value, t cty.Type) (cty.Value, error) {
+	cv, err := convert.Convert(val, t)
+	if err == nil {
+		return cv, nil
+	}
+
+	if val.Type() != cty.String || val.IsNull() || !val.IsKnown() {
+		return cty.NilVal, err
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(val.AsString()), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, err
+	}
+
+	pv, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, err
+	}
+
+	cv, perr := convert.Convert(pv, t)
+	if perr != nil {
+		return cty.NilVal, err
+	}
+
+	return cv, nil
+}
+
+func parseYardHCL(mc *moduleContext, file string, c *Config) error {
 	parser := hclparse.NewParser()
 
 	f, diag := parser.ParseHCLFile(file)
@@ -1033,7 +1427,7 @@ func parseYardHCL(file string, c *Config) error {
 
 	bp := &Blueprint{}
 
-	diag = gohcl.DecodeBody(body, ctx, bp)
+	diag = gohcl.DecodeBody(body, mc.fileContext(file), bp)
 	if diag.HasErrors() {
 		return errors.New(diag.Error())
 	}
@@ -1186,31 +1580,6 @@ func buildContext() *hcl.EvalContext {
 		},
 	})
 
-	var FileFunc = function.New(&function.Spec{
-		Params: []function.Parameter{
-			{
-				Name:             "path",
-				Type:             cty.String,
-				AllowDynamicType: true,
-			},
-		},
-		Type: function.StaticReturnType(cty.String),
-		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-			// get the current file path from the context
-			path := ctx.Variables["path"].AsString()
-			// conver the file path to an absolute
-			fp := ensureAbsolute(args[0].AsString(), path)
-
-			// read the contents of the file
-			d, err := ioutil.ReadFile(fp)
-			if err != nil {
-				return cty.StringVal(""), err
-			}
-
-			return cty.StringVal(string(d)), nil
-		},
-	})
-
 	var DataFunc = function.New(&function.Spec{
 		Params: []function.Parameter{
 			{
@@ -1251,19 +1620,43 @@ func buildContext() *hcl.EvalContext {
 	ctx.Functions["k8s_config_docker"] = KubeConfigDockerFunc
 	ctx.Functions["home"] = HomeFunc
 	ctx.Functions["shipyard"] = ShipyardFunc
-	ctx.Functions["file"] = FileFunc
 	ctx.Functions["data"] = DataFunc
 	ctx.Functions["docker_ip"] = DockerIPFunc
 	ctx.Functions["docker_host"] = DockerHostFunc
 	ctx.Functions["shipyard_ip"] = ShipyardIPFunc
 	ctx.Functions["cluster_api"] = ClusterAPIFunc
 
-	// the functions file_path and file_dir are added dynamically when processing a file
-	// this is because the need a reference to the current file
+	// the functions file, file_path and file_dir are added dynamically when processing
+	// a file this is because the need a reference to the current file
 
 	return ctx
 }
 
+func getFileFunc(path string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name:             "path",
+				Type:             cty.String,
+				AllowDynamicType: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			// conver the file path to an absolute
+			fp := ensureAbsolute(args[0].AsString(), path)
+
+			// read the contents of the file
+			d, err := ioutil.ReadFile(fp)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(string(d)), nil
+		},
+	})
+}
+
 func getFilePathFunc(path string) function.Function {
 	return function.New(&function.Spec{
 		Type: function.StaticReturnType(cty.String),
@@ -1285,17 +1678,89 @@ func getFileDirFunc(path string) function.Function {
 	})
 }
 
-func decodeBody(path string, b *hclsyntax.Block, p interface{}) error {
-	// add the current file path to the context.
-	// this allows any functions which require absolute paths to be able to
-	// build them from relative paths.
-	ctx.Variables["path"] = cty.StringVal(path)
+func decodeBody(ctx *hcl.EvalContext, src []byte, b *hclsyntax.Block, p interface{}) error {
+	return decodeResourceBody(ctx, src, b, nil, p)
+}
+
+// decodePluginBody decodes the attributes defined by the plugin schema into
+// the Attributes map, the remaining body is decoded into the resource
+func decodePluginBody(ctx *hcl.EvalContext, src []byte, b *hclsyntax.Block, spec hcldec.ObjectSpec, p *PluginResource) error {
+	return decodeResourceBody(ctx, src, b, spec, p)
+}
+
+// validateDuration returns an error when the attribute attr in the nested
+// block of b is not a valid duration, the diagnostic points at the attribute.
+// Empty values are not validated, these are unset or reference other resources.
+func validateDuration(b *hclsyntax.Block, block, attr, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err == nil && d >= 0 {
+		return nil
+	}
+
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid duration",
+		Detail:   fmt.Sprintf("The %s attribute in the %s block must be a positive duration such as \"30s\" or \"5m\", got %q.", attr, block, value),
+		Subject:  b.DefRange().Ptr(),
+	}
+
+	for _, nb := range b.Body.Blocks {
+		if a, ok := nb.Body.Attributes[attr]; ok && nb.Type == block {
+			diag.Subject = a.Expr.Range().Ptr()
+		}
+	}
+
+	return errors.New(diag.Error())
+}
 
-	diag := gohcl.DecodeBody(b.Body, ctx, p)
+// decodeWithContext decodes body, the body of block b, into p using the given
+// context, when spec is not nil p must be a plugin resource
+func decodeWithContext(ctx *hcl.EvalContext, b *hclsyntax.Block, body hcl.Body, spec hcldec.ObjectSpec, p interface{}) hcl.Diagnostics {
+	if spec == nil {
+		return gohcl.DecodeBody(body, ctx, p)
+	}
+
+	pr := p.(*PluginResource)
+
+	val, remain, diag := hcldec.PartialDecode(body, spec, ctx)
 	if diag.HasErrors() {
-		return errors.New(diag.Error())
+		return diag
 	}
 
+	diag = gohcl.DecodeBody(remain, ctx, pr)
+	if diag.HasErrors() {
+		return diag
+	}
+
+	attrs := map[string]interface{}{}
+
+	d, err := ctyjson.Marshal(val, val.Type())
+	if err == nil {
+		err = json.Unmarshal(d, &attrs)
+	}
+
+	if err != nil {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to convert attributes",
+			Detail:   fmt.Sprintf("Unable to convert attributes for resource %s.%s: %s", b.Type, b.Labels[0], err),
+			Subject:  b.DefRange().Ptr(),
+		}}
+	}
+
+	// optional attributes which have not been set are null
+	for k, v := range attrs {
+		if v == nil {
+			delete(attrs, k)
+		}
+	}
+
+	pr.Attributes = attrs
+
 	return nil
 }
 
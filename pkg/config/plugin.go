@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/hcl2/ext/typeexpr"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcldec"
+)
+
+// PluginResource is a resource with a type which is provided by a plugin,
+// the values for the attributes defined in the plugin schema are stored in
+// Attributes
+type PluginResource struct {
+	ResourceInfo `hcl:",remain" mapstructure:",squash"`
+
+	Depends []string `hcl:"depends_on,optional" json:"depends,omitempty"`
+
+	// Attributes contains the values for the attributes defined by the plugin schema
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// NewPluginResource creates a new resource for the given plugin type
+func NewPluginResource(t ResourceType, name string) *PluginResource {
+	return &PluginResource{ResourceInfo: ResourceInfo{Name: name, Type: t, Status: PendingCreation}}
+}
+
+// PluginSchema defines a resource type which is provided by a plugin
+type PluginSchema struct {
+	Type       ResourceType
+	Attributes []PluginAttribute
+}
+
+// PluginAttribute defines an attribute for a resource type provided by a plugin
+type PluginAttribute struct {
+	Name string
+	// Type is an HCL type constraint i.e. string, number, bool, list(string)
+	Type     string
+	Required bool
+}
+
+var pluginTypes = map[ResourceType]hcldec.ObjectSpec{}
+var pluginTypesMutex = sync.Mutex{}
+
+// builtinTypes cannot be replaced by plugins
+var builtinTypes = []ResourceType{
+	TypeContainer, TypeContainerIngress, TypeDocs, TypeExecLocal, TypeExecRemote,
+	TypeHelm, TypeImageCache, TypeIngress, TypeK8sCluster, TypeK8sConfig, TypeK8sIngress,
+	TypeLegacyIngress, TypeModule, TypeNetwork, TypeNomadCluster, TypeNomadIngress,
+	TypeNomadJob, TypeOutput, TypeSidecar, TypeTemplate, TypeVariable,
+}
+
+// RegisterPluginType allows resources of the given type to be parsed from HCL and
+// loaded from the state, an error is returned if the type already exists or the
+// schema is invalid
+func RegisterPluginType(s PluginSchema) error {
+	for _, t := range builtinTypes {
+		if t == s.Type {
+			return fmt.Errorf("Unable to register plugin type %s, type is built in", s.Type)
+		}
+	}
+
+	spec := hcldec.ObjectSpec{}
+	for _, a := range s.Attributes {
+		expr, diags := hclsyntax.ParseExpression([]byte(a.Type), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			return fmt.Errorf("Invalid type %s for attribute %s of plugin type %s: %s", a.Type, a.Name, s.Type, diags.Error())
+		}
+
+		ty, diags := typeexpr.TypeConstraint(expr)
+		if diags.HasErrors() {
+			return fmt.Errorf("Invalid type %s for attribute %s of plugin type %s: %s", a.Type, a.Name, s.Type, diags.Error())
+		}
+
+		spec[a.Name] = &hcldec.AttrSpec{Name: a.Name, Type: ty, Required: a.Required}
+	}
+
+	pluginTypesMutex.Lock()
+	defer pluginTypesMutex.Unlock()
+
+	if _, ok := pluginTypes[s.Type]; ok {
+		return fmt.Errorf("Unable to register plugin type %s, type has already been registered", s.Type)
+	}
+
+	pluginTypes[s.Type] = spec
+
+	return nil
+}
+
+// IsPluginType returns true when the resource type has been registered by a plugin
+func IsPluginType(t ResourceType) bool {
+	_, ok := pluginTypeSpec(t)
+	return ok
+}
+
+func pluginTypeSpec(t ResourceType) (hcldec.ObjectSpec, bool) {
+	pluginTypesMutex.Lock()
+	defer pluginTypesMutex.Unlock()
+
+	spec, ok := pluginTypes[t]
+	return spec, ok
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPluginType ResourceType = "mock_saas"
+
+func setupPluginType(t *testing.T) {
+	err := RegisterPluginType(PluginSchema{
+		Type: testPluginType,
+		Attributes: []PluginAttribute{
+			PluginAttribute{Name: "endpoint", Type: "string", Required: true},
+			PluginAttribute{Name: "replicas", Type: "number"},
+			PluginAttribute{Name: "tags", Type: "list(string)"},
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		pluginTypesMutex.Lock()
+		defer pluginTypesMutex.Unlock()
+
+		delete(pluginTypes, testPluginType)
+	})
+}
+
+func TestRegisterPluginTypeWithBuiltinTypeReturnsError(t *testing.T) {
+	err := RegisterPluginType(PluginSchema{Type: TypeContainer})
+	assert.Error(t, err)
+}
+
+func TestRegisterPluginTypeTwiceReturnsError(t *testing.T) {
+	setupPluginType(t)
+
+	err := RegisterPluginType(PluginSchema{Type: testPluginType})
+	assert.Error(t, err)
+}
+
+func TestRegisterPluginTypeWithInvalidAttributeTypeReturnsError(t *testing.T) {
+	err := RegisterPluginType(PluginSchema{
+		Type:       "invalid_plugin",
+		Attributes: []PluginAttribute{PluginAttribute{Name: "endpoint", Type: "strong"}},
+	})
+	assert.Error(t, err)
+	assert.False(t, IsPluginType("invalid_plugin"))
+}
+
+func TestPluginResourceParsesAttributes(t *testing.T) {
+	setupPluginType(t)
+
+	c, _, cleanup := setupTestConfig(t, pluginConfig)
+	defer cleanup()
+
+	r, err := c.FindResource("mock_saas.api")
+	assert.NoError(t, err)
+
+	pr := r.(*PluginResource)
+	assert.Equal(t, "http://localhost:9090", pr.Attributes["endpoint"])
+	assert.Equal(t, float64(3), pr.Attributes["replicas"])
+	assert.Equal(t, []interface{}{"a", "b"}, pr.Attributes["tags"])
+	assert.Equal(t, []string{"network.test"}, pr.DependsOn)
+	assert.Equal(t, "10m", pr.Timeouts.Create)
+}
+
+func TestPluginResourceOmitsUnsetAttributes(t *testing.T) {
+	setupPluginType(t)
+
+	c, _, cleanup := setupTestConfig(t, pluginConfig)
+	defer cleanup()
+
+	r, err := c.FindResource("mock_saas.web")
+	assert.NoError(t, err)
+
+	pr := r.(*PluginResource)
+	assert.Len(t, pr.Attributes, 1)
+}
+
+func TestPluginResourceWithMissingRequiredAttributeReturnsError(t *testing.T) {
+	setupPluginType(t)
+
+	dir, cleanup := createTestFiles(t, pluginMissingAttributeConfig)
+	defer cleanup()
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+}
+
+func TestPluginResourceNotRegisteredReturnsError(t *testing.T) {
+	dir, cleanup := createTestFiles(t, pluginConfig)
+	defer cleanup()
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+}
+
+func TestPluginResourceLoadsFromState(t *testing.T) {
+	setupPluginType(t)
+
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+	defer os.Setenv(utils.HomeEnvName(), home)
+
+	c := New()
+	pr := NewPluginResource(testPluginType, "api")
+	pr.Attributes = map[string]interface{}{"endpoint": "http://localhost:9090"}
+	c.AddResource(pr)
+
+	err := c.ToJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	c2 := New()
+	err = c2.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c2.FindResource("mock_saas.api")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090", r.(*PluginResource).Attributes["endpoint"])
+}
+
+var pluginConfig = `
+network "test" {
+	subnet = "10.0.0.0/16"
+}
+
+mock_saas "api" {
+	depends_on = ["network.test"]
+
+	endpoint = "http://localhost:9090"
+	replicas = 3
+	tags     = ["a", "b"]
+
+	timeouts {
+		create = "10m"
+	}
+}
+
+mock_saas "web" {
+	endpoint = "http://localhost:9091"
+}
+`
+
+var pluginMissingAttributeConfig = `
+mock_saas "api" {
+	replicas = 3
+}
+`
@@ -0,0 +1,655 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// resourceRoot is the root name for expressions which reference the attributes
+// of other resources i.e. resource.container.db.ip_address
+const resourceRoot = "resource"
+
+// moduleRoot is the root name for expressions which reference the
+// outputs of modules i.e. module.consul.output.address
+const moduleRoot = "module"
+
+// referenceTypes are the resource types which can be referenced from
+// expressions using [type].[name], i.e. network.cloud
+var referenceTypes = []ResourceType{
+	TypeContainer,
+	TypeContainerIngress,
+	TypeDocs,
+	TypeExecLocal,
+	TypeExecRemote,
+	TypeHelm,
+	TypeImageCache,
+	TypeIngress,
+	TypeK8sCluster,
+	TypeK8sConfig,
+	TypeK8sIngress,
+	TypeNetwork,
+	TypeNomadCluster,
+	TypeNomadIngress,
+	TypeNomadJob,
+	TypeSidecar,
+	TypeTemplate,
+}
+
+// resourceReference is a reference to another resource from an expression
+type resourceReference struct {
+	// Address of the referenced resource i.e. container.db
+	Address string
+	// Deferred is set for references to the attributes of a resource
+	// i.e. resource.container.db.ip_address, these are only known
+	// once the resource has been applied
+	Deferred bool
+	// Name of the top level attribute or block which contains the reference
+	Name string
+	// Range of the top level attribute or block which contains the reference
+	Range hcl.Range
+	// Subject is the range of the reference
+	Subject hcl.Range
+	// ModuleOutput is set for references to the outputs of modules
+	// i.e. module.consul.output.address, the address is the output
+	ModuleOutput bool
+}
+
+// deferredBody holds the config for a resource which references other resources,
+// the referenced attributes are only known once the resources have been applied
+type deferredBody struct {
+	block *hclsyntax.Block
+	spec  hcldec.ObjectSpec
+	ctx   *hcl.EvalContext
+	refs  []resourceReference
+	// hash contains the config for the deferred attributes which is used
+	// to generate the hash of the resource, see deferredHash
+	hash map[string]string
+}
+
+// findResourceReferences returns the references to other resources
+// in the top level attributes and blocks of the given body, references
+// are either to the resource i.e. network.cloud, to the attributes of
+// the resource i.e. resource.container.db.ip_address, or to the outputs
+// of a module i.e. module.consul.output.address
+func findResourceReferences(ctx *hcl.EvalContext, b *hclsyntax.Body) []resourceReference {
+	refs := []resourceReference{}
+
+	find := func(name string, rng hcl.Range, n hclsyntax.Node) {
+		hclsyntax.VisitAll(n, func(n hclsyntax.Node) hcl.Diagnostics {
+			if e, ok := n.(*hclsyntax.ScopeTraversalExpr); ok {
+				if addr := moduleOutputAddress(e.Traversal); addr != "" {
+					// outputs which reference the attributes of resources
+					// are unknown until the resources have been applied
+					v, diags := e.Traversal.TraverseAbs(ctx)
+
+					refs = append(refs, resourceReference{
+						Address:      addr,
+						Deferred:     !diags.HasErrors() && !v.IsWhollyKnown(),
+						Name:         name,
+						Range:        rng,
+						Subject:      e.SrcRange,
+						ModuleOutput: true,
+					})
+				}
+
+				if addr, deferred := referenceAddress(e.Traversal); addr != "" {
+					refs = append(refs, resourceReference{
+						Address:  addr,
+						Deferred: deferred,
+						Name:     name,
+						Range:    rng,
+						Subject:  e.SrcRange,
+					})
+				}
+			}
+
+			return nil
+		})
+	}
+
+	for n, a := range b.Attributes {
+		find(n, a.SrcRange, a.Expr)
+	}
+
+	for _, bl := range b.Blocks {
+		find(bl.Type, bl.Range(), bl.Body)
+	}
+
+	// attributes are stored in a map, sort the references
+	// so that dependencies are added in the order of the config
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].Subject.Start.Byte < refs[j].Subject.Start.Byte
+	})
+
+	return refs
+}
+
+// referenceAddress returns the address of the resource for the traversals
+// [type].[name] and resource.[type].[name], deferred is true for references
+// to the attributes of a resource. References to instances of resources
+// created with count or for_each include the index i.e. container.consul[0].
+// A blank string is returned when the traversal does not reference a resource.
+func referenceAddress(t hcl.Traversal) (address string, deferred bool) {
+	typ := t.RootName()
+	i := 1
+
+	if typ == resourceRoot {
+		if len(t) < 2 {
+			return "", false
+		}
+
+		a, ok := t[1].(hcl.TraverseAttr)
+		if !ok {
+			return "", false
+		}
+
+		typ = a.Name
+		deferred = true
+		i = 2
+	} else if !isReferenceType(typ) {
+		return "", false
+	}
+
+	if len(t) <= i {
+		return "", false
+	}
+
+	name, ok := t[i].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+
+	address = fmt.Sprintf("%s.%s", typ, name.Name)
+
+	if len(t) > i+1 {
+		if idx, ok := t[i+1].(hcl.TraverseIndex); ok && (idx.Key.Type() == cty.Number || idx.Key.Type() == cty.String) {
+			address = instanceName(address, idx.Key)
+		}
+	}
+
+	return address, deferred
+}
+
+// moduleOutputAddress returns the address of the output for the traversal
+// module.[name].output.[key] i.e. output.consul.address, a blank string is
+// returned when the traversal does not reference the output of a module
+func moduleOutputAddress(t hcl.Traversal) string {
+	if t.RootName() != moduleRoot || len(t) < 4 {
+		return ""
+	}
+
+	name, ok := t[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+
+	if o, ok := t[2].(hcl.TraverseAttr); !ok || o.Name != string(TypeOutput) {
+		return ""
+	}
+
+	key, ok := t[3].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s.%s.%s", TypeOutput, name.Name, key.Name)
+}
+
+func isReferenceType(t string) bool {
+	for _, rt := range referenceTypes {
+		if string(rt) == t {
+			return true
+		}
+	}
+
+	_, ok := pluginTypeSpec(ResourceType(t))
+	return ok
+}
+
+// addressVariables returns the variables for the references to resources,
+// a reference to a resource i.e. network.cloud evaluates to its address
+func addressVariables(refs []resourceReference) map[string]cty.Value {
+	types := map[string]map[string]cty.Value{}
+
+	for _, r := range refs {
+		// module outputs are set in the parser context
+		if r.Deferred || r.ModuleOutput {
+			continue
+		}
+
+		parts := strings.SplitN(r.Address, ".", 2)
+		if types[parts[0]] == nil {
+			types[parts[0]] = map[string]cty.Value{}
+		}
+
+		types[parts[0]][parts[1]] = cty.StringVal(r.Address)
+	}
+
+	vars := map[string]cty.Value{}
+	for t, n := range types {
+		vars[t] = cty.ObjectVal(instanceValues(n))
+	}
+
+	return vars
+}
+
+// decodeResourceBody decodes the block into the resource, references to other
+// resources are added as dependencies. Attributes which reference the attributes
+// of other resources are unknown when the config is parsed, these are left unset
+// and decoded by ResolveReferences once the referenced resources have been applied.
+func decodeResourceBody(ctx *hcl.EvalContext, src []byte, b *hclsyntax.Block, spec hcldec.ObjectSpec, p interface{}) error {
+	refs := findResourceReferences(ctx, b.Body)
+
+	deferred := []resourceReference{}
+	for _, ref := range refs {
+		if ref.Deferred {
+			deferred = append(deferred, ref)
+		}
+	}
+
+	actx := ctx.NewChild()
+	actx.Variables = addressVariables(refs)
+
+	var diag hcl.Diagnostics
+	var d *deferredBody
+
+	if len(deferred) == 0 {
+		diag = decodeWithContext(actx, b, b.Body, spec, p)
+	} else {
+		d = &deferredBody{block: b, spec: spec, ctx: actx, refs: deferred}
+
+		pctx := actx.NewChild()
+		pctx.Variables = map[string]cty.Value{resourceRoot: cty.DynamicVal}
+
+		// the unknown attributes are left unset until the references are resolved
+		diag = decodeWithContext(pctx, b, &knownBody{b.Body, pctx}, spec, p)
+
+		h, err := deferredHash(pctx, src, b, d.names())
+		if err != nil {
+			return err
+		}
+
+		d.hash = h
+	}
+
+	if diag.HasErrors() {
+		return errors.New(diag.Error())
+	}
+
+	r, ok := p.(Resource)
+	if !ok {
+		return nil
+	}
+
+	err := r.Info().Retry.validate(b)
+	if err != nil {
+		return err
+	}
+
+	err = r.Info().Timeouts.validate(b)
+	if err != nil {
+		return err
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	// create a new slice as the dependencies can be shared with other resources
+	deps := append([]string{}, r.Info().DependsOn...)
+	for _, ref := range refs {
+		if !contains(deps, ref.Address) {
+			deps = append(deps, ref.Address)
+		}
+	}
+
+	r.Info().DependsOn = deps
+	r.Info().references = refs
+	r.Info().deferred = d
+
+	return nil
+}
+
+// deferredHash returns the config for the attributes and blocks with the given
+// names, src is the source of the file containing b. The attributes are not set until the referenced resources have been
+// applied so the hash of the resource uses the source of the expressions and
+// the values of any variables referenced by the expressions
+func deferredHash(ctx *hcl.EvalContext, src []byte, b *hclsyntax.Block, names map[string]bool) (map[string]string, error) {
+	hash := map[string]string{}
+
+	add := func(name string, rng hcl.Range, n hclsyntax.Node) {
+		vals := []string{}
+		hclsyntax.VisitAll(n, func(n hclsyntax.Node) hcl.Diagnostics {
+			// the values of the referenced resources are not part of the hash
+			if e, ok := n.(*hclsyntax.ScopeTraversalExpr); ok && e.Traversal.RootName() != resourceRoot {
+				v, _ := e.Traversal.TraverseAbs(ctx)
+				vals = append(vals, v.GoString())
+			}
+
+			return nil
+		})
+
+		hash[name] += string(rng.SliceBytes(src)) + strings.Join(vals, ",")
+	}
+
+	for n, a := range b.Body.Attributes {
+		if names[n] {
+			add(n, a.SrcRange, a.Expr)
+		}
+	}
+
+	for _, bl := range b.Body.Blocks {
+		if names[bl.Type] {
+			add(bl.Type, bl.Range(), bl.Body)
+		}
+	}
+
+	return hash, nil
+}
+
+// checkReferences returns an error containing the location of
+// any references to resources which do not exist in the config
+func (c *Config) checkReferences() error {
+	diags := hcl.Diagnostics{}
+
+	for _, r := range c.Resources {
+		for _, ref := range r.Info().references {
+			if _, err := c.FindResourceInstances(ref.Address); err == nil {
+				continue
+			}
+
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared resource",
+				Detail:   fmt.Sprintf("Resource %s.%s references %s which has not been declared.", r.Info().Type, r.Info().Name, ref.Address),
+				Subject:  ref.Subject.Ptr(),
+			})
+		}
+	}
+
+	if !diags.HasErrors() {
+		return nil
+	}
+
+	// report all of the missing references
+	errs := []string{}
+	for _, d := range diags {
+		errs = append(errs, d.Error())
+	}
+
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+// knownBody wraps the body of a resource block when parsing, attributes which
+// reference the attributes of other resources are unknown until the resources
+// have been applied. Unknown attributes are removed from the content of the body
+// so that they are left unset rather than failing to decode.
+type knownBody struct {
+	*hclsyntax.Body
+	ctx *hcl.EvalContext
+}
+
+func (k *knownBody) Content(s *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	c, diags := k.Body.Content(k.schema(s))
+
+	return k.content(c), diags
+}
+
+func (k *knownBody) PartialContent(s *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	c, remain, diags := k.Body.PartialContent(k.schema(s))
+	if sb, ok := remain.(*hclsyntax.Body); ok {
+		remain = &knownBody{sb, k.ctx}
+	}
+
+	return k.content(c), remain, diags
+}
+
+// schema returns a copy of the schema where the unknown attributes are optional
+func (k *knownBody) schema(s *hcl.BodySchema) *hcl.BodySchema {
+	ks := &hcl.BodySchema{Blocks: s.Blocks}
+	for _, a := range s.Attributes {
+		if k.unknown(a.Name) {
+			a.Required = false
+		}
+
+		ks.Attributes = append(ks.Attributes, a)
+	}
+
+	return ks
+}
+
+// content removes the unknown attributes from the content, nested
+// blocks are wrapped so that their unknown attributes are also removed
+func (k *knownBody) content(c *hcl.BodyContent) *hcl.BodyContent {
+	if c == nil {
+		return nil
+	}
+
+	for n := range c.Attributes {
+		if k.unknown(n) {
+			delete(c.Attributes, n)
+		}
+	}
+
+	for _, b := range c.Blocks {
+		if sb, ok := b.Body.(*hclsyntax.Body); ok {
+			b.Body = &knownBody{sb, k.ctx}
+		}
+	}
+
+	return c
+}
+
+// unknown returns true when the value of the attribute is not known, attributes
+// which can not be evaluated are not unknown so that the errors are reported
+func (k *knownBody) unknown(name string) bool {
+	a, ok := k.Body.Attributes[name]
+	if !ok {
+		return false
+	}
+
+	v, diags := a.Expr.Value(k.ctx)
+
+	return !diags.HasErrors() && !v.IsWhollyKnown()
+}
+
+// names returns the names of the attributes and blocks which reference other resources
+func (d *deferredBody) names() map[string]bool {
+	n := map[string]bool{}
+	for _, ref := range d.refs {
+		n[ref.Name] = true
+	}
+
+	return n
+}
+
+// ResolveReferences decodes the attributes of the resource which reference
+// other resources, the values of the referenced resources are read from the
+// config. This must be called after the referenced resources have been applied.
+//
+// Only the referenced resources are read, these are dependencies of the resource
+// and are not changed while it is applied. Other resources in the config may
+// be applied concurrently.
+func (c *Config) ResolveReferences(r Resource) error {
+	d := r.Info().deferred
+	if d == nil {
+		return nil
+	}
+
+	// the whole block is decoded again, references which are known
+	// when parsing need to be resolved as well as the deferred references
+	refs := []resourceReference{}
+	for _, ref := range r.Info().references {
+		if ref.Deferred || ref.ModuleOutput {
+			refs = append(refs, ref)
+		}
+	}
+
+	res, mods, err := c.referencedValues(refs)
+	if err != nil {
+		return fmt.Errorf("Unable to resolve references for resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	ectx := d.ctx.NewChild()
+	ectx.Variables = map[string]cty.Value{resourceRoot: res, moduleRoot: mods}
+
+	// decode the body into a new resource so that values which
+	// have been processed after parsing are not overwritten
+	n := reflect.New(reflect.TypeOf(r).Elem()).Interface()
+	if pr, ok := r.(*PluginResource); ok {
+		n = NewPluginResource(pr.Type, pr.Name)
+	}
+
+	diag := decodeWithContext(ectx, d.block, d.block.Body, d.spec, n)
+	if diag.HasErrors() {
+		return fmt.Errorf("Unable to resolve references for resource %s.%s: %s", r.Info().Type, r.Info().Name, diag.Error())
+	}
+
+	names := d.names()
+	copyFields(reflect.ValueOf(r).Elem(), reflect.ValueOf(n).Elem(), names)
+
+	// plugin attributes are not struct fields
+	if pr, ok := r.(*PluginResource); ok {
+		for k := range names {
+			if v, ok := n.(*PluginResource).Attributes[k]; ok {
+				pr.Attributes[k] = v
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyFields copies the struct fields with the given hcl names from src to dst
+func copyFields(dst, src reflect.Value, names map[string]bool) {
+	t := dst.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		// the resource info is embedded
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			copyFields(dst.Field(i), src.Field(i), names)
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("hcl"), ",")[0]
+		if name != "" && names[name] {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// referencedValues returns the referenced resources as an object which can be
+// referenced using resource.[type].[name].[attribute], and the referenced module
+// outputs as an object which can be referenced using module.[name].output.[key]
+func (c *Config) referencedValues(refs []resourceReference) (cty.Value, cty.Value, error) {
+	types := map[string]map[string]cty.Value{}
+	modules := map[string]map[string]cty.Value{}
+
+	for _, ref := range refs {
+		rs, err := c.FindResourceInstances(ref.Address)
+		if err != nil {
+			return cty.NilVal, cty.NilVal, err
+		}
+
+		for _, r := range rs {
+			// module outputs are strings
+			if o, ok := r.(*Output); ok && ref.ModuleOutput {
+				if modules[o.Module] == nil {
+					modules[o.Module] = map[string]cty.Value{}
+				}
+
+				modules[o.Module][strings.TrimPrefix(o.Name, o.Module+".")] = cty.StringVal(o.Value)
+				continue
+			}
+
+			v, err := resourceValue(r)
+			if err != nil {
+				return cty.NilVal, cty.NilVal, err
+			}
+
+			typ := string(r.Info().Type)
+			if types[typ] == nil {
+				types[typ] = map[string]cty.Value{}
+			}
+
+			types[typ][r.Info().Name] = v
+		}
+	}
+
+	res := map[string]cty.Value{}
+	for t, r := range types {
+		res[t] = cty.ObjectVal(instanceValues(r))
+	}
+
+	mods := map[string]cty.Value{}
+	for m, o := range modules {
+		mods[m] = cty.ObjectVal(map[string]cty.Value{string(TypeOutput): cty.ObjectVal(o)})
+	}
+
+	return cty.ObjectVal(res), cty.ObjectVal(mods), nil
+}
+
+// moduleValue returns the outputs of the module with the given name, when parsing
+// outputs which reference the attributes of other resources are unknown
+func (c *Config) moduleValue(name string, parsing bool) cty.Value {
+	outputs := map[string]cty.Value{}
+
+	for _, r := range c.Resources {
+		o, ok := r.(*Output)
+		if !ok || o.Module != name {
+			continue
+		}
+
+		key := strings.TrimPrefix(o.Name, name+".")
+		outputs[key] = cty.StringVal(o.Value)
+
+		if parsing && o.deferred != nil {
+			outputs[key] = cty.UnknownVal(cty.String)
+		}
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{string(TypeOutput): cty.ObjectVal(outputs)})
+}
+
+// resourceValue converts the resource to a cty value using
+// the same attribute names as the state file
+func resourceValue(r Resource) (cty.Value, error) {
+	d, err := json.Marshal(r)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	t, err := ctyjson.ImpliedType(d)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	v, err := ctyjson.Unmarshal(d, t)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("Unable to serialize resource %s.%s: %s", r.Info().Type, r.Info().Name, err)
+	}
+
+	return v, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
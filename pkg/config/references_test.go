@@ -0,0 +1,380 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func parseReferencesConfig(t *testing.T, contents string) *Config {
+	dir, cleanup := createTestFiles(t, contents)
+	t.Cleanup(cleanup)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.NoError(t, err)
+
+	return c
+}
+
+func TestResourceReferencesAreAddedAsDependencies(t *testing.T) {
+	c := parseReferencesConfig(t, referencesConfig)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"container.db"}, r.Info().DependsOn)
+
+	o, err := c.FindResource("output.k8s_api")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"k8s_cluster.k3s"}, o.Info().DependsOn)
+}
+
+func TestStringReferencesAreAddedAsDependencies(t *testing.T) {
+	c := parseReferencesConfig(t, stringReferencesConfig)
+
+	err := ParseReferences(c)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+	assert.Contains(t, r.Info().DependsOn, "network.cloud")
+
+	r, err = c.FindResource("helm.consul")
+	assert.NoError(t, err)
+	assert.Contains(t, r.Info().DependsOn, "k8s_cluster.k3s")
+}
+
+func TestResourceReferencesAreCreatedAsDAGEdges(t *testing.T) {
+	c := parseReferencesConfig(t, referencesConfig)
+
+	d, err := c.DoYaLikeDAGs()
+	assert.NoError(t, err)
+
+	db, _ := c.FindResource("container.db")
+	web, _ := c.FindResource("container.web")
+
+	assert.Contains(t, d.DownEdges(db).List(), web)
+}
+
+func TestResourceReferencesAreNotSetWhenParsed(t *testing.T) {
+	c := parseReferencesConfig(t, referencesConfig)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+
+	web := r.(*Container)
+	assert.Empty(t, web.EnvVar)
+	assert.Equal(t, "web:1.0", web.Image.Name)
+	assert.Equal(t, "static", web.Environment[0].Value)
+}
+
+func TestRequiredResourceReferencesAreNotSetWhenParsed(t *testing.T) {
+	c := parseReferencesConfig(t, requiredReferencesConfig)
+
+	r, err := c.FindResource("network.copy")
+	assert.NoError(t, err)
+	assert.Empty(t, r.(*Network).Subnet)
+
+	r, err = c.FindResource("container.web")
+	assert.NoError(t, err)
+	assert.Empty(t, r.(*Container).Image.Name)
+
+	err = c.ResolveReferences(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres:13", r.(*Container).Image.Name)
+
+	r, _ = c.FindResource("network.copy")
+	err = c.ResolveReferences(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.5.0.0/16", r.(*Network).Subnet)
+}
+
+func TestResolveReferencesSetsValues(t *testing.T) {
+	c := parseReferencesConfig(t, referencesConfig)
+
+	r, _ := c.FindResource("container.db")
+	db := r.(*Container)
+	db.IPAddress = "10.5.0.2"
+	db.AssignedPorts = map[string]string{"5432": "32768"}
+
+	r, _ = c.FindResource("k8s_cluster.k3s")
+	r.(*K8sCluster).APIAddress = "https://127.0.0.1:6443"
+
+	r, _ = c.FindResource("container.web")
+	web := r.(*Container)
+
+	err := c.ResolveReferences(web)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10.5.0.2", web.EnvVar["DB_ADDR"])
+	assert.Equal(t, "10.5.0.2:32768", web.EnvVar["DB_HOST_ADDR"])
+	assert.Equal(t, "web:1.0", web.Image.Name)
+
+	o, _ := c.FindResource("output.k8s_api")
+	err = c.ResolveReferences(o)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://127.0.0.1:6443", o.(*Output).Value)
+}
+
+func TestResolveReferencesDoesNotChangeProcessedValues(t *testing.T) {
+	c := parseReferencesConfig(t, referencesConfig)
+
+	r, _ := c.FindResource("container.db")
+	r.(*Container).AssignedPorts = map[string]string{"5432": "32768"}
+
+	r, _ = c.FindResource("container.web")
+	web := r.(*Container)
+	src := web.Volumes[0].Source
+
+	err := c.ResolveReferences(web)
+	assert.NoError(t, err)
+
+	assert.Equal(t, src, web.Volumes[0].Source)
+}
+
+func TestResolveReferencesWithMissingAttributeReturnsError(t *testing.T) {
+	c := parseReferencesConfig(t, invalidReferenceConfig)
+
+	r, _ := c.FindResource("container.web")
+
+	err := c.ResolveReferences(r)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "container.web")
+}
+
+func TestResourceReferencesWithInvalidExpressionReturnsError(t *testing.T) {
+	dir, cleanup := createTestFiles(t, invalidExpressionReferenceConfig)
+	defer cleanup()
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown variable")
+}
+
+func TestReferencesToResourcesEvaluateToAddress(t *testing.T) {
+	c := parseReferencesConfig(t, addressReferencesConfig)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+
+	web := r.(*Container)
+	assert.Equal(t, "network.cloud", web.Networks[0].Name)
+	assert.Equal(t, []string{"container.db"}, web.Depends)
+
+	r, err = c.FindResource("helm.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, "k8s_cluster.k3s", r.(*Helm).Cluster)
+}
+
+func TestReferencesToResourcesAreAddedAsDependencies(t *testing.T) {
+	c := parseReferencesConfig(t, addressReferencesConfig)
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"network.cloud", "container.db"}, r.Info().DependsOn)
+
+	// references from fields which do not create dependencies
+	r, err = c.FindResource("exec_local.setup")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"container.db"}, r.Info().DependsOn)
+}
+
+func TestParseReferencesWithReferencesReturnsNoError(t *testing.T) {
+	c := parseReferencesConfig(t, addressReferencesConfig)
+
+	err := ParseReferences(c)
+	assert.NoError(t, err)
+}
+
+func TestParseReferencesWithMissingResourceReturnsErrorWithRange(t *testing.T) {
+	dir, cleanup := createTestFiles(t)
+	defer cleanup()
+
+	f := createNamedFile(t, dir, "*.hcl", missingReferencesConfig)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.NoError(t, err)
+
+	err = ParseReferences(c)
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:8,12-27", f))
+	assert.Contains(t, err.Error(), "Reference to undeclared resource")
+	assert.Contains(t, err.Error(), "network.missing")
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:12,15-52", f))
+	assert.Contains(t, err.Error(), "container.missing")
+}
+
+var addressReferencesConfig = `
+network "cloud" {
+  subnet = "10.0.0.0/16"
+}
+
+k8s_cluster "k3s" {
+  driver = "k3s"
+}
+
+helm "consul" {
+  cluster = k8s_cluster.k3s
+  chart   = "./chart"
+}
+
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  network {
+    name = network.cloud
+  }
+
+  depends_on = [container.db]
+}
+
+exec_local "setup" {
+  cmd = "./setup.sh"
+  env_var = {
+    DB = container.db
+  }
+}
+`
+
+var stringReferencesConfig = `
+network "cloud" {
+  subnet = "10.0.0.0/16"
+}
+
+k8s_cluster "k3s" {
+  driver = "k3s"
+}
+
+helm "consul" {
+  cluster = "k8s_cluster.k3s"
+  chart   = "./chart"
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  network {
+    name = "network.cloud"
+  }
+}
+`
+
+var missingReferencesConfig = `
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  network {
+    name = network.missing
+  }
+
+  env_var = {
+    DB_ADDR = resource.container.missing.ip_address
+  }
+}
+`
+
+var referencesConfig = `
+k8s_cluster "k3s" {
+  driver = "k3s"
+}
+
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env {
+    key   = "STATIC"
+    value = "static"
+  }
+
+  volume {
+    source      = "./files"
+    destination = "/files"
+  }
+
+  env_var = {
+    DB_ADDR      = resource.container.db.ip_address
+    DB_HOST_ADDR = "${resource.container.db.ip_address}:${resource.container.db.assigned_ports["5432"]}"
+  }
+}
+
+output "k8s_api" {
+  value = resource.k8s_cluster.k3s.api_address
+}
+`
+
+var requiredReferencesConfig = `
+network "cloud" {
+  subnet = "10.5.0.0/16"
+}
+
+network "copy" {
+  subnet = resource.network.cloud.subnet
+}
+
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+container "web" {
+  image {
+    name = resource.container.db.image.name
+  }
+}
+`
+
+var invalidReferenceConfig = `
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env_var = {
+    DB_ADDR = resource.container.db.not_an_attribute
+  }
+}
+`
+
+var invalidExpressionReferenceConfig = `
+container "web" {
+  image {
+    name = var.not_a_var
+  }
+
+  env_var = {
+    DB_ADDR = resource.container.db.ip_address
+  }
+}
+`
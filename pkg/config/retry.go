@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// Retry is an internal block which can be added to any resource, it defines
+// the policy for retrying a resource which fails to be created due to
+// transient errors such as image pull failures
+// example config:
+//    retry {
+//      attempts = 3    // the maximum number of times to attempt to create the resource
+//      backoff  = "5s" // time to wait before the first retry, doubled for each subsequent retry
+//    }
+type Retry struct {
+	Attempts int    `hcl:"attempts,optional" json:"attempts,omitempty"`
+	Backoff  string `hcl:"backoff,optional" json:"backoff,omitempty"`
+}
+
+// validate checks the retry policy decoded from the resource block b
+func (r *Retry) validate(b *hclsyntax.Block) error {
+	if r == nil {
+		return nil
+	}
+
+	return validateDuration(b, "retry", "backoff", r.Backoff)
+}
+
+// BackoffDuration returns the time to wait before the given retry,
+// retry 1 is the first retry after the initial attempt has failed
+func (r *Retry) BackoffDuration(retry int) (time.Duration, error) {
+	if r.Backoff == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(r.Backoff)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid retry backoff %s: %s", r.Backoff, err)
+	}
+
+	for i := 1; i < retry; i++ {
+		d = d * 2
+	}
+
+	return d, nil
+}
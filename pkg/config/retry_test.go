@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryParsesForResources(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, retryConfig)
+	defer cleanup()
+
+	n, err := c.FindResource("network.test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, n.Info().Retry.Attempts)
+	assert.Equal(t, "5s", n.Info().Retry.Backoff)
+
+	co, err := c.FindResource("container.test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, co.Info().Retry.Attempts)
+}
+
+func TestRetryIsNilWhenNotSet(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, networkDefault)
+	defer cleanup()
+
+	n, err := c.FindResource("network.test")
+	assert.NoError(t, err)
+
+	assert.Nil(t, n.Info().Retry)
+}
+
+func TestRetrySerializesToState(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, retryConfig)
+	defer cleanup()
+
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+	defer os.Setenv(utils.HomeEnvName(), home)
+
+	err := c.ToJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	sc := New()
+	err = sc.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	n, err := sc.FindResource("network.test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, n.Info().Retry.Attempts)
+	assert.Equal(t, "5s", n.Info().Retry.Backoff)
+}
+
+func TestRetryBackoffDoublesForEachRetry(t *testing.T) {
+	r := &Retry{Attempts: 3, Backoff: "5s"}
+
+	d, err := r.BackoffDuration(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, d)
+
+	d, err = r.BackoffDuration(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 20*time.Second, d)
+}
+
+func TestRetryBackoffReturnsErrorWhenInvalid(t *testing.T) {
+	r := &Retry{Attempts: 3, Backoff: "abc"}
+
+	_, err := r.BackoffDuration(1)
+	assert.Error(t, err)
+}
+
+func TestRetryWithInvalidBackoffReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, retryInvalidConfig)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ":5,15-20: Invalid duration")
+	assert.Contains(t, err.Error(), "backoff")
+}
+
+const retryInvalidConfig = `
+network "test" {
+  subnet = "10.0.0.0/24"
+  retry {
+    backoff = "abc"
+  }
+}
+`
+
+const retryConfig = `
+network "test" {
+	subnet = "10.0.0.0/24"
+
+	retry {
+		attempts = 3
+		backoff  = "5s"
+	}
+}
+
+container "test" {
+	image {
+		name = "consul"
+	}
+
+	retry {
+		attempts = 2
+	}
+}
+`
@@ -3,92 +3,121 @@ package config
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
 
 	"github.com/mitchellh/mapstructure"
-	"github.com/shipyard-run/shipyard/pkg/utils"
 )
 
 var StateNotFoundError = fmt.Errorf("State file not found")
 
+// StateVersion is the version of the state schema written by this release,
+// increment the version and add a migration to stateMigrations when a change
+// to a resource struct prevents older state files from being loaded
+const StateVersion = 1
+
+// StateBackups is the number of previous state files which are kept when the
+// state is saved, backups are named state.json.1, state.json.2, with .1 the newest
+const StateBackups = 5
+
+// stateMigration upgrades the decoded state by one version, migrations operate
+// on the raw JSON objects before they are converted into resources
+type stateMigration func(state map[string]interface{}) error
+
+// stateMigrations contains the migration from the version at the index to the
+// next version, i.e. stateMigrations[0] upgrades a version 0 state to version 1
+var stateMigrations = []stateMigration{
+	// version 0 state files were written before the version field was
+	// added, the schema is otherwise unchanged
+	func(state map[string]interface{}) error { return nil },
+}
+
 // ToJSON saves the config in JSON format to the specified path
 // returns an error if the config can not be saved.
+// The config is written to a temporary file which replaces the existing
+// state so that the state is never partially written, the previous state
+// is kept as a backup.
 func (c *Config) ToJSON(path string) error {
-	sd := utils.StateDir()
-	sp := utils.StatePath()
+	return c.Save(NewLocalStateBackend(path))
+}
+
+// FromJSON attempts to rehydrate the config from a JSON formatted statefile
+func (c *Config) FromJSON(path string) error {
+	return c.Load(NewLocalStateBackend(path))
+}
+
+// Save writes the config in JSON format to the given state backend
+func (c *Config) Save(b StateBackend) error {
+	// serialize the state to json, the version is always the current version
+	sc := *c
+	sc.Version = StateVersion
 
-	// if it does not exist create the state folder
-	_, err := os.Stat(sd)
+	d, err := json.Marshal(&sc)
 	if err != nil {
-		os.MkdirAll(sd, os.ModePerm)
+		return fmt.Errorf("Unable to serialize state: %s", err)
 	}
 
-	// if the statefile exists overwrite it
-	_, err = os.Stat(sp)
-	if err == nil {
-		// delete the old state
-		os.Remove(sp)
-	}
+	return b.Write(d)
+}
 
-	// serialize the state to json and write to a file
-	f, err := os.Create(sp)
+// Load rehydrates the config from the state stored in the given backend,
+// StateNotFoundError is returned when the backend does not contain any state
+func (c *Config) Load(b StateBackend) error {
+	d, err := b.Read()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	ne := json.NewEncoder(f)
-	return ne.Encode(c)
-}
 
-// FromJSON attempts to rehydrate the config from a JSON formatted statefile
-func (c *Config) FromJSON(path string) error {
 	// it is fine that the state might not exist
-	f, err := os.Open(path)
-	if err != nil {
+	if d == nil {
 		return StateNotFoundError
 	}
-	defer f.Close()
 
-	jd := json.NewDecoder(f)
-	return jd.Decode(c)
+	return json.Unmarshal(d, c)
 }
 
 // UnmarshalJSON is a cusom Unmarshaler to deal with
-// converting the objects back into their main type
+// converting the objects back into their main type,
+// state files written by older versions are migrated
+// to the current version before they are converted
 func (c *Config) UnmarshalJSON(b []byte) error {
-	var objMap map[string]*json.RawMessage
-	err := json.Unmarshal(b, &objMap)
+	state := map[string]interface{}{}
+	err := json.Unmarshal(b, &state)
+	if err != nil {
+		return err
+	}
+
+	err = migrateState(state)
 	if err != nil {
 		return err
 	}
 
-	if objMap["blueprint"] != nil {
-		var rawBlueprint *json.RawMessage
-		json.Unmarshal(*objMap["blueprint"], &rawBlueprint)
+	c.Version = StateVersion
+
+	if state["blueprint"] != nil {
+		d, err := json.Marshal(state["blueprint"])
+		if err != nil {
+			return err
+		}
+
 		bp := &Blueprint{}
-		err = json.Unmarshal(*rawBlueprint, &bp)
+		err = json.Unmarshal(d, &bp)
 		if err == nil {
 			c.Blueprint = bp
 		}
 	}
 
-	var rawMessagesForResources []*json.RawMessage
-	err = json.Unmarshal(*objMap["resources"], &rawMessagesForResources)
-	if err != nil {
-		return err
-	}
+	resources, _ := state["resources"].([]interface{})
 
-	for _, m := range rawMessagesForResources {
-		mm := map[string]interface{}{}
-		err := json.Unmarshal(*m, &mm)
-		if err != nil {
-			return err
+	for _, m := range resources {
+		mm, ok := m.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("Unable to read resource from state, expected an object got %v", m)
 		}
 
+		t, _ := mm["type"].(string)
+
 		var out interface{}
-		switch rt := ResourceType(mm["type"].(string)); rt {
+		switch rt := ResourceType(t); rt {
 		case TypeContainerIngress:
 			out = &ContainerIngress{}
 		case TypeContainer:
@@ -130,7 +159,11 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 		case TypeVariable:
 			out = &Variable{}
 		default:
-			return fmt.Errorf("Unable to convert to type %s, please define types in UnmarshalJSON function", rt)
+			if !IsPluginType(rt) {
+				return fmt.Errorf("Unable to convert to type %s, please define types in UnmarshalJSON function or install the plugin which provides the type", rt)
+			}
+
+			out = &PluginResource{}
 		}
 
 		err = c.decodeAndAdd(mm, out)
@@ -143,6 +176,30 @@ func (c *Config) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// migrateState upgrades the decoded state to the current version
+func migrateState(state map[string]interface{}) error {
+	// state files without a version were written before versioning was added
+	version := 0
+	if v, ok := state["version"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > StateVersion {
+		return fmt.Errorf("State version %d is newer than the version supported by this release %d, please upgrade Shipyard", version, StateVersion)
+	}
+
+	for v := version; v < StateVersion; v++ {
+		err := stateMigrations[v](state)
+		if err != nil {
+			return fmt.Errorf("Unable to migrate state from version %d to %d: %s", v, v+1, err)
+		}
+	}
+
+	state["version"] = float64(StateVersion)
+
+	return nil
+}
+
 func (c *Config) decodeAndAdd(in map[string]interface{}, out interface{}) error {
 	dec, err := mapstructure.NewDecoder(
 		&mapstructure.DecoderConfig{
@@ -165,6 +222,10 @@ func (c *Config) decodeAndAdd(in map[string]interface{}, out interface{}) error
 // Merge config merges two config items
 func (c *Config) Merge(c2 *Config) {
 	for _, cc2 := range c2.Resources {
+		// generate the hash before any state is merged
+		// errors are ignored as the resource will be treated as unchanged
+		hash, _ := HashResource(cc2)
+
 		found := false
 		for i, cc := range c.Resources {
 			if cc2.Info().Name == cc.Info().Name && cc2.Info().Type == cc.Info().Type {
@@ -174,7 +235,11 @@ func (c *Config) Merge(c2 *Config) {
 				status := c.Resources[i].Info().Status
 				// do not update the status for resources we need to re-create or have not yet been created
 				if status == Applied {
-					status = PendingUpdate
+					// old state files do not contain the hash, treat these
+					// resources as unchanged
+					if cc.Info().Hash != "" && cc.Info().Hash != hash {
+						status = PendingUpdate
+					}
 
 					if cc2.Info().Type == TypeImageCache {
 						// always set Image Cache to Pending Creation to
@@ -183,8 +248,17 @@ func (c *Config) Merge(c2 *Config) {
 					}
 				}
 
+				// resources which were disabled when they were first applied
+				// have never been created, disabled is not part of the hash so
+				// enabling the resource is not detected as a change and it must
+				// be set to be created
+				if status == Disabled && !cc2.Info().Disabled {
+					status = PendingCreation
+				}
+
 				c.Resources[i] = cc2
 				c.Resources[i].Info().Status = status
+				c.Resources[i].Info().Hash = hash
 
 				// make sure the reference is the world view not the local view
 				c.Resources[i].Info().Config = c
@@ -234,6 +308,7 @@ func (c *Config) Merge(c2 *Config) {
 		}
 
 		if !found {
+			cc2.Info().Hash = hash
 			c.AddResource(cc2)
 		}
 	}
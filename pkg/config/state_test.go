@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/shipyard-run/shipyard/pkg/utils"
@@ -87,6 +88,103 @@ func TestConfigDeSerializesFromJSON(t *testing.T) {
 	assert.NotNil(t, r)
 }
 
+func TestConfigSerializesToGivenPath(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	statePath := filepath.Join(t.TempDir(), "other", "state.json")
+	err := c.ToJSON(statePath)
+	assert.NoError(t, err)
+
+	assert.FileExists(t, statePath)
+	assert.NoFileExists(t, utils.StatePath())
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(filepath.Dir(statePath))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestConfigSerializesVersion(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	err := c.ToJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	d, err := ioutil.ReadFile(utils.StatePath())
+	assert.NoError(t, err)
+
+	s := map[string]interface{}{}
+	err = json.Unmarshal(d, &s)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(StateVersion), s["version"])
+}
+
+func TestConfigKeepsBackups(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	statePath := utils.StatePath()
+	for i := 0; i < StateBackups+2; i++ {
+		c.Resources[0].Info().Name = fmt.Sprintf("save%d", i)
+
+		err := c.ToJSON(statePath)
+		assert.NoError(t, err)
+	}
+
+	// .1 is the state before the last save
+	b := New()
+	err := b.FromJSON(statePath + ".1")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("save%d", StateBackups), b.Resources[0].Info().Name)
+
+	assert.FileExists(t, fmt.Sprintf("%s.%d", statePath, StateBackups))
+	assert.NoFileExists(t, fmt.Sprintf("%s.%d", statePath, StateBackups+1))
+}
+
+func TestConfigDeSerializesStateWithoutVersion(t *testing.T) {
+	c := New()
+	err := json.Unmarshal([]byte(`{"blueprint": null, "resources": [{"name": "dc1", "type": "network", "status": "applied", "subnet": "10.0.0.0/16"}]}`), c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, StateVersion, c.Version)
+	assert.Len(t, c.Resources, 1)
+}
+
+func TestConfigDeSerializesNewerVersionReturnsError(t *testing.T) {
+	c := New()
+	err := json.Unmarshal([]byte(fmt.Sprintf(`{"version": %d, "blueprint": null, "resources": []}`, StateVersion+1)), c)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "please upgrade Shipyard")
+}
+
+func TestConfigDeSerializesRunsMigrations(t *testing.T) {
+	migrations := stateMigrations
+	defer func() { stateMigrations = migrations }()
+
+	stateMigrations = []stateMigration{
+		func(state map[string]interface{}) error {
+			// rename a field which has changed in the resource struct
+			for _, r := range state["resources"].([]interface{}) {
+				rm := r.(map[string]interface{})
+				rm["subnet"] = rm["cidr"]
+				delete(rm, "cidr")
+			}
+
+			return nil
+		},
+	}
+
+	c := New()
+	err := json.Unmarshal([]byte(`{"blueprint": null, "resources": [{"name": "dc1", "type": "network", "status": "applied", "cidr": "10.0.0.0/16"}]}`), c)
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/16", r.(*Network).Subnet)
+}
+
 func TestConfigMergesAddingItems(t *testing.T) {
 	c, cleanup := setupConfigTests(t)
 	defer cleanup()
@@ -99,14 +197,17 @@ func TestConfigMergesAddingItems(t *testing.T) {
 	assert.Len(t, c.Resources, 11)
 }
 
-func TestConfigMergesWithExistingItemSetsPendingUpdateWhenApplied(t *testing.T) {
+func TestConfigMergesWithExistingItemSetsPendingUpdateWhenAppliedAndChanged(t *testing.T) {
 	c, cleanup := setupConfigTests(t)
 	defer cleanup()
 
 	c.Resources[1].Info().Status = Applied
+	c.Resources[1].Info().Hash, _ = HashResource(c.Resources[1])
 
 	c2 := New()
-	c2.AddResource(NewContainer("config"))
+	con := NewContainer("config")
+	con.Command = []string{"consul", "agent"}
+	c2.AddResource(con)
 
 	cacheNew := NewImageCache("docker-cache")
 	c2.AddResource(cacheNew)
@@ -115,6 +216,75 @@ func TestConfigMergesWithExistingItemSetsPendingUpdateWhenApplied(t *testing.T)
 
 	assert.Len(t, c.Resources, 10)
 	assert.Equal(t, c.Resources[1].Info().Status, PendingUpdate)
+
+	h, _ := HashResource(con)
+	assert.Equal(t, h, c.Resources[1].Info().Hash)
+}
+
+func TestConfigMergesWithExistingItemRetainsAppliedWhenUnchanged(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	c.Resources[1].Info().Status = Applied
+	c.Resources[1].Info().Module = ""
+	c.Resources[1].Info().Hash, _ = HashResource(c.Resources[1])
+
+	c2 := New()
+	c2.AddResource(NewContainer("config"))
+
+	c.Merge(c2)
+
+	assert.Len(t, c.Resources, 10)
+	assert.Equal(t, c.Resources[1].Info().Status, Applied)
+}
+
+func TestConfigMergesWithExistingItemRetainsAppliedWhenNoHash(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	c.Resources[1].Info().Status = Applied
+
+	c2 := New()
+	con := NewContainer("config")
+	con.Command = []string{"consul", "agent"}
+	c2.AddResource(con)
+
+	c.Merge(c2)
+
+	assert.Len(t, c.Resources, 10)
+	assert.Equal(t, c.Resources[1].Info().Status, Applied)
+}
+
+func TestConfigMergesWithExistingItemSetsPendingCreationWhenEnabled(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	c.Resources[1].Info().Status = Disabled
+	c.Resources[1].Info().Disabled = true
+
+	c2 := New()
+	c2.AddResource(NewContainer("config"))
+
+	c.Merge(c2)
+
+	assert.Equal(t, c.Resources[1].Info().Status, PendingCreation)
+}
+
+func TestConfigMergesWithExistingItemRetainsDisabledWhenStillDisabled(t *testing.T) {
+	c, cleanup := setupConfigTests(t)
+	defer cleanup()
+
+	c.Resources[1].Info().Status = Disabled
+	c.Resources[1].Info().Disabled = true
+
+	c2 := New()
+	con := NewContainer("config")
+	con.Disabled = true
+	c2.AddResource(con)
+
+	c.Merge(c2)
+
+	assert.Equal(t, c.Resources[1].Info().Status, Disabled)
 }
 
 func TestConfigMergesWithExistingItemSetsItemCacheToPendingCreationWhenApplied(t *testing.T) {
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// Timeouts is an internal block which can be added to any resource, it defines
+// the maximum time the engine waits for the resource to be created or destroyed
+// example config:
+//    timeouts {
+//      create  = "10m" // maximum time to create, update, or recreate the resource
+//      destroy = "2m"  // maximum time to destroy the resource
+//    }
+type Timeouts struct {
+	Create  string `hcl:"create,optional" json:"create,omitempty"`
+	Destroy string `hcl:"destroy,optional" json:"destroy,omitempty"`
+}
+
+// validate checks the timeouts decoded from the resource block b
+func (t *Timeouts) validate(b *hclsyntax.Block) error {
+	if t == nil {
+		return nil
+	}
+
+	err := validateDuration(b, "timeouts", "create", t.Create)
+	if err != nil {
+		return err
+	}
+
+	return validateDuration(b, "timeouts", "destroy", t.Destroy)
+}
+
+// CreateDuration returns the create timeout, 0 is returned
+// when no timeout has been set
+func (t *Timeouts) CreateDuration() (time.Duration, error) {
+	if t == nil {
+		return 0, nil
+	}
+
+	return parseTimeout("create", t.Create)
+}
+
+// DestroyDuration returns the destroy timeout, 0 is returned
+// when no timeout has been set
+func (t *Timeouts) DestroyDuration() (time.Duration, error) {
+	if t == nil {
+		return 0, nil
+	}
+
+	return parseTimeout("destroy", t.Destroy)
+}
+
+func parseTimeout(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s timeout %s: %s", name, value, err)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutsParsesForResources(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, timeoutsConfig)
+	defer cleanup()
+
+	n, err := c.FindResource("network.test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10m", n.Info().Timeouts.Create)
+	assert.Equal(t, "2m", n.Info().Timeouts.Destroy)
+
+	co, err := c.FindResource("container.test")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "30s", co.Info().Timeouts.Create)
+	assert.Equal(t, "", co.Info().Timeouts.Destroy)
+}
+
+func TestTimeoutsDoNotChangeHash(t *testing.T) {
+	c, _, cleanup := setupTestConfig(t, timeoutsConfig)
+	defer cleanup()
+
+	n, err := c.FindResource("network.test")
+	assert.NoError(t, err)
+
+	h1, err := HashResource(n)
+	assert.NoError(t, err)
+
+	n.Info().Timeouts = nil
+
+	h2, err := HashResource(n)
+	assert.NoError(t, err)
+
+	assert.Equal(t, h1, h2)
+}
+
+func TestTimeoutsReturnsDurations(t *testing.T) {
+	to := &Timeouts{Create: "10m", Destroy: "2m"}
+
+	d, err := to.CreateDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, d)
+
+	d, err = to.DestroyDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, d)
+}
+
+func TestTimeoutsReturnsZeroWhenNotSet(t *testing.T) {
+	var to *Timeouts
+
+	d, err := to.CreateDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	to = &Timeouts{Create: "10m"}
+
+	d, err = to.DestroyDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d)
+}
+
+func TestTimeoutsReturnsErrorWhenInvalid(t *testing.T) {
+	to := &Timeouts{Create: "abc"}
+
+	_, err := to.CreateDuration()
+	assert.Error(t, err)
+}
+
+func TestTimeoutsWithInvalidDurationReturnsError(t *testing.T) {
+	_, err := parseInstancesConfig(t, timeoutsInvalidConfig)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ":6,15-20: Invalid duration")
+	assert.Contains(t, err.Error(), "destroy")
+}
+
+const timeoutsInvalidConfig = `
+network "test" {
+  subnet = "10.0.0.0/24"
+  timeouts {
+    create  = "10m"
+    destroy = "-2m"
+  }
+}
+`
+
+const timeoutsConfig = `
+network "test" {
+	subnet = "10.0.0.0/24"
+
+	timeouts {
+		create  = "10m"
+		destroy = "2m"
+	}
+}
+
+container "test" {
+	image {
+		name = "consul"
+	}
+
+	timeouts {
+		create = "30s"
+	}
+}
+`
@@ -1,5 +1,7 @@
 package config
 
+import "github.com/hashicorp/hcl2/hcl"
+
 const TypeVariable ResourceType = "variable"
 
 // Output defines an output variable which can be set by a module
@@ -7,6 +9,21 @@ type Variable struct {
 	ResourceInfo `mapstructure:",squash"`
 	Default      interface{} `hcl:"default" json:"default"`                            // default value for a variable
 	Description  string      `hcl:"description,optional" json:"description,omitempty"` // description of the variable
+
+	// TypeConstraint is the type of the variable i.e. number, bool, string,
+	// list(string), or map(string), values are converted to the type when
+	// the config is parsed
+	TypeConstraint *hcl.Attribute `hcl:"type,optional" json:"-"`
+
+	// Validation rules which the value of the variable must satisfy
+	Validation []VariableValidation `hcl:"validation,block" json:"-"`
+}
+
+// VariableValidation defines a rule for the value of a variable,
+// when condition is false parsing fails with the error message
+type VariableValidation struct {
+	Condition    hcl.Expression `hcl:"condition"`
+	ErrorMessage string         `hcl:"error_message"`
 }
 
 // NewOutput creates a new output variable
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func parseVariablesConfig(t *testing.T, vars map[string]string, valuesFile string) (*Config, *moduleContext, string, error) {
+	dir, cleanup := createTestFiles(t)
+	t.Cleanup(cleanup)
+
+	f := createNamedFile(t, dir, "*.hcl", typedVariables)
+
+	if valuesFile != "" {
+		createNamedFile(t, dir, "*.vars", valuesFile)
+	}
+
+	c := New()
+	mc := newModuleContext()
+	err := parseFolder(mc, dir, c, false, "", false, []string{}, vars, "")
+
+	return c, mc, f, err
+}
+
+func TestVariableDefaultsAreConvertedToType(t *testing.T) {
+	_, mc, _, err := parseVariablesConfig(t, nil, "")
+	assert.NoError(t, err)
+
+	vars := mc.ctx.Variables["var"].AsValueMap()
+	assert.Equal(t, cty.Number, vars["replicas"].Type())
+	assert.Equal(t, cty.List(cty.String), vars["regions"].Type())
+	assert.Equal(t, cty.Map(cty.String), vars["labels"].Type())
+	assert.Equal(t, cty.Bool, vars["enabled"].Type())
+}
+
+func TestVariableFromFlagIsConvertedToType(t *testing.T) {
+	c, mc, _, err := parseVariablesConfig(t, map[string]string{"replicas": "3", "regions": `["eu", "us"]`}, "")
+	assert.NoError(t, err)
+
+	vars := mc.ctx.Variables["var"].AsValueMap()
+	assert.True(t, vars["replicas"].Equals(cty.NumberIntVal(3)).True())
+	assert.Equal(t, cty.ListVal([]cty.Value{cty.StringVal("eu"), cty.StringVal("us")}), vars["regions"])
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", r.(*Container).Environment[0].Value)
+}
+
+func TestVariableFromFlagWithInvalidTypeReturnsError(t *testing.T) {
+	_, _, f, err := parseVariablesConfig(t, map[string]string{"replicas": "abc"}, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), "Invalid value for variable replicas")
+	assert.Contains(t, err.Error(), "not a valid number")
+	assert.Contains(t, err.Error(), "--var")
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:3,", f))
+}
+
+func TestVariableFromFlagFailingValidationReturnsError(t *testing.T) {
+	_, _, f, err := parseVariablesConfig(t, map[string]string{"replicas": "9"}, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), "Replicas must be between 1 and 4")
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:7,", f))
+}
+
+func TestVariableFromEnvFailingValidationReturnsError(t *testing.T) {
+	os.Setenv("SY_VAR_replicas", "0")
+	t.Cleanup(func() {
+		os.Unsetenv("SY_VAR_replicas")
+	})
+
+	_, _, _, err := parseVariablesConfig(t, nil, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), "Replicas must be between 1 and 4")
+	assert.Contains(t, err.Error(), "SY_VAR_replicas")
+}
+
+func TestVariableFromValuesFileReportsValueLocation(t *testing.T) {
+	dir, cleanup := createTestFiles(t)
+	defer cleanup()
+
+	createNamedFile(t, dir, "*.hcl", typedVariables)
+	vf := createNamedFile(t, dir, "*.vars", "\nreplicas = 12\n")
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:2,", vf))
+	assert.Contains(t, err.Error(), "Replicas must be between 1 and 4")
+	assert.Contains(t, err.Error(), filepath.Base(vf))
+}
+
+func TestVariableWithInvalidDefaultReportsDefaultLocation(t *testing.T) {
+	dir, cleanup := createTestFiles(t)
+	defer cleanup()
+
+	f := createNamedFile(t, dir, "*.hcl", invalidDefaultVariable)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), fmt.Sprintf("%s:4,", f))
+	assert.Contains(t, err.Error(), "not a valid bool")
+}
+
+func TestVariableWithNonBoolConditionReturnsError(t *testing.T) {
+	dir, cleanup := createTestFiles(t)
+	defer cleanup()
+
+	createNamedFile(t, dir, "*.hcl", invalidConditionVariable)
+
+	c := New()
+	err := ParseFolder(dir, c, false, "", false, []string{}, nil, "")
+	assert.Error(t, err)
+
+	assert.Contains(t, err.Error(), "must return a bool")
+}
+
+func TestVariableWithoutTypeIsNotConverted(t *testing.T) {
+	_, mc, _, err := parseVariablesConfig(t, map[string]string{"name": "3"}, "")
+	assert.NoError(t, err)
+
+	vars := mc.ctx.Variables["var"].AsValueMap()
+	assert.Equal(t, cty.StringVal("3"), vars["name"])
+}
+
+func TestParseFolderWithDifferentVariablesConcurrently(t *testing.T) {
+	dir, cleanup := createTestFiles(t)
+	defer cleanup()
+
+	createNamedFile(t, dir, "*.hcl", typedVariables)
+
+	configs := make([]*Config, 4)
+	errs := make([]error, 4)
+
+	wg := sync.WaitGroup{}
+	for i := range configs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			configs[i] = New()
+			errs[i] = ParseFolder(dir, configs[i], false, "", false, []string{}, map[string]string{"replicas": fmt.Sprintf("%d", i+1)}, "")
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, c := range configs {
+		assert.NoError(t, errs[i])
+
+		r, err := c.FindResource("container.consul")
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d", i+1), r.(*Container).Environment[0].Value)
+	}
+}
+
+var typedVariables = `
+variable "replicas" {
+  type    = number
+  default = 1
+
+  validation {
+    condition     = var.replicas > 0 && var.replicas < 5
+    error_message = "Replicas must be between 1 and 4."
+  }
+}
+
+variable "regions" {
+  type    = list(string)
+  default = ["eu"]
+}
+
+variable "labels" {
+  type    = map(string)
+  default = {
+    app = "consul"
+  }
+}
+
+variable "enabled" {
+  type    = bool
+  default = "true"
+}
+
+variable "name" {
+  default = "consul"
+}
+
+container "consul" {
+  image {
+    name = "consul:1.8.1"
+  }
+
+  env {
+    key   = "REPLICAS"
+    value = var.replicas
+  }
+}
+`
+
+var invalidDefaultVariable = `
+variable "enabled" {
+  type    = bool
+  default = "yes"
+}
+`
+
+var invalidConditionVariable = `
+variable "replicas" {
+  default = 1
+
+  validation {
+    condition     = var.replicas
+    error_message = "Replicas must be set."
+  }
+}
+`
@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+)
+
+// Host loads plugins and returns the provider for a resource type
+type Host interface {
+	// Load starts all the plugin binaries in the given folder
+	Load(dir string) error
+	// Register adds the resource types managed by the provider
+	Register(p Provider) error
+	// Schemas returns the schema for all resource types provided by plugins
+	Schemas() []ResourceSchema
+	// Provider returns the plugin provider for the given resource type
+	Provider(t string) (Provider, bool)
+	// Close stops all running plugins
+	Close()
+}
+
+// HostImpl is the concrete implementation of Host which uses go-plugin
+// to start plugin binaries
+type HostImpl struct {
+	log       hclog.Logger
+	mutex     sync.Mutex
+	clients   []*plugin.Client
+	schemas   []ResourceSchema
+	providers map[string]Provider
+}
+
+// NewHost creates a new plugin host
+func NewHost(l hclog.Logger) *HostImpl {
+	return &HostImpl{log: l, providers: map[string]Provider{}}
+}
+
+// Load starts all the plugin binaries in the given folder, plugins must be
+// named shipyard-plugin-<name>. A folder which does not exist is ignored,
+// plugins which fail to start are logged and skipped.
+func (h *HostImpl) Load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "shipyard-plugin-*"))
+	if err != nil {
+		return fmt.Errorf("Unable to list plugins in %s: %s", dir, err)
+	}
+
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+
+		h.log.Debug("Loading plugin", "path", f)
+
+		err = h.start(f)
+		if err != nil {
+			h.log.Error("Unable to load plugin", "path", f, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (h *HostImpl) start(path string) error {
+	c := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig: Handshake,
+		Plugins: plugin.PluginSet{
+			PluginName: &ProviderPlugin{},
+		},
+		Cmd:              exec.Command(path),
+		Logger:           h.log.Named("plugin"),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolNetRPC},
+	})
+
+	err := h.register(c)
+	if err != nil {
+		// stop the plugin so that it is not left running
+		c.Kill()
+		return err
+	}
+
+	h.mutex.Lock()
+	h.clients = append(h.clients, c)
+	h.mutex.Unlock()
+
+	return nil
+}
+
+// register dispenses the provider from the plugin client and registers it
+func (h *HostImpl) register(c *plugin.Client) error {
+	rc, err := c.Client()
+	if err != nil {
+		return err
+	}
+
+	raw, err := rc.Dispense(PluginName)
+	if err != nil {
+		return err
+	}
+
+	p, ok := raw.(Provider)
+	if !ok {
+		return fmt.Errorf("plugin does not implement a provider")
+	}
+
+	return h.Register(p)
+}
+
+// Register adds the resource types managed by the provider, an error is
+// returned if a type has already been registered by another plugin
+func (h *HostImpl) Register(p Provider) error {
+	schemas, err := p.Schema()
+	if err != nil {
+		return fmt.Errorf("Unable to read schema: %s", err)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, s := range schemas {
+		if _, ok := h.providers[s.Type]; ok {
+			return fmt.Errorf("Resource type %s has already been registered by another plugin", s.Type)
+		}
+	}
+
+	for _, s := range schemas {
+		h.providers[s.Type] = p
+		h.schemas = append(h.schemas, s)
+	}
+
+	return nil
+}
+
+// Schemas returns the schema for all resource types provided by plugins
+func (h *HostImpl) Schemas() []ResourceSchema {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return append([]ResourceSchema{}, h.schemas...)
+}
+
+// Provider returns the plugin provider for the given resource type
+func (h *HostImpl) Provider(t string) (Provider, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	p, ok := h.providers[t]
+	return p, ok
+}
+
+// Close stops all running plugins
+func (h *HostImpl) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, c := range h.clients {
+		c.Kill()
+	}
+
+	h.clients = nil
+}
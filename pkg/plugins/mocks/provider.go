@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"github.com/shipyard-run/shipyard/pkg/plugins"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProvider is a mock implementation of plugins.Provider
+type MockProvider struct {
+	mock.Mock
+}
+
+func (m *MockProvider) Schema() ([]plugins.ResourceSchema, error) {
+	args := m.Called()
+
+	if s, ok := args.Get(0).([]plugins.ResourceSchema); ok {
+		return s, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockProvider) Create(r plugins.Resource) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockProvider) Destroy(r plugins.Resource) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockProvider) Lookup(r plugins.Resource) ([]string, error) {
+	args := m.Called(r)
+
+	if ids, ok := args.Get(0).([]string); ok {
+		return ids, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
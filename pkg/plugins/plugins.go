@@ -0,0 +1,80 @@
+// Package plugins allows resource types to be provided by external binaries.
+//
+// A plugin is a binary which calls Serve with an implementation of Provider,
+// Shipyard starts the binary and communicates with it using go-plugin over RPC.
+// Plugins are loaded from the plugins folder in the Shipyard home directory and
+// must be named shipyard-plugin-<name>.
+package plugins
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/go-plugin"
+)
+
+// Handshake is used by Shipyard and the plugin to ensure that the plugin
+// is a Shipyard plugin and that the protocol versions are compatible
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "SHIPYARD_PLUGIN",
+	MagicCookieValue: "a2d4e6b1-5c3f-4e0a-9b7d-shipyard",
+}
+
+// PluginName is the name used to dispense the provider from the plugin
+const PluginName = "provider"
+
+// Provider is implemented by plugins to manage one or more resource types
+type Provider interface {
+	// Schema returns the resource types which are managed by the plugin
+	Schema() ([]ResourceSchema, error)
+	// Create the given resource
+	Create(r Resource) error
+	// Destroy the given resource
+	Destroy(r Resource) error
+	// Lookup returns the ids of any objects which exist for the resource
+	Lookup(r Resource) ([]string, error)
+}
+
+// ResourceSchema defines the HCL schema for a resource type
+type ResourceSchema struct {
+	// Type of the resource, this is the name of the HCL block i.e. mock_saas
+	Type string
+	// Attributes which can be set in the HCL block for the resource
+	Attributes []AttributeSchema
+}
+
+// AttributeSchema defines an attribute for a resource type
+type AttributeSchema struct {
+	// Name of the attribute
+	Name string
+	// Type is an HCL type constraint i.e. string, number, bool, list(string), map(string)
+	Type string
+	// Required attributes must be set in the config
+	Required bool
+}
+
+// Resource is sent to the plugin for each operation
+type Resource struct {
+	Type   string
+	Name   string
+	Module string
+	// Attributes contains the values for the attributes
+	// defined in the schema encoded as JSON
+	Attributes []byte
+}
+
+// Decode the attributes for the resource into the given value
+func (r Resource) Decode(v interface{}) error {
+	return json.Unmarshal(r.Attributes, v)
+}
+
+// Serve is called by the plugin binary to serve the given provider,
+// this function blocks until Shipyard closes the plugin
+func Serve(p Provider) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: plugin.PluginSet{
+			PluginName: &ProviderPlugin{Impl: p},
+		},
+	})
+}
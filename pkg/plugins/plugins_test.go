@@ -0,0 +1,139 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	assert "github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	created []Resource
+	err     error
+}
+
+func (p *testProvider) Schema() ([]ResourceSchema, error) {
+	return []ResourceSchema{
+		ResourceSchema{
+			Type:       "mock_saas",
+			Attributes: []AttributeSchema{AttributeSchema{Name: "endpoint", Type: "string", Required: true}},
+		},
+	}, p.err
+}
+
+func (p *testProvider) Create(r Resource) error {
+	p.created = append(p.created, r)
+	return p.err
+}
+
+func (p *testProvider) Destroy(r Resource) error {
+	return p.err
+}
+
+func (p *testProvider) Lookup(r Resource) ([]string, error) {
+	return []string{r.Name}, p.err
+}
+
+func setupRPCTests(t *testing.T, err error) (Provider, *testProvider) {
+	impl := &testProvider{err: err}
+
+	client, _ := plugin.TestPluginRPCConn(t, plugin.PluginSet{PluginName: &ProviderPlugin{Impl: impl}}, nil)
+	t.Cleanup(func() { client.Close() })
+
+	raw, dErr := client.Dispense(PluginName)
+	assert.NoError(t, dErr)
+
+	return raw.(Provider), impl
+}
+
+func TestRPCReturnsSchema(t *testing.T) {
+	p, _ := setupRPCTests(t, nil)
+
+	s, err := p.Schema()
+	assert.NoError(t, err)
+	assert.Len(t, s, 1)
+	assert.Equal(t, "mock_saas", s[0].Type)
+	assert.Equal(t, "endpoint", s[0].Attributes[0].Name)
+	assert.True(t, s[0].Attributes[0].Required)
+}
+
+func TestRPCCreateSendsResource(t *testing.T) {
+	p, impl := setupRPCTests(t, nil)
+
+	err := p.Create(Resource{Type: "mock_saas", Name: "api", Attributes: []byte(`{"endpoint":"http://localhost"}`)})
+	assert.NoError(t, err)
+	assert.Len(t, impl.created, 1)
+
+	attrs := map[string]string{}
+	err = impl.created[0].Decode(&attrs)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost", attrs["endpoint"])
+}
+
+func TestRPCLookupReturnsIDs(t *testing.T) {
+	p, _ := setupRPCTests(t, nil)
+
+	ids, err := p.Lookup(Resource{Type: "mock_saas", Name: "api"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api"}, ids)
+}
+
+func TestRPCReturnsPluginErrors(t *testing.T) {
+	p, _ := setupRPCTests(t, fmt.Errorf("boom"))
+
+	err := p.Destroy(Resource{Type: "mock_saas", Name: "api"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestHostRegistersProviderTypes(t *testing.T) {
+	h := NewHost(hclog.NewNullLogger())
+
+	err := h.Register(&testProvider{})
+	assert.NoError(t, err)
+
+	_, ok := h.Provider("mock_saas")
+	assert.True(t, ok)
+	assert.Len(t, h.Schemas(), 1)
+}
+
+func TestHostRegisterDuplicateTypeReturnsError(t *testing.T) {
+	h := NewHost(hclog.NewNullLogger())
+
+	err := h.Register(&testProvider{})
+	assert.NoError(t, err)
+
+	err = h.Register(&testProvider{})
+	assert.Error(t, err)
+}
+
+func TestHostLoadWithMissingFolderDoesNothing(t *testing.T) {
+	h := NewHost(hclog.NewNullLogger())
+
+	err := h.Load("/does/not/exist")
+	assert.NoError(t, err)
+	assert.Len(t, h.Schemas(), 0)
+}
+
+func TestHostLoadSkipsPluginsWhichFailToStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script as the plugin binary")
+	}
+
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "shipyard-plugin-broken"), []byte("#!/bin/sh\nexit 1\n"), 0755)
+	assert.NoError(t, err)
+
+	h := NewHost(hclog.NewNullLogger())
+	t.Cleanup(h.Close)
+
+	err = h.Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, h.Schemas(), 0)
+	assert.Len(t, h.clients, 0)
+}
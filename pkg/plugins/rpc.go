@@ -0,0 +1,75 @@
+package plugins
+
+import (
+	"net/rpc"
+
+	"github.com/hashicorp/go-plugin"
+)
+
+// ProviderPlugin implements plugin.Plugin so that the Provider
+// can be served and consumed over RPC
+type ProviderPlugin struct {
+	Impl Provider
+}
+
+// Server returns the RPC server for the plugin
+func (p *ProviderPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return &rpcServer{p.Impl}, nil
+}
+
+// Client returns a Provider which calls the plugin over RPC
+func (p *ProviderPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &rpcClient{c}, nil
+}
+
+type rpcClient struct {
+	client *rpc.Client
+}
+
+func (c *rpcClient) Schema() ([]ResourceSchema, error) {
+	resp := []ResourceSchema{}
+	err := c.client.Call("Plugin.Schema", new(interface{}), &resp)
+
+	return resp, err
+}
+
+func (c *rpcClient) Create(r Resource) error {
+	return c.client.Call("Plugin.Create", r, new(interface{}))
+}
+
+func (c *rpcClient) Destroy(r Resource) error {
+	return c.client.Call("Plugin.Destroy", r, new(interface{}))
+}
+
+func (c *rpcClient) Lookup(r Resource) ([]string, error) {
+	resp := []string{}
+	err := c.client.Call("Plugin.Lookup", r, &resp)
+
+	return resp, err
+}
+
+type rpcServer struct {
+	impl Provider
+}
+
+func (s *rpcServer) Schema(args interface{}, resp *[]ResourceSchema) error {
+	var err error
+	*resp, err = s.impl.Schema()
+
+	return err
+}
+
+func (s *rpcServer) Create(r Resource, resp *interface{}) error {
+	return s.impl.Create(r)
+}
+
+func (s *rpcServer) Destroy(r Resource, resp *interface{}) error {
+	return s.impl.Destroy(r)
+}
+
+func (s *rpcServer) Lookup(r Resource, resp *[]string) error {
+	var err error
+	*resp, err = s.impl.Lookup(r)
+
+	return err
+}
@@ -68,6 +68,17 @@ func (c *K8sCluster) Lookup() ([]string, error) {
 	return c.client.FindContainerIDs(fmt.Sprintf("server.%s", c.config.Name), c.config.Type)
 }
 
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the server container for the cluster
+func (c *K8sCluster) Exists() (bool, error) {
+	ids, err := c.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
 func (c *K8sCluster) createK3s() error {
 	// create a named log
 	c.log = c.log.Named(c.config.Name)
@@ -152,8 +163,8 @@ func (c *K8sCluster) createK3s() error {
 			return fmt.Errorf("Unable to read root CA for proxy: %s", err)
 		}
 
-		cc.EnvVar["HTTP_PROXY"] = utils.ProxyAddress
-		cc.EnvVar["HTTPS_PROXY"] = utils.ProxyAddress
+		cc.EnvVar["HTTP_PROXY"] = utils.ProxyAddress()
+		cc.EnvVar["HTTPS_PROXY"] = utils.ProxyAddress()
 		cc.EnvVar["NO_PROXY"] = utils.ProxyBypass
 		cc.EnvVar["PROXY_CA"] = string(ca)
 	}
@@ -225,6 +236,8 @@ func (c *K8sCluster) createK3s() error {
 		return xerrors.Errorf("Error creating Local Kubernetes config: %w", err)
 	}
 
+	c.config.APIAddress = fmt.Sprintf("https://%s:%d", utils.GetDockerIP(), clusterConfig.APIPort)
+
 	// create the Docker container version of the Kubeconfig
 	// the default KubeConfig has the server location https://localhost:port
 	// to use this config inside a docker container we need to use the FQDN for the server
@@ -242,7 +255,7 @@ func (c *K8sCluster) createK3s() error {
 		return err
 	}
 
-	err = c.kubeClient.HealthCheckPods([]string{""}, startTimeout)
+	err = c.kubeClient.HealthCheckPods([]string{""}, clusterStartTimeout(c.config.Timeouts))
 	if err != nil {
 		// fetch the logs from the container before exit
 		lr, lerr := c.client.ContainerLogs(id, true, true)
@@ -272,10 +285,11 @@ func (c *K8sCluster) createK3s() error {
 
 func (c *K8sCluster) waitForStart(id string) error {
 	start := time.Now()
+	timeout := clusterStartTimeout(c.config.Timeouts)
 
 	for {
 		// not running after timeout exceeded? Rollback and delete everything.
-		if startTimeout != 0 && time.Now().After(start.Add(startTimeout)) {
+		if timeout != 0 && time.Now().After(start.Add(timeout)) {
 			//deleteCluster()
 			return errors.New("Cluster creation exceeded specified timeout")
 		}
@@ -450,8 +464,13 @@ func (c *K8sCluster) deployConnector(grpcPort, httpPort int) error {
 		return fmt.Errorf("Unable to apply configuration: %s", err)
 	}
 
-	// wait for it to start
-	c.kubeClient.HealthCheckPods([]string{"app=connector"}, 60*time.Second)
+	// wait for it to start, the create timeout of the cluster replaces the default
+	to := 60 * time.Second
+	if d, err := c.config.Timeouts.CreateDuration(); err == nil && d > 0 {
+		to = d
+	}
+
+	c.kubeClient.HealthCheckPods([]string{"app=connector"}, to)
 	if err != nil {
 		return fmt.Errorf("Error waiting for connector to start: %s", err)
 	}
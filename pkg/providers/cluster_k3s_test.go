@@ -104,6 +104,18 @@ func TestClusterK3ErrorsWhenUnableToLookupIDs(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestClusterK3SetsAPIAddress(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+
+	p := NewK8sCluster(cc, md, mk, nil, mc, hclog.NewNullLogger())
+
+	err := p.Create()
+	assert.NoError(t, err)
+
+	conf, _ := utils.GetClusterConfig(string(config.TypeK8sCluster) + "." + cc.Name)
+	assert.Equal(t, fmt.Sprintf("https://%s:%d", utils.GetDockerIP(), conf.APIPort), cc.APIAddress)
+}
+
 func TestClusterK3SetsEnvironment(t *testing.T) {
 	cc, md, mk, mc := setupClusterMocks(t)
 	cc.Version = ""
@@ -117,8 +129,8 @@ func TestClusterK3SetsEnvironment(t *testing.T) {
 
 	assert.Equal(t, params.EnvVar["K3S_KUBECONFIG_OUTPUT"], "/output/kubeconfig.yaml")
 	assert.Equal(t, params.EnvVar["K3S_CLUSTER_SECRET"], "mysupersecret")
-	assert.Equal(t, params.EnvVar["HTTP_PROXY"], utils.ProxyAddress)
-	assert.Equal(t, params.EnvVar["HTTPS_PROXY"], utils.ProxyAddress)
+	assert.Equal(t, params.EnvVar["HTTP_PROXY"], utils.ProxyAddress())
+	assert.Equal(t, params.EnvVar["HTTPS_PROXY"], utils.ProxyAddress())
 	assert.Equal(t, params.EnvVar["NO_PROXY"], utils.ProxyBypass)
 
 	assert.Equal(t, params.EnvVar["PROXY_CA"], "CA")
@@ -388,6 +400,17 @@ func TestClusterK3sWaitsForPods(t *testing.T) {
 	mk.AssertCalled(t, "HealthCheckPods", []string{""}, startTimeout)
 }
 
+func TestClusterK3sWaitsForPodsWithCreateTimeout(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Timeouts = &config.Timeouts{Create: "10m"}
+
+	p := NewK8sCluster(cc, md, mk, nil, mc, hclog.NewNullLogger())
+
+	err := p.Create()
+	assert.NoError(t, err)
+	mk.AssertCalled(t, "HealthCheckPods", []string{""}, 10*time.Minute)
+}
+
 func TestClusterK3sErrorsWhenWaitsForPodsFail(t *testing.T) {
 	cc, md, mk, mc := setupClusterMocks(t)
 
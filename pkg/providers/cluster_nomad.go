@@ -74,6 +74,17 @@ func (c *NomadCluster) Lookup() ([]string, error) {
 	return c.client.FindContainerIDs(fmt.Sprintf("server.%s", c.config.Name), c.config.Type)
 }
 
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the server container for the cluster
+func (c *NomadCluster) Exists() (bool, error) {
+	ids, err := c.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
 func (c *NomadCluster) createNomad() error {
 	c.log.Info("Creating Cluster", "ref", c.config.Name)
 
@@ -158,7 +169,7 @@ func (c *NomadCluster) createNomad() error {
 
 	// ensure all client nodes are up
 	c.nomadClient.SetConfig(clusterConfig, string(utils.LocalContext))
-	err = c.nomadClient.HealthCheckAPI(startTimeout)
+	err = c.nomadClient.HealthCheckAPI(clusterStartTimeout(c.config.Timeouts))
 	if err != nil {
 		return err
 	}
@@ -362,8 +373,8 @@ func (c *NomadCluster) appendProxyEnv(cc *config.Container) error {
 			return fmt.Errorf("Unable to read root CA for proxy: %s", err)
 		}
 
-		cc.EnvVar["HTTP_PROXY"] = utils.ProxyAddress
-		cc.EnvVar["HTTPS_PROXY"] = utils.ProxyAddress
+		cc.EnvVar["HTTP_PROXY"] = utils.ProxyAddress()
+		cc.EnvVar["HTTPS_PROXY"] = utils.ProxyAddress()
 		cc.EnvVar["NO_PROXY"] = utils.ProxyBypass
 		cc.EnvVar["PROXY_CA"] = string(ca)
 	}
@@ -1,11 +1,11 @@
 package providers
 
 import (
-	"time"
-
+	"github.com/docker/docker/api/types"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
 	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
 	"golang.org/x/xerrors"
 )
 
@@ -71,15 +71,23 @@ func (c *Container) internalCreate() error {
 		}
 	}
 
-	_, err := c.client.CreateContainer(c.config)
+	id, err := c.client.CreateContainer(c.config)
+	if err != nil {
+		return err
+	}
 
-	if c.config.HealthCheck == nil {
+	err = c.setOutputs(id)
+	if err != nil {
 		return err
 	}
 
+	if c.config.HealthCheck == nil {
+		return nil
+	}
+
 	// check the health of the container
 	if hc := c.config.HealthCheck.HTTP; hc != "" {
-		d, err := time.ParseDuration(c.config.HealthCheck.Timeout)
+		d, err := healthCheckTimeout(c.config.HealthCheck, c.config.Timeouts)
 		if err != nil {
 			return err
 		}
@@ -96,6 +104,38 @@ func (c *Container) internalCreate() error {
 	return nil
 }
 
+// setOutputs reads the attributes which are only known once the container
+// has been created such as the IP address and the ports assigned by Docker
+func (c *Container) setOutputs(id string) error {
+	i, err := c.client.ContainerInfo(id)
+	if err != nil {
+		return err
+	}
+
+	info, ok := i.(types.ContainerJSON)
+	if !ok || info.NetworkSettings == nil {
+		return nil
+	}
+
+	c.config.IPAddress = ""
+	for _, n := range c.config.Networks {
+		en, ok := info.NetworkSettings.Networks[utils.DockerNetworkName(n.Name)]
+		if ok && en.IPAddress != "" {
+			c.config.IPAddress = en.IPAddress
+			break
+		}
+	}
+
+	c.config.AssignedPorts = map[string]string{}
+	for p, b := range info.NetworkSettings.Ports {
+		if len(b) > 0 {
+			c.config.AssignedPorts[p.Port()] = b[0].HostPort
+		}
+	}
+
+	return nil
+}
+
 // Destroy stops and removes the container
 func (c *Container) Destroy() error {
 	c.log.Info("Destroy Container", "ref", c.config.Name)
@@ -134,3 +174,14 @@ func (c *Container) internalDestroy() error {
 func (c *Container) Lookup() ([]string, error) {
 	return c.client.FindContainerIDs(c.config.Name, c.config.Type)
 }
+
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the container
+func (c *Container) Exists() (bool, error) {
+	ids, err := c.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
@@ -5,6 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
 	"github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients/mocks"
 	"github.com/shipyard-run/shipyard/pkg/config"
@@ -24,6 +27,7 @@ func TestContainerCreatesSuccessfully(t *testing.T) {
 
 	// check calls CreateContainer with the config
 	md.On("CreateContainer", cc).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
 
 	err := c.Create()
 	assert.NoError(t, err)
@@ -31,6 +35,56 @@ func TestContainerCreatesSuccessfully(t *testing.T) {
 	hc.AssertNotCalled(t, "HealthCheckHTTP", mock.Anything, mock.Anything)
 }
 
+func TestContainerCreateSetsOutputs(t *testing.T) {
+	cc := config.NewContainer("tests")
+	cc.Image = &config.Image{}
+	cc.Networks = []config.NetworkAttachment{
+		config.NetworkAttachment{Name: "network.onprem"},
+		config.NetworkAttachment{Name: "network.cloud"},
+	}
+
+	md := &mocks.MockContainerTasks{}
+	hc := &mocks.MockHTTP{}
+	c := NewContainer(cc, md, hc, hclog.NewNullLogger())
+
+	md.On("PullImage", *cc.Image, false).Once().Return(nil)
+	md.On("CreateContainer", cc).Once().Return("abc", nil)
+	md.On("ContainerInfo", "abc").Return(types.ContainerJSON{
+		NetworkSettings: &types.NetworkSettings{
+			NetworkSettingsBase: types.NetworkSettingsBase{
+				Ports: nat.PortMap{
+					"8500/tcp": []nat.PortBinding{nat.PortBinding{HostIP: "0.0.0.0", HostPort: "32768"}},
+				},
+			},
+			Networks: map[string]*network.EndpointSettings{
+				"cloud": &network.EndpointSettings{IPAddress: "10.5.0.2"},
+			},
+		},
+	}, nil)
+
+	err := c.Create()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10.5.0.2", cc.IPAddress)
+	assert.Equal(t, map[string]string{"8500": "32768"}, cc.AssignedPorts)
+}
+
+func TestContainerCreateWithInfoErrorReturnsError(t *testing.T) {
+	cc := config.NewContainer("tests")
+	cc.Image = &config.Image{}
+
+	md := &mocks.MockContainerTasks{}
+	hc := &mocks.MockHTTP{}
+	c := NewContainer(cc, md, hc, hclog.NewNullLogger())
+
+	md.On("PullImage", *cc.Image, false).Once().Return(nil)
+	md.On("CreateContainer", cc).Once().Return("abc", nil)
+	md.On("ContainerInfo", "abc").Return(nil, fmt.Errorf("boom"))
+
+	err := c.Create()
+	assert.Error(t, err)
+}
+
 func TestContainerSidecarCreatesContainerSuccessfully(t *testing.T) {
 	md := &mocks.MockContainerTasks{}
 	hc := &mocks.MockHTTP{}
@@ -50,6 +104,7 @@ func TestContainerSidecarCreatesContainerSuccessfully(t *testing.T) {
 
 	md.On("PullImage", cc.Image, false).Once().Return(nil)
 	md.On("CreateContainer", mock.Anything).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
 
 	c := NewContainerSidecar(cc, md, hc, hclog.NewNullLogger())
 	err := c.Create()
@@ -87,6 +142,7 @@ func TestContainerRunsHTTPChecks(t *testing.T) {
 
 	md.On("PullImage", *cc.Image, false).Once().Return(nil)
 	md.On("CreateContainer", cc).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
 
 	hc.On("HealthCheckHTTP", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
@@ -111,6 +167,7 @@ func TestContainerRunsHTTPChecksWithCustomStatusCodes(t *testing.T) {
 
 	md.On("PullImage", *cc.Image, false).Once().Return(nil)
 	md.On("CreateContainer", cc).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
 
 	hc.On("HealthCheckHTTP", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
@@ -120,6 +177,31 @@ func TestContainerRunsHTTPChecksWithCustomStatusCodes(t *testing.T) {
 	hc.AssertCalled(t, "HealthCheckHTTP", "http://localhost:8500", []int{200, 429}, 30*time.Second)
 }
 
+func TestContainerHTTPChecksUseCreateTimeout(t *testing.T) {
+	cc := config.NewContainer("tests")
+	cc.Image = &config.Image{}
+	cc.Timeouts = &config.Timeouts{Create: "5m"}
+	cc.HealthCheck = &config.HealthCheck{
+		Timeout: "30s",
+		HTTP:    "http://localhost:8500",
+	}
+
+	md := &mocks.MockContainerTasks{}
+	hc := &mocks.MockHTTP{}
+	c := NewContainer(cc, md, hc, hclog.NewNullLogger())
+
+	md.On("PullImage", *cc.Image, false).Once().Return(nil)
+	md.On("CreateContainer", cc).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
+
+	hc.On("HealthCheckHTTP", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := c.Create()
+	assert.NoError(t, err)
+
+	hc.AssertCalled(t, "HealthCheckHTTP", "http://localhost:8500", []int{200}, 5*time.Minute)
+}
+
 func TestContainerDoesNOTCreateWhenPullImageFail(t *testing.T) {
 	cc := config.NewContainer("tests")
 	cc.Image = &config.Image{}
@@ -195,6 +277,19 @@ func TestContainerLooksupIDs(t *testing.T) {
 	assert.Equal(t, []string{"abc"}, ids)
 }
 
+func TestContainerExistsReturnsFalseWhenNoContainers(t *testing.T) {
+	cc := config.NewContainer("tests")
+	md := &mocks.MockContainerTasks{}
+	hc := &mocks.MockHTTP{}
+	c := NewContainer(cc, md, hc, hclog.NewNullLogger())
+
+	md.On("FindContainerIDs", cc.Name, cc.Type).Return([]string{}, nil)
+
+	exists, err := c.Exists()
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
 func TestContainerBuildsContainer(t *testing.T) {
 	cc := config.NewContainer("tests")
 	cc.Build = &config.Build{Context: "./", File: "./"}
@@ -202,6 +297,7 @@ func TestContainerBuildsContainer(t *testing.T) {
 	md := &mocks.MockContainerTasks{}
 	md.On("BuildContainer", mock.Anything, mock.Anything).Return("testimage", nil)
 	md.On("CreateContainer", cc).Once().Return("", nil)
+	md.On("ContainerInfo", mock.Anything).Return(types.ContainerJSON{}, nil)
 
 	hc := &mocks.MockHTTP{}
 	c := NewContainer(cc, md, hc, hclog.NewNullLogger())
@@ -39,7 +39,7 @@ func (c *ExecLocal) Create() error {
 	}
 
 	// create the folders for logs and pids
-	logPath := filepath.Join(utils.LogsDir(), fmt.Sprintf("exec_%s.log", c.config.Name))
+	logPath := filepath.Join(utils.LogsDir(), fmt.Sprintf("exec_%s.log", utils.ProviderName(c.config.Name)))
 
 	// do we have a duration to parse
 	var d time.Duration
@@ -53,6 +53,13 @@ func (c *ExecLocal) Create() error {
 		if c.config.Daemon {
 			c.log.Warn("Timeout will be ignored when exec is running in daemon mode")
 		}
+	} else {
+		// use the create timeout for the resource when set rather
+		// than the default timeout for the command client
+		d, err = c.config.Timeouts.CreateDuration()
+		if err != nil {
+			return err
+		}
 	}
 
 	// create the config
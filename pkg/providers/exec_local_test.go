@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
@@ -54,6 +55,19 @@ func TestExecLocalExecutesCommandAndSetsPid(t *testing.T) {
 	assert.Equal(t, 123, c.Pid)
 }
 
+func TestExecLocalUsesCreateTimeoutWhenTimeoutNotSet(t *testing.T) {
+	c, mc := testLocalExecSetupMocks()
+	c.Timeouts = &config.Timeouts{Create: "10m"}
+
+	p := NewExecLocal(c, mc, hclog.Default())
+
+	err := p.Create()
+	assert.NoError(t, err)
+
+	params := mc.Calls[0].Arguments[0].(clients.CommandConfig)
+	assert.Equal(t, 10*time.Minute, params.Timeout)
+}
+
 func TestExecLocalExecuteFailsReturnsError(t *testing.T) {
 	c, mc := testLocalExecSetupMocks()
 
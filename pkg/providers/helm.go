@@ -1,8 +1,6 @@
 package providers
 
 import (
-	"time"
-
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
 	"github.com/shipyard-run/shipyard/pkg/config"
@@ -27,6 +25,19 @@ func NewHelm(c *config.Helm, kc clients.Kubernetes, hc clients.Helm, g clients.G
 func (h *Helm) Create() error {
 	h.log.Info("Creating Helm chart", "ref", h.config.Name)
 
+	return h.install(false)
+}
+
+// Update implements the Updater interface and upgrades the
+// existing Helm release with the current config
+func (h *Helm) Update() error {
+	h.log.Info("Updating Helm chart", "ref", h.config.Name)
+
+	return h.install(true)
+}
+
+// install the chart, when upgrade is true the existing release is upgraded
+func (h *Helm) install(upgrade bool) error {
 	// get the target cluster
 	kcPath, err := h.getKubeConfigPath()
 	if err != nil {
@@ -61,10 +72,16 @@ func (h *Helm) Create() error {
 		return xerrors.Errorf("unable to create Kubernetes client: %w", err)
 	}
 
-	err = h.helmClient.Create(
-		kcPath, h.config.ChartName,
-		h.config.Namespace, h.config.CreateNamespace,
-		h.config.Chart, h.config.Values, h.config.ValuesString)
+	if upgrade {
+		err = h.helmClient.Upgrade(
+			kcPath, h.config.ChartName, h.config.Namespace,
+			h.config.Chart, h.config.Values, h.config.ValuesString)
+	} else {
+		err = h.helmClient.Create(
+			kcPath, h.config.ChartName,
+			h.config.Namespace, h.config.CreateNamespace,
+			h.config.Chart, h.config.Values, h.config.ValuesString)
+	}
 
 	if err != nil {
 		return err
@@ -72,7 +89,7 @@ func (h *Helm) Create() error {
 
 	// we can now health check the install
 	if h.config.HealthCheck != nil && len(h.config.HealthCheck.Pods) > 0 {
-		to, err := time.ParseDuration(h.config.HealthCheck.Timeout)
+		to, err := healthCheckTimeout(h.config.HealthCheck, h.config.Timeouts)
 		if err != nil {
 			return xerrors.Errorf("unable to parse healthcheck duration: %w", err)
 		}
@@ -109,9 +126,40 @@ func (h *Helm) Destroy() error {
 	return nil
 }
 
-// Lookup implements the provider Lookup method
+// Lookup implements the provider Lookup method, it returns the name
+// of the release when it has been installed in the cluster
 func (h *Helm) Lookup() ([]string, error) {
-	return []string{}, nil
+	kcPath, err := h.getKubeConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// if the namespace is null set to default
+	if h.config.Namespace == "" {
+		h.config.Namespace = "default"
+	}
+
+	status, err := h.helmClient.Status(kcPath, h.config.ChartName, h.config.Namespace)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to get status for Helm release: %w", err)
+	}
+
+	if status == "" || status == "uninstalled" {
+		return []string{}, nil
+	}
+
+	return []string{h.config.ChartName}, nil
+}
+
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the Helm release
+func (h *Helm) Exists() (bool, error) {
+	ids, err := h.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
 }
 
 func (h *Helm) getKubeConfigPath() (string, error) {
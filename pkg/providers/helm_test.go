@@ -19,6 +19,7 @@ func setupHelm() (*mocks.MockHelm, *clients.MockKubernetes, *mocks.Getter, *conf
 	mh := &mocks.MockHelm{}
 	mh.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mh.On("Destroy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mh.On("Upgrade", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	kc := &clients.MockKubernetes{}
 	kc.On("SetConfig", mock.Anything).Return(nil)
@@ -184,3 +185,44 @@ func TestHelmDestroyWithErrorSwallowsError(t *testing.T) {
 	assert.NoError(t, err)
 	hm.AssertCalled(t, "Destroy", mock.Anything, mock.Anything, "custom")
 }
+
+func TestHelmUpdateCallsUpgrade(t *testing.T) {
+	mh, _, _, _, p := setupHelm()
+
+	err := p.Update()
+	assert.NoError(t, err)
+
+	_, fp, _ := utils.CreateKubeConfigPath("tester")
+	mh.AssertCalled(t, "Upgrade", fp, "test", "default", mock.Anything, mock.Anything, mock.Anything)
+	mh.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHelmLookupReturnsReleaseWhenInstalled(t *testing.T) {
+	mh, _, _, _, p := setupHelm()
+	mh.On("Status", mock.Anything, "test", "default").Return("deployed", nil)
+
+	ids, err := p.Lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, ids)
+
+	exists, err := p.Exists()
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestHelmExistsReturnsFalseWhenReleaseNotFound(t *testing.T) {
+	mh, _, _, _, p := setupHelm()
+	mh.On("Status", mock.Anything, "test", "default").Return("", nil)
+
+	exists, err := p.Exists()
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestHelmExistsWithStatusErrorReturnsError(t *testing.T) {
+	mh, _, _, _, p := setupHelm()
+	mh.On("Status", mock.Anything, "test", "default").Return("", fmt.Errorf("boom"))
+
+	_, err := p.Exists()
+	assert.Error(t, err)
+}
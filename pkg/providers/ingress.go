@@ -32,6 +32,10 @@ func NewIngress(
 func (c *Ingress) Create() error {
 	c.log.Info("Create Ingress", "ref", c.config.Name)
 
+	return c.expose()
+}
+
+func (c *Ingress) expose() error {
 	if c.config.Destination.Driver == "local" {
 		return c.exposeLocal()
 	}
@@ -43,6 +47,23 @@ func (c *Ingress) Create() error {
 	return nil
 }
 
+// Update implements the Updater interface, the existing service is removed
+// from the connector and exposed with the current config
+func (c *Ingress) Update() error {
+	c.log.Info("Update Ingress", "ref", c.config.Name, "id", c.config.Id)
+
+	if c.config.Id != "" {
+		err := c.connector.RemoveService(c.config.Id)
+		if err != nil {
+			c.log.Warn("Unable to remove existing ingress", "ref", c.config.Name, "id", c.config.Id, "error", err)
+		}
+
+		c.config.Id = ""
+	}
+
+	return c.expose()
+}
+
 // Destroy satisfies the interface method but is not implemented by LocalExec
 func (c *Ingress) Destroy() error {
 	c.log.Info("Destroy Ingress", "ref", c.config.Name, "id", c.config.Id)
@@ -98,7 +119,7 @@ func (c *Ingress) exposeLocal() error {
 	destAddr := fmt.Sprintf("%s:%s", c.config.Destination.Config.Address, c.config.Destination.Config.Port)
 
 	// sanitize the name to make it uri format
-	serviceName, err := utils.ReplaceNonURIChars(c.config.Name)
+	serviceName, err := utils.ReplaceNonURIChars(utils.ProviderName(c.config.Name))
 	if err != nil {
 		return xerrors.Errorf("Unable to repace non URI characters in service name %s :%w", c.config.Name, err)
 	}
@@ -157,7 +178,7 @@ func (c *Ingress) exposeK8sRemote() error {
 	}
 
 	// sanitize the name to make it uri format
-	serviceName, err := utils.ReplaceNonURIChars(c.config.Name)
+	serviceName, err := utils.ReplaceNonURIChars(utils.ProviderName(c.config.Name))
 	if err != nil {
 		return xerrors.Errorf("Unable to repace non URI characters in service name %s :%w", c.config.Name, err)
 	}
@@ -239,6 +239,25 @@ func TestIngressDestroyCallsRemove(t *testing.T) {
 	mc.AssertCalled(t, "RemoveService", "12345")
 }
 
+func TestIngressUpdateRemovesAndExposes(t *testing.T) {
+	md, c := testIngressCreateMocks()
+	mc := testIngressCreateMockConnector(t, testIngressExposeK8sLocalConfig.Name)
+
+	tc := testIngressExposeK8sLocalConfig
+	tc.Id = "54321"
+	c.AddResource(&tc)
+
+	p := NewIngress(&tc, md, mc, hclog.NewNullLogger())
+
+	err := p.Update()
+	assert.NoError(t, err)
+
+	mc.AssertCalled(t, "RemoveService", "54321")
+	mc.AssertCalled(t, "ExposeService", tc.Name, mock.Anything, mock.Anything, mock.Anything, "local")
+
+	assert.Equal(t, tc.Id, "12345")
+}
+
 var testIngressExposeK8sLocalConfig = config.Ingress{
 	ResourceInfo: config.ResourceInfo{
 		Name: "local-http",
@@ -1,8 +1,6 @@
 package providers
 
 import (
-	"time"
-
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
 	"github.com/shipyard-run/shipyard/pkg/config"
@@ -25,6 +23,18 @@ func NewK8sConfig(c *config.K8sConfig, kc clients.Kubernetes, l hclog.Logger) *K
 func (c *K8sConfig) Create() error {
 	c.log.Info("Applying Kubernetes configuration", "ref", c.config.Name, "config", c.config.Paths)
 
+	return c.apply()
+}
+
+// Update implements the Updater interface and re-applies the Kubernetes
+// configuration, Kubernetes will modify any existing resources in place
+func (c *K8sConfig) Update() error {
+	c.log.Info("Updating Kubernetes configuration", "ref", c.config.Name, "config", c.config.Paths)
+
+	return c.apply()
+}
+
+func (c *K8sConfig) apply() error {
 	err := c.setup()
 	if err != nil {
 		return err
@@ -37,7 +47,7 @@ func (c *K8sConfig) Create() error {
 
 	// run any health checks
 	if c.config.HealthCheck != nil && len(c.config.HealthCheck.Pods) > 0 {
-		to, err := time.ParseDuration(c.config.HealthCheck.Timeout)
+		to, err := healthCheckTimeout(c.config.HealthCheck, c.config.Timeouts)
 		if err != nil {
 			return xerrors.Errorf("unable to parse healthcheck duration: %w", err)
 		}
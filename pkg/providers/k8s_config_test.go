@@ -44,6 +44,16 @@ func TestCreatesCorrectly(t *testing.T) {
 	mk.AssertCalled(t, "Apply", p.config.Paths, p.config.WaitUntilReady)
 }
 
+func TestUpdateAppliesConfig(t *testing.T) {
+	mk, p := setupK8sConfig()
+
+	err := p.Update()
+	assert.NoError(t, err)
+
+	mk.AssertCalled(t, "Apply", p.config.Paths, p.config.WaitUntilReady)
+	mk.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
 func TestRunsHealthChecks(t *testing.T) {
 	mk, p := setupK8sConfig()
 	p.config.HealthCheck = &config.HealthCheck{
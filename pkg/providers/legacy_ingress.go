@@ -238,7 +238,18 @@ func (i *LegacyIngress) Destroy() error {
 
 // Lookup the id of the ingress
 func (i *LegacyIngress) Lookup() ([]string, error) {
-	return []string{}, nil
+	return i.client.FindContainerIDs(i.config.Name, config.TypeIngress)
+}
+
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the ingress container
+func (i *LegacyIngress) Exists() (bool, error) {
+	ids, err := i.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
 }
 
 // Config returns the config for the provider
@@ -32,3 +32,33 @@ func (m *MockProvider) Lookup() ([]string, error) {
 func (m *MockProvider) Config() config.Resource {
 	return m.c
 }
+
+// MockUpdater is a mock provider which implements the
+// providers.Updater interface
+type MockUpdater struct {
+	*MockProvider
+}
+
+func NewUpdater(c config.Resource) *MockUpdater {
+	return &MockUpdater{New(c)}
+}
+
+func (m *MockUpdater) Update() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// MockRefresher is a mock provider which implements the
+// providers.Refresher interface
+type MockRefresher struct {
+	*MockProvider
+}
+
+func NewRefresher(c config.Resource) *MockRefresher {
+	return &MockRefresher{New(c)}
+}
+
+func (m *MockRefresher) Exists() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
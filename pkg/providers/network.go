@@ -11,6 +11,7 @@ import (
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/shipyard-run/shipyard/pkg/clients"
 	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
 	"golang.org/x/xerrors"
 )
 
@@ -19,11 +20,20 @@ type Network struct {
 	config *config.Network
 	client clients.Docker
 	log    hclog.Logger
+	ctx    context.Context
 }
 
 // NewNetwork creates a new network with the given config and Docker client
 func NewNetwork(co *config.Network, cl clients.Docker, l hclog.Logger) *Network {
-	return &Network{co, cl, l}
+	return &Network{co, cl, l, context.Background()}
+}
+
+// WithContext sets the context used for calls to the Docker SDK,
+// when the context is cancelled any in flight calls are aborted
+func (n *Network) WithContext(ctx context.Context) *Network {
+	n.ctx = ctx
+
+	return n
 }
 
 // Create implements the provider interface method for creating new networks
@@ -42,6 +52,8 @@ func (n *Network) Create() error {
 		return err
 	}
 
+	name := utils.DockerNetworkName(n.config.Name)
+
 	// is the network name and subnet equal to one which already exists
 	bridgeExists := false
 	for _, ne := range nets {
@@ -49,7 +61,7 @@ func (n *Network) Create() error {
 			bridgeExists = true
 		}
 
-		if ne.Name == n.config.Name {
+		if ne.Name == name {
 			for _, ci := range ne.IPAM.Config {
 				// check that the returned networks subnet matches the existing networks subnet
 				if ci.Subnet != n.config.Subnet {
@@ -95,7 +107,7 @@ func (n *Network) Create() error {
 		Attachable: true,
 	}
 
-	_, err = n.client.NetworkCreate(context.Background(), n.config.Name, opts)
+	_, err = n.client.NetworkCreate(n.ctx, name, opts)
 	if err != nil {
 		return err
 	}
@@ -117,7 +129,7 @@ func (n *Network) Destroy() error {
 	}
 
 	if len(ids) == 1 {
-		return n.client.NetworkRemove(context.Background(), n.config.Name)
+		return n.client.NetworkRemove(n.ctx, utils.DockerNetworkName(n.config.Name))
 	}
 
 	return nil
@@ -125,7 +137,7 @@ func (n *Network) Destroy() error {
 
 // Lookup the ID for a network
 func (n *Network) Lookup() ([]string, error) {
-	nets, err := n.getNetworks(n.config.Name)
+	nets, err := n.getNetworks(utils.DockerNetworkName(n.config.Name))
 
 	if err != nil {
 		return nil, err
@@ -139,8 +151,19 @@ func (n *Network) Lookup() ([]string, error) {
 	return ids, nil
 }
 
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the Docker network
+func (n *Network) Exists() (bool, error) {
+	ids, err := n.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
 func (n *Network) getNetworks(name string) ([]types.NetworkResource, error) {
 	args := filters.NewArgs()
 	args.Add("name", name)
-	return n.client.NetworkList(context.Background(), types.NetworkListOptions{Filters: args})
+	return n.client.NetworkList(n.ctx, types.NetworkListOptions{Filters: args})
 }
@@ -9,6 +9,7 @@ import (
 	hclog "github.com/hashicorp/go-hclog"
 	clients "github.com/shipyard-run/shipyard/pkg/clients/mocks"
 	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
 	"github.com/stretchr/testify/mock"
 	assert "github.com/stretchr/testify/require"
 )
@@ -60,6 +61,33 @@ func TestLookupFailReturnsError(t *testing.T) {
 	_, err := p.Lookup()
 	assert.Error(t, err)
 }
+func TestNetworkCreatesWithWorkspaceName(t *testing.T) {
+	utils.SetWorkspace("payments")
+	t.Cleanup(func() { utils.SetWorkspace("") })
+
+	c := config.NewNetwork("testnet")
+	c.Subnet = "10.1.2.0/24"
+
+	md, p := setupNetworkTests(c)
+
+	err := p.Create()
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "NetworkCreate", mock.Anything, "testnet.payments", mock.Anything)
+}
+
+func TestNetworkCreatesInstanceWithProviderName(t *testing.T) {
+	c := config.NewNetwork("cloud[0]")
+	c.Subnet = "10.1.2.0/24"
+
+	md, p := setupNetworkTests(c)
+
+	err := p.Create()
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "NetworkCreate", mock.Anything, "cloud-0", mock.Anything)
+}
+
 func TestNetworkCreatesCorrectly(t *testing.T) {
 	c := config.NewNetwork("testnet")
 	c.Subnet = "10.1.2.0/24"
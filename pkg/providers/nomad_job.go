@@ -27,6 +27,18 @@ func NewNomadJob(c *config.NomadJob, hc clients.Nomad, l hclog.Logger) *NomadJob
 func (n *NomadJob) Create() error {
 	n.log.Info("Create Nomad Job", "ref", n.config.Name, "files", n.config.Paths)
 
+	return n.register()
+}
+
+// Update implements the Updater interface and re-registers the jobs,
+// Nomad will update any existing jobs with the new definition
+func (n *NomadJob) Update() error {
+	n.log.Info("Update Nomad Job", "ref", n.config.Name, "files", n.config.Paths)
+
+	return n.register()
+}
+
+func (n *NomadJob) register() error {
 	// find the cluster
 	cc, err := n.config.ResourceInfo.FindDependentResource(n.config.Cluster)
 	if err != nil {
@@ -45,7 +57,7 @@ func (n *NomadJob) Create() error {
 	// if health check defined wait for jobs
 	if n.config.HealthCheck != nil {
 		st := time.Now()
-		dur, err := time.ParseDuration(n.config.HealthCheck.Timeout)
+		dur, err := healthCheckTimeout(n.config.HealthCheck, n.config.Timeouts)
 		if err != nil {
 			return err
 		}
@@ -96,9 +108,42 @@ func (n *NomadJob) Destroy() error {
 	return nil
 }
 
-// Lookup the Nomad jobs defined by the config
+// Lookup the Nomad jobs defined by the config, returns the files
+// for jobs which are registered with the cluster and have not stopped
 func (n *NomadJob) Lookup() ([]string, error) {
-	return nil, nil
+	cc, err := n.config.ResourceInfo.FindDependentResource(n.config.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the config
+	clusterConfig, _ := utils.GetClusterConfig(string(cc.Info().Type) + "." + cc.Info().Name)
+	n.client.SetConfig(clusterConfig, string(utils.LocalContext))
+
+	jobs := []string{}
+	for _, p := range n.config.Paths {
+		s, err := n.client.JobStatus(p)
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to get status for Nomad job %s: %w", p, err)
+		}
+
+		if s != "" && s != "dead" {
+			jobs = append(jobs, p)
+		}
+	}
+
+	return jobs, nil
+}
+
+// Exists implements the Refresher interface and returns true when
+// Lookup finds the Nomad jobs
+func (n *NomadJob) Exists() (bool, error) {
+	ids, err := n.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
 }
 
 // /v1/jobs/parse
@@ -78,6 +78,18 @@ func TestNomadJobValidatesConfig(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNomadJobUpdateRegistersJob(t *testing.T) {
+	jc, mh := setupNomadJobMocks()
+
+	p := NewNomadJob(jc, mh, hclog.NewNullLogger())
+
+	err := p.Update()
+	assert.NoError(t, err)
+
+	mh.AssertCalled(t, "Create", jc.Paths)
+	mh.AssertNotCalled(t, "Stop", mock.Anything)
+}
+
 func TestNomadJobHealthCheckInvalidDurationReturnsError(t *testing.T) {
 	jc, mh := setupNomadJobMocks()
 	jc.HealthCheck = &config.HealthCheck{
@@ -161,3 +173,29 @@ func TestNomadJobDestroyCallsStop(t *testing.T) {
 
 	mh.AssertCalled(t, "Stop", jc.Paths)
 }
+
+func TestNomadJobLookupReturnsRunningJobs(t *testing.T) {
+	jc, mh := setupNomadJobMocks()
+	jc.Paths = []string{"./blah.hcl", "./something.hcl"}
+
+	mh.On("JobStatus", "./blah.hcl").Return("running", nil)
+	mh.On("JobStatus", "./something.hcl").Return("dead", nil)
+
+	p := NewNomadJob(jc, mh, hclog.NewNullLogger())
+
+	ids, err := p.Lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"./blah.hcl"}, ids)
+}
+
+func TestNomadJobExistsReturnsFalseWhenJobsNotFound(t *testing.T) {
+	jc, mh := setupNomadJobMocks()
+
+	mh.On("JobStatus", mock.Anything).Return("", nil)
+
+	p := NewNomadJob(jc, mh, hclog.NewNullLogger())
+
+	exists, err := p.Exists()
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
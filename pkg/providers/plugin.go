@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/plugins"
+)
+
+// Plugin is a provider which forwards operations to an external plugin
+type Plugin struct {
+	config *config.PluginResource
+	plugin plugins.Provider
+	log    hclog.Logger
+}
+
+// NewPlugin creates a provider for a resource type which is managed by a plugin
+func NewPlugin(c *config.PluginResource, p plugins.Provider, l hclog.Logger) *Plugin {
+	return &Plugin{c, p, l}
+}
+
+// Create the resource using the plugin
+func (p *Plugin) Create() error {
+	p.log.Info("Creating resource", "ref", p.config.Name, "type", p.config.Type)
+
+	r, err := p.resource()
+	if err != nil {
+		return err
+	}
+
+	return p.plugin.Create(r)
+}
+
+// Destroy the resource using the plugin
+func (p *Plugin) Destroy() error {
+	p.log.Info("Destroy resource", "ref", p.config.Name, "type", p.config.Type)
+
+	r, err := p.resource()
+	if err != nil {
+		return err
+	}
+
+	return p.plugin.Destroy(r)
+}
+
+// Lookup the ids of the objects created by the plugin
+func (p *Plugin) Lookup() ([]string, error) {
+	r, err := p.resource()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.plugin.Lookup(r)
+}
+
+// Exists returns true when the plugin reports objects for the resource
+func (p *Plugin) Exists() (bool, error) {
+	ids, err := p.Lookup()
+	if err != nil {
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
+func (p *Plugin) resource() (plugins.Resource, error) {
+	attrs := p.config.Attributes
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+
+	d, err := json.Marshal(attrs)
+	if err != nil {
+		return plugins.Resource{}, fmt.Errorf("Unable to encode attributes for resource %s.%s: %s", p.config.Type, p.config.Name, err)
+	}
+
+	return plugins.Resource{
+		Type:       string(p.config.Type),
+		Name:       p.config.Name,
+		Module:     p.config.Module,
+		Attributes: d,
+	}, nil
+}
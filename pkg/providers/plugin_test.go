@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"fmt"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/plugins"
+	"github.com/shipyard-run/shipyard/pkg/plugins/mocks"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupPluginTests() (*config.PluginResource, *mocks.MockProvider, *Plugin) {
+	c := config.NewPluginResource("mock_saas", "api")
+	c.Module = "test"
+	c.Attributes = map[string]interface{}{"endpoint": "http://localhost:9090"}
+
+	mp := &mocks.MockProvider{}
+	mp.On("Create", mock.Anything).Return(nil)
+	mp.On("Destroy", mock.Anything).Return(nil)
+	mp.On("Lookup", mock.Anything).Return([]string{"abc"}, nil)
+
+	return c, mp, NewPlugin(c, mp, hclog.NewNullLogger())
+}
+
+func TestPluginCreateSendsResourceToPlugin(t *testing.T) {
+	_, mp, p := setupPluginTests()
+
+	err := p.Create()
+	assert.NoError(t, err)
+
+	r := mp.Calls[0].Arguments[0].(plugins.Resource)
+	assert.Equal(t, "mock_saas", r.Type)
+	assert.Equal(t, "api", r.Name)
+	assert.Equal(t, "test", r.Module)
+
+	attrs := map[string]string{}
+	err = r.Decode(&attrs)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:9090", attrs["endpoint"])
+}
+
+func TestPluginCreateWithErrorReturnsError(t *testing.T) {
+	_, mp, p := setupPluginTests()
+	removeOn(&mp.Mock, "Create")
+	mp.On("Create", mock.Anything).Return(fmt.Errorf("boom"))
+
+	err := p.Create()
+	assert.Error(t, err)
+}
+
+func TestPluginDestroyCallsPlugin(t *testing.T) {
+	_, mp, p := setupPluginTests()
+
+	err := p.Destroy()
+	assert.NoError(t, err)
+
+	mp.AssertCalled(t, "Destroy", mock.Anything)
+}
+
+func TestPluginExistsUsesLookup(t *testing.T) {
+	_, mp, p := setupPluginTests()
+
+	ok, err := p.Exists()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	removeOn(&mp.Mock, "Lookup")
+	mp.On("Lookup", mock.Anything).Return(nil, nil)
+
+	ok, err = p.Exists()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -7,6 +7,22 @@ type Provider interface {
 	Lookup() ([]string, error)
 }
 
+// Updater is an optional interface which can be implemented by providers
+// that are able to modify an existing resource in place when the config changes.
+// When a provider does not implement Updater the resource is destroyed and
+// created again.
+type Updater interface {
+	Update() error
+}
+
+// Refresher is an optional interface which can be implemented by providers
+// that are able to check if the objects they created still exist. It is used
+// to detect resources which have been removed outside of Shipyard, providers which
+// do not implement Refresher are assumed to exist.
+type Refresher interface {
+	Exists() (bool, error)
+}
+
 // ConfigWrapper alows the provider config to be deserialized to a type
 type ConfigWrapper struct {
 	Type  string
@@ -25,6 +25,19 @@ func NewTemplate(c *config.Template, l hclog.Logger) *Template {
 // Create a new template
 func (c *Template) Create() error {
 	c.log.Info("Generating template", "ref", c.config.Name, "output", c.config.Destination)
+
+	return c.render()
+}
+
+// Update implements the Updater interface and renders the template,
+// replacing the existing destination file
+func (c *Template) Update() error {
+	c.log.Info("Updating template", "ref", c.config.Name, "output", c.config.Destination)
+
+	return c.render()
+}
+
+func (c *Template) render() error {
 	c.log.Debug("Template content", "ref", c.config.Name, "source", c.config.Source)
 
 	// check the template is valid
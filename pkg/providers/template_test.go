@@ -46,6 +46,23 @@ func TestTemplateProcessesCorrectly(t *testing.T) {
 	assert.Contains(t, string(d), `data_dir = "something"`)
 }
 
+func TestTemplateUpdateRendersChangedSource(t *testing.T) {
+	tmpl, provider := setupTemplate(t)
+
+	err := provider.Create()
+	assert.NoError(t, err)
+
+	tmpl.Source = "updated"
+
+	err = provider.Update()
+	assert.NoError(t, err)
+
+	d, err := ioutil.ReadFile(tmpl.Destination)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "updated", string(d))
+}
+
 func TestTemplateWriteSourceWhenNoVars(t *testing.T) {
 	tmpl, provider := setupTemplate(t)
 	provider.config.Vars = nil
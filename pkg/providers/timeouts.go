@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+)
+
+// clusterStartTimeout returns the time to wait for a cluster to start, when
+// the resource has a create timeout this is used instead of the default
+func clusterStartTimeout(t *config.Timeouts) time.Duration {
+	d, err := t.CreateDuration()
+	if err != nil || d == 0 {
+		return startTimeout
+	}
+
+	return d
+}
+
+// healthCheckTimeout returns the time to wait for a health check to pass, when
+// the resource has a create timeout this is used instead of the health check timeout
+func healthCheckTimeout(hc *config.HealthCheck, t *config.Timeouts) (time.Duration, error) {
+	d, err := t.CreateDuration()
+	if err != nil {
+		return 0, err
+	}
+
+	if d > 0 {
+		return d, nil
+	}
+
+	return time.ParseDuration(hc.Timeout)
+}
@@ -0,0 +1,74 @@
+package shipyard
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupStateBackend(t *testing.T, e Engine) string {
+	// copy the state from the default location to the backend
+	sc := config.New()
+	err := sc.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	sp := filepath.Join(t.TempDir(), "remote", "state.json")
+	err = sc.ToJSON(sp)
+	assert.NoError(t, err)
+
+	e.SetStateBackend(config.NewLocalStateBackend(sp))
+
+	return sp
+}
+
+func TestApplyUsesStateBackend(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	sp := setupStateBackend(t, e)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+
+	// the state is written to the backend and the lock is held in the backend
+	assert.FileExists(t, sp+".1")
+	assert.NoFileExists(t, utils.StatePath()+".1")
+
+	h, err := config.ReadStateLock(sp)
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
+
+func TestApplyWhenStateBackendLockedReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	sp := setupStateBackend(t, e)
+
+	l, err := config.LockState(sp, 0)
+	assert.NoError(t, err)
+	defer l.Unlock()
+
+	_, err = e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.IsType(t, config.StateLockedError{}, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestDestroyRemovesStateFromBackend(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	sp := setupStateBackend(t, e)
+
+	err := e.Destroy(context.Background(), "", true)
+	assert.NoError(t, err)
+
+	assert.NoFileExists(t, sp)
+	assert.FileExists(t, utils.StatePath())
+}
@@ -4,9 +4,9 @@ import (
 
 	// "fmt"
 
+	"context"
 	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -19,6 +19,7 @@ import (
 	// "github.com/mitchellh/mapstructure"
 	"github.com/shipyard-run/shipyard/pkg/clients"
 	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/plugins"
 	"github.com/shipyard-run/shipyard/pkg/providers"
 	"github.com/shipyard-run/shipyard/pkg/utils"
 )
@@ -37,6 +38,52 @@ type Clients struct {
 	Browser        clients.System
 	ImageLog       clients.ImageLog
 	Connector      clients.Connector
+	Plugins        plugins.Host
+
+	// ctx is used by providers which call the Docker SDK directly
+	ctx context.Context
+}
+
+// withContext returns a copy of the clients which use the given context,
+// cancelling the context aborts any in flight operations for clients
+// which support cancellation
+func (c *Clients) withContext(ctx context.Context) *Clients {
+	cl := *c
+	cl.ctx = ctx
+	cl.ContainerTasks = clients.ContainerTasksWithContext(ctx, c.ContainerTasks)
+	cl.Kubernetes = clients.KubernetesWithContext(ctx, c.Kubernetes)
+	cl.Helm = clients.HelmWithContext(ctx, c.Helm)
+	cl.Nomad = clients.NomadWithContext(ctx, c.Nomad)
+	cl.Command = clients.CommandWithContext(ctx, c.Command)
+
+	return &cl
+}
+
+// context returns the context set with withContext
+func (c *Clients) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// ApplyOptions defines optional settings which control how
+// configuration is applied
+type ApplyOptions struct {
+	// Targets restricts the apply to the given resources, i.e. container.api,
+	// and their transitive dependencies. Resources which are not targeted
+	// are not changed and retain their current status in the state.
+	Targets []string
+
+	// Refresh checks that the resources in the state still exist before
+	// applying, missing resources are created again
+	Refresh bool
+
+	// RollbackOnFailure destroys the resources which have been created by
+	// the apply when any resource fails. Resources which existed before the
+	// apply are not changed.
+	RollbackOnFailure bool
 }
 
 // Engine defines an interface for the Shipyard engine
@@ -47,22 +94,71 @@ type Engine interface {
 	// ApplyWithVariables applies a configuration file or directory containing
 	// configuraiton. Optionally the user can provide a map of variables which the configuraiton
 	// uses and / or a file containing variables.
-	ApplyWithVariables(path string, variables map[string]string, variablesFile string) ([]config.Resource, error)
+	// When ctx is cancelled no new resources are created, in flight operations are
+	// aborted and the state is saved.
+	ApplyWithVariables(ctx context.Context, path string, variables map[string]string, variablesFile string) ([]config.Resource, error)
+
+	// ApplyWithOptions applies a configuration file or directory in the same way as
+	// ApplyWithVariables, the given options control which resources are applied.
+	ApplyWithOptions(ctx context.Context, path string, variables map[string]string, variablesFile string, options ApplyOptions) ([]config.Resource, error)
+
+	// Plan reads the configuration and the current state and returns the actions
+	// which would be taken for each resource by ApplyWithVariables.
+	// Plan does not create, destroy, or modify any resources or the state.
+	Plan(path string, variables map[string]string, variablesFile string) (*Plan, error)
+
+	// Graph reads the configuration and the current state and returns the reduced
+	// dependency graph which determines the order resources are applied in.
+	// Graph does not create, destroy, or modify any resources or the state.
+	Graph(path string, variables map[string]string, variablesFile string) (*Graph, error)
+
 	ParseConfig(string) error
 	ParseConfigWithVariables(string, map[string]string, string) error
-	Destroy(string, bool) error
+	// Destroy the resources defined in the config at path or all resources in the state.
+	// When ctx is cancelled no new resources are destroyed, in flight operations are
+	// aborted and the state is saved.
+	Destroy(ctx context.Context, path string, allResources bool) error
+
+	// DestroyTargets destroys the resources in the state matching the given names,
+	// i.e. helm.vault, and any resources which depend on them. Other resources and
+	// their state are not changed.
+	DestroyTargets(ctx context.Context, targets []string) error
+
+	// Refresh checks that the resources in the state still exist, resources which
+	// have been removed outside of Shipyard are marked for creation and returned
+	// as drift.
+	Refresh(ctx context.Context) (*Drift, error)
 	ResourceCount() int
 	ResourceCountForType(string) int
 	Blueprint() *config.Blueprint
+
+	// Subscribe adds an observer which receives events as resources are created,
+	// updated, and destroyed. The returned function removes the observer.
+	Subscribe(o Observer) func()
+
+	// SetLockTimeout sets the time to wait for the state lock to be released
+	// when the state is locked by another process, the default is not to wait
+	SetLockTimeout(timeout time.Duration)
+
+	// SetStateBackend sets the backend used to store the state, this overrides
+	// the backend configured in the blueprint and the workspace
+	SetStateBackend(b config.StateBackend)
 }
 
 // EngineImpl is responsible for creating and destroying resources
 type EngineImpl struct {
-	clients     *Clients
-	config      *config.Config
-	log         hclog.Logger
-	getProvider getProviderFunc
-	sync        sync.Mutex
+	clients       *Clients
+	config        *config.Config
+	log           hclog.Logger
+	getProvider   getProviderFunc
+	sync          sync.Mutex
+	observers     []subscription
+	observerID    int
+	observerMutex sync.Mutex
+	lockTimeout   time.Duration
+	backend       config.StateBackend
+	state         config.StateBackend
+	pluginsOnce   sync.Once
 }
 
 // defines a function which is used for generating providers
@@ -97,6 +193,8 @@ func GenerateClients(l hclog.Logger) (*Clients, error) {
 	co := clients.DefaultConnectorOptions()
 	cc := clients.NewConnector(co)
 
+	ph := plugins.NewHost(l)
+
 	return &Clients{
 		ContainerTasks: ct,
 		Docker:         dc,
@@ -110,28 +208,128 @@ func GenerateClients(l hclog.Logger) (*Clients, error) {
 		Browser:        bc,
 		ImageLog:       il,
 		Connector:      cc,
+		Plugins:        ph,
 	}, nil
 }
 
 // New creates a new shipyard engine
 func New(l hclog.Logger) (Engine, error) {
-	var err error
+	// create the clients
+	cl, err := GenerateClients(l)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithClients(cl, l), nil
+}
+
+// NewWithClients creates a new shipyard engine which uses the given clients
+func NewWithClients(cl *Clients, l hclog.Logger) Engine {
 	e := &EngineImpl{}
 	e.log = l
 	e.getProvider = generateProviderImpl
+	e.clients = cl
 
 	// Set the standard writer to our logger as the DAG uses the standard library log.
 	log.SetOutput(l.StandardWriter(&hclog.StandardLoggerOptions{ForceLevel: hclog.Trace}))
 
-	// create the clients
-	cl, err := GenerateClients(l)
+	return e
+}
+
+// SetLockTimeout sets the time to wait for the state lock
+func (e *EngineImpl) SetLockTimeout(timeout time.Duration) {
+	e.lockTimeout = timeout
+}
+
+// SetStateBackend sets the backend used to store the state
+func (e *EngineImpl) SetStateBackend(b config.StateBackend) {
+	e.backend = b
+}
+
+// openState sets the backend used to store the state for the current operation.
+// The backend set with SetStateBackend is used when set, otherwise the backend
+// configured in the blueprint at path, or the backend for the workspace.
+// When save is true a backend configured in the blueprint is saved so that
+// commands which do not read the blueprint use the same backend.
+func (e *EngineImpl) openState(path string, save bool) error {
+	if e.backend != nil {
+		e.state = e.backend
+		return nil
+	}
+
+	if path != "" {
+		sc, err := config.ParseStateBackendConfig(path)
+		if err != nil {
+			return fmt.Errorf("Unable to read state backend config: %s", err)
+		}
+
+		if sc != nil {
+			if save {
+				err := config.SaveStateBackendConfig(sc)
+				if err != nil {
+					return err
+				}
+			}
+
+			e.state, err = sc.StateBackend()
+			return err
+		}
+	}
+
+	b, err := config.DefaultStateBackend()
+	if err != nil {
+		return err
+	}
+
+	e.state = b
+
+	return nil
+}
+
+// lockState acquires the lock for the state of the current operation,
+// the returned function releases the lock
+func (e *EngineImpl) lockState() (func(), error) {
+	l, err := e.state.Lock(e.lockTimeout)
 	if err != nil {
 		return nil, err
 	}
 
-	e.clients = cl
+	return func() {
+		err := l.Unlock()
+		if err != nil {
+			e.log.Error("Unable to release state lock", "error", err)
+		}
+	}, nil
+}
+
+// loadPlugins starts the plugins in the plugins folder and registers the resource
+// types they provide with the parser. Plugins are started the first time the engine
+// reads the config so commands which do not parse or apply config do not start them,
+// plugins which fail to load are logged and do not stop Shipyard from running.
+func (e *EngineImpl) loadPlugins() {
+	e.pluginsOnce.Do(func() {
+		if e.clients == nil || e.clients.Plugins == nil {
+			return
+		}
+
+		ph := e.clients.Plugins
+		err := ph.Load(utils.PluginsDir())
+		if err != nil {
+			e.log.Error("Unable to load plugins", "error", err)
+		}
+
+		for _, s := range ph.Schemas() {
+			ps := config.PluginSchema{Type: config.ResourceType(s.Type)}
+			for _, a := range s.Attributes {
+				ps.Attributes = append(ps.Attributes, config.PluginAttribute{Name: a.Name, Type: a.Type, Required: a.Required})
+			}
 
-	return e, nil
+			err := config.RegisterPluginType(ps)
+			if err != nil {
+				e.log.Error("Unable to register plugin resource type", "type", s.Type, "error", err)
+			}
+		}
+	})
 }
 
 // GetClients returns the clients from the engine
@@ -150,7 +348,12 @@ func (e *EngineImpl) ParseConfig(path string) error {
 // not apply or destroy the resources.
 // This function can be used to check the validity of a configuration without making changes
 func (e *EngineImpl) ParseConfigWithVariables(path string, vars map[string]string, variablesFile string) error {
-	_, err := e.readConfig(path, vars, variablesFile)
+	err := e.openState(path, false)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = e.readConfig(path, vars, variablesFile)
 	if err != nil {
 		return err
 	}
@@ -160,11 +363,17 @@ func (e *EngineImpl) ParseConfigWithVariables(path string, vars map[string]strin
 
 // Apply the configuration and create or destroy the resources
 func (e *EngineImpl) Apply(path string) ([]config.Resource, error) {
-	return e.ApplyWithVariables(path, nil, "")
+	return e.ApplyWithVariables(context.Background(), path, nil, "")
 }
 
 // ApplyWithVariables applies the current config creating the resources
-func (e *EngineImpl) ApplyWithVariables(path string, vars map[string]string, variablesFile string) ([]config.Resource, error) {
+func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars map[string]string, variablesFile string) ([]config.Resource, error) {
+	return e.ApplyWithOptions(ctx, path, vars, variablesFile, ApplyOptions{})
+}
+
+// ApplyWithOptions applies the current config creating the resources
+// filtered by the given options
+func (e *EngineImpl) ApplyWithOptions(ctx context.Context, path string, vars map[string]string, variablesFile string, options ApplyOptions) ([]config.Resource, error) {
 	// abs paths
 	var err error
 	path, err = filepath.Abs(path)
@@ -179,13 +388,63 @@ func (e *EngineImpl) ApplyWithVariables(path string, vars map[string]string, var
 		}
 	}
 
-	d, err := e.readConfig(path, vars, variablesFile)
+	err = e.openState(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := e.lockState()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if options.Refresh {
+		drift, err := e.refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range drift.Resources {
+			e.log.Info("Resource has drifted", "ref", d.Name, "type", d.Type, "message", d.Message)
+		}
+	}
+
+	d, _, err := e.readConfig(path, vars, variablesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the current state so that resources which are not targeted
+	// can retain their status
+	sc := config.New()
+	if len(options.Targets) > 0 {
+		err := sc.Load(e.state)
+		if err != nil && err != config.StateNotFoundError {
+			return nil, fmt.Errorf("Error parsing state: %s", err)
+		}
+	}
+
+	targets, err := e.findTargets(d, options.Targets)
 	if err != nil {
 		return nil, err
 	}
 
 	createdResource := []config.Resource{}
 
+	// resources which did not exist before this apply, in the order
+	// they were created, these are destroyed on rollback
+	newResources := []config.Resource{}
+
+	queued := []config.Resource{}
+	for _, r := range e.config.Resources {
+		if targets == nil || targets[r] {
+			queued = append(queued, r)
+		}
+	}
+
+	ev := e.newWalkEvents(queued)
+
 	// walk the dag and apply the config
 	w := dag.Walker{}
 	w.Callback = func(v dag.Vertex) (diags tfdiags.Diagnostics) {
@@ -197,51 +456,112 @@ func (e *EngineImpl) ApplyWithVariables(path string, vars map[string]string, var
 			return nil
 		}
 
+		// skip resources which have not been targeted
+		if targets != nil && !targets[r] {
+			return nil
+		}
+
+		// do not start any new resources once cancelled, the status
+		// is not changed so the resource is created on the next run
+		if ctx.Err() != nil {
+			ev.skipped(r, "", "operation cancelled")
+			return diags.Append(fmt.Errorf("Unable to create resource Name: %s, Type: %s, %s", r.Info().Name, r.Info().Type, ctx.Err()))
+		}
+
+		// expressions which reference other resources can only be
+		// evaluated once the referenced resources have been applied
+		if !r.Info().Disabled {
+			err := e.config.ResolveReferences(r)
+			if err != nil {
+				r.Info().Status = config.Failed
+				ev.failed(r, "", err)
+
+				return diags.Append(err)
+			}
+		}
+
+		// the timeout does not depend on the provider
+		timeout, err := resourceTimeout(r, planAction(r, nil))
+		if err != nil {
+			r.Info().Status = config.Failed
+			ev.failed(r, "", err)
+
+			return diags.Append(err)
+		}
+
+		rctx, cancel := resourceContext(ctx, timeout)
+		defer cancel()
+
 		// get the provider to create the resource
-		p := e.getProvider(r, e.clients)
+		cc := e.clients.withContext(rctx).forResource(e, r)
+		p := e.getProvider(r, cc)
 
 		if p == nil {
 			r.Info().Status = config.Failed
-			return diags.Append(fmt.Errorf("Unable to create provider for resource Name: %s, Type: %s", r.Info().Name, r.Info().Type))
+			err := fmt.Errorf("Unable to create provider for resource Name: %s, Type: %s", r.Info().Name, r.Info().Type)
+			ev.failed(r, "", err)
+
+			return diags.Append(err)
 		}
 
-		switch r.Info().Status {
-		// Normal case for PendingUpdate is do nothing
-		// PendingModification causes a resource to be
-		// destroyed before created
-		case config.PendingModification:
-			fallthrough
+		action := planAction(r, p)
+		switch action {
+		case PlanNoOp:
+			ev.skipped(r, action, "no changes")
+		case PlanDisabled:
+			ev.skipped(r, action, "resource is disabled")
+		default:
+			ev.started(r, action)
+		}
 
-			// Always attempt to destroy and re-create failed resources
-		case config.Failed:
-			err = p.Destroy()
-			if err != nil {
-				r.Info().Status = config.Failed
-				return diags.Append(err)
+		applyErr := waitForResource(ctx, rctx, r, action, timeout, func() error {
+			if action == PlanDestroy || action == PlanRecreate {
+				err := e.runPreDestroyHooks(r, r.Info().Status, cc)
+				if err != nil {
+					return err
+				}
 			}
 
-			fallthrough // failed resources should always attempt recreation
+			err := applyResource(r, p)
 
-		// Create new resources
-		case config.PendingCreation:
-			createErr := p.Create()
-			if createErr != nil {
-				r.Info().Status = config.Failed
-				return diags.Append(createErr)
+			// resources which are being created can be retried, updates are not
+			// retried as the retry destroys and re-creates the resource
+			if err != nil && (action == PlanCreate || action == PlanRecreate) {
+				err = e.retryResource(rctx, r, p, action, ev, err)
 			}
 
-		case config.PendingUpdate:
-			// do nothing for pending updates
+			if err == nil && (action == PlanCreate || action == PlanRecreate) {
+				err = e.runPostCreateHooks(r, cc)
+			}
+
+			return err
+		})
 
-		case config.Disabled:
-			// do nothing for disabled updates
+		// a resource which fails to be created may have been partially
+		// created so it is also destroyed on rollback, the image cache
+		// is always created and is shared so it is never rolled back
+		if action == PlanCreate && r.Info().Type != config.TypeImageCache {
+			appendResources(&newResources, r)
 		}
 
-		// set the status only if not disabled
-		if r.Info().Status != config.Disabled {
+		if applyErr != nil {
+			r.Info().Status = config.Failed
+			ev.failed(r, action, applyErr)
+
+			return diags.Append(applyErr)
+		}
+
+		// set the status only if not disabled, resources which have been
+		// disabled keep their status so that any pending changes are
+		// applied when the resource is enabled
+		if !r.Info().Disabled && r.Info().Status != config.Disabled {
 			r.Info().Status = config.Applied
 		}
 
+		if action != PlanNoOp && action != PlanDisabled {
+			ev.succeeded(r, action)
+		}
+
 		appendResources(&createdResource, r)
 
 		return nil
@@ -253,9 +573,34 @@ func (e *EngineImpl) ApplyWithVariables(path string, vars map[string]string, var
 		err = tf.Err()
 	}
 
+	ev.finish()
+
+	if err != nil && options.RollbackOnFailure {
+		if ctx.Err() != nil {
+			e.log.Info("Apply cancelled, created resources will not be rolled back")
+		} else if rerr := e.rollback(ctx, newResources); rerr != nil {
+			err = fmt.Errorf("%s, unable to roll back created resources: %s", err, rerr)
+		}
+	}
+
+	// resources which were not targeted retain the status and hash
+	// from the state so that any changes are applied on the next run
+	if targets != nil {
+		for _, r := range e.config.Resources {
+			if targets[r] {
+				continue
+			}
+
+			if sr, serr := sc.FindResource(fmt.Sprintf("%s.%s", r.Info().Type, r.Info().Name)); serr == nil {
+				r.Info().Status = sr.Info().Status
+				r.Info().Hash = sr.Info().Hash
+			}
+		}
+	}
+
 	if len(e.config.Resources) > 0 {
 		// save the state regardless of error
-		jerr := e.config.ToJSON(utils.StatePath())
+		jerr := e.config.Save(e.state)
 		if jerr != nil {
 			return createdResource, jerr
 		}
@@ -266,22 +611,288 @@ func (e *EngineImpl) ApplyWithVariables(path string, vars map[string]string, var
 	return nil, tf.Err()
 }
 
+// applyResource creates, updates, or destroys the resource depending on its status
+func applyResource(r config.Resource, p providers.Provider) error {
+	// disabled resources are not created or destroyed
+	if r.Info().Disabled {
+		return nil
+	}
+
+	switch r.Info().Status {
+	// PendingUpdate means the config has changed since the
+	// resource was created, providers which support in place
+	// updates are updated, all others are destroyed and re-created
+	case config.PendingUpdate:
+		if u, ok := p.(providers.Updater); ok {
+			return u.Update()
+		}
+
+		fallthrough
+
+	// PendingModification causes a resource to be
+	// destroyed before created
+	case config.PendingModification:
+		fallthrough
+
+		// Always attempt to destroy and re-create failed resources
+	case config.Failed:
+		err := p.Destroy()
+		if err != nil {
+			return err
+		}
+
+		fallthrough // failed resources should always attempt recreation
+
+	// Create new resources
+	case config.PendingCreation:
+		return p.Create()
+
+	case config.Applied:
+		// do nothing for resources which have not changed
+
+	case config.Disabled:
+		// do nothing for disabled updates
+	}
+
+	return nil
+}
+
+// retryResource destroys and re-creates a resource which has failed to be created
+// or re-created using the retry policy for the resource, when all attempts fail the
+// last error is returned
+func (e *EngineImpl) retryResource(ctx context.Context, r config.Resource, p providers.Provider, a PlanAction, ev *walkEvents, err error) error {
+	rp := r.Info().Retry
+	if rp == nil {
+		return err
+	}
+
+	for i := 1; i < rp.Attempts; i++ {
+		backoff, berr := rp.BackoffDuration(i)
+		if berr != nil {
+			return berr
+		}
+
+		e.log.Info("Retrying resource", "ref", fmt.Sprintf("%s.%s", r.Info().Type, r.Info().Name), "attempt", i+1, "backoff", backoff, "error", err)
+		ev.retry(r, a, fmt.Sprintf("attempt %d of %d in %s, %s", i+1, rp.Attempts, backoff, err))
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+
+		// remove anything which was partially created by the failed attempt
+		derr := p.Destroy()
+		if derr != nil {
+			e.log.Debug("Unable to destroy resource before retry", "ref", fmt.Sprintf("%s.%s", r.Info().Type, r.Info().Name), "error", derr)
+		}
+
+		err = p.Create()
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Plan returns the actions which will be taken for each resource when the
+// configuration at path is applied
+func (e *EngineImpl) Plan(path string, vars map[string]string, variablesFile string) (*Plan, error) {
+	var err error
+	if path != "" {
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if variablesFile != "" {
+		variablesFile, err = filepath.Abs(variablesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = e.openState(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	d, _, err := e.readConfig(path, vars, variablesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plan{Resources: []PlanItem{}}
+	pMutex := sync.Mutex{}
+
+	// walk the dag without calling the providers so that the resources
+	// in the plan are ordered by their dependencies
+	w := dag.Walker{}
+	w.Callback = func(v dag.Vertex) (diags tfdiags.Diagnostics) {
+		r, ok := v.(config.Resource)
+		if !ok {
+			return nil
+		}
+
+		pr := e.getProvider(r, e.clients)
+
+		pMutex.Lock()
+		defer pMutex.Unlock()
+
+		p.Resources = append(p.Resources, PlanItem{
+			Name:   r.Info().Name,
+			Type:   r.Info().Type,
+			Module: r.Info().Module,
+			Status: r.Info().Status,
+			Action: planAction(r, pr),
+		})
+
+		return nil
+	}
+
+	w.Update(d)
+	tf := w.Wait()
+	if tf.Err() != nil {
+		return nil, tf.Err()
+	}
+
+	return p, nil
+}
+
 // Destroy the resources defined by the config
-func (e *EngineImpl) Destroy(path string, allResources bool) error {
-	d, err := e.readConfig(path, nil, "")
+func (e *EngineImpl) Destroy(ctx context.Context, path string, allResources bool) error {
+	err := e.openState(path, false)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := e.lockState()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	d, cc, err := e.readConfig(path, nil, "")
+	if err != nil {
+		return err
+	}
+
+	// store the status of the resources before they are marked for
+	// destruction so that resources which are not destroyed can be restored
+	status := map[config.Resource]config.Status{}
 
 	// make sure we destroy everything
 	if allResources {
 		for _, i := range e.config.Resources {
-			if i.Info().Status != config.Disabled {
-				i.Info().Status = config.PendingUpdate
+			markForDestruction(i, status)
+		}
+	} else {
+		// only destroy the resources defined in the config which are in the state,
+		// failed resources and resources pending update may have been created.
+		// The image cache is shared with other configs and is not destroyed
+		sc := config.New()
+		err := sc.Load(e.state)
+		if err != nil && err != config.StateNotFoundError {
+			return fmt.Errorf("Error parsing state: %s", err)
+		}
+
+		for _, i := range cc.Resources {
+			if i.Info().Type == config.TypeImageCache {
+				continue
+			}
+
+			if _, err := sc.FindResource(fmt.Sprintf("%s.%s", i.Info().Type, i.Info().Name)); err == nil {
+				markForDestruction(i, status)
+			}
+		}
+	}
+
+	return e.destroy(ctx, d, status)
+}
+
+// DestroyTargets destroys the given resources and any resources which depend on them
+func (e *EngineImpl) DestroyTargets(ctx context.Context, targets []string) error {
+	err := e.openState("", false)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := e.lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	d, _, err := e.readConfig("", nil, "")
+	if err != nil {
+		return err
+	}
+
+	status := map[config.Resource]config.Status{}
+
+	for _, n := range targets {
+		// a target without an index destroys all the instances of a
+		// resource which uses count or for_each
+		instances, err := e.config.FindResourceInstances(n)
+		if err != nil {
+			return fmt.Errorf("Unable to find target: %s", err)
+		}
+
+		for _, r := range instances {
+			// edges point from a dependency to the resource which depends on it
+			// walking down the graph returns all the resources which depend on the target
+			dependents, err := d.Ancestors(r)
+			if err != nil {
+				return fmt.Errorf("Unable to find dependent resources for target %s: %s", n, err)
+			}
+
+			resources := []config.Resource{r}
+			for _, v := range dependents.List() {
+				if dr, ok := v.(config.Resource); ok {
+					resources = append(resources, dr)
+				}
+			}
+
+			for _, i := range resources {
+				if _, ok := status[i]; !ok {
+					markForDestruction(i, status)
+				}
 			}
 		}
 	}
 
+	return e.destroy(ctx, d, status)
+}
+
+// markForDestruction sets the status of the resource so that it is destroyed,
+// the original status is stored in status so that it can be restored should
+// the resource not be destroyed. Disabled resources are removed from the state
+// without calling the provider.
+func markForDestruction(r config.Resource, status map[config.Resource]config.Status) {
+	status[r] = r.Info().Status
+
+	if r.Info().Status != config.Disabled {
+		r.Info().Status = config.PendingUpdate
+	}
+}
+
+// destroy walks the dag in reverse order destroying the resources
+// contained in status, status holds the original status of the
+// resources which is restored should the resource not be destroyed
+func (e *EngineImpl) destroy(ctx context.Context, d *dag.AcyclicGraph, status map[config.Resource]config.Status) error {
+	var err error
+
+	queued := []config.Resource{}
+	for _, r := range e.config.Resources {
+		if _, ok := status[r]; ok {
+			queued = append(queued, r)
+		}
+	}
+
+	ev := e.newWalkEvents(queued)
+
 	// walk the dag and apply the config
 	w := dag.Walker{}
 	w.Reverse = true
@@ -289,6 +900,17 @@ func (e *EngineImpl) Destroy(path string, allResources bool) error {
 
 		// check if the resource needs to be created and if so create
 		if r, ok := v.(config.Resource); ok {
+			// only destroy resources which have been marked for destruction
+			if _, ok := status[r]; !ok {
+				return nil
+			}
+
+			// do not start destroying any new resources once cancelled
+			if ctx.Err() != nil && r.Info().Status == config.PendingUpdate {
+				ev.skipped(r, PlanDestroy, "operation cancelled")
+				return diags.Append(fmt.Errorf("Unable to destroy resource Name: %s, Type: %s, %s", r.Info().Name, r.Info().Type, ctx.Err()))
+			}
+
 			switch r.Info().Status {
 			case config.PendingUpdate:
 				// do nothing for disabled resources
@@ -297,24 +919,53 @@ func (e *EngineImpl) Destroy(path string, allResources bool) error {
 					return nil
 				}
 
+				timeout, terr := resourceTimeout(r, PlanDestroy)
+				if terr != nil {
+					r.Info().Status = config.Failed
+					ev.failed(r, PlanDestroy, terr)
+
+					return diags.Append(terr)
+				}
+
+				rctx, cancel := resourceContext(ctx, timeout)
+				defer cancel()
+
 				// get the provider to create the resource
-				p := e.getProvider(r, e.clients)
+				cc := e.clients.withContext(rctx).forResource(e, r)
+				p := e.getProvider(r, cc)
 				if p == nil {
 					r.Info().Status = config.Failed
-					return diags.Append(fmt.Errorf("Unable to create provider for resource Name: %s, Type: %s", r.Info().Name, r.Info().Type))
+					err := fmt.Errorf("Unable to create provider for resource Name: %s, Type: %s", r.Info().Name, r.Info().Type)
+					ev.failed(r, PlanDestroy, err)
+
+					return diags.Append(err)
 				}
 
+				ev.started(r, PlanDestroy)
+
 				// execute
-				destroyErr := p.Destroy()
+				destroyErr := waitForResource(ctx, rctx, r, PlanDestroy, timeout, func() error {
+					err := e.runPreDestroyHooks(r, status[r], cc)
+					if err != nil {
+						return err
+					}
+
+					return p.Destroy()
+				})
 				if destroyErr != nil {
 					r.Info().Status = config.Failed
+					ev.failed(r, PlanDestroy, destroyErr)
+
 					return diags.Append(destroyErr)
 				}
 
-				fallthrough
+				r.Info().Status = config.Destroyed
+				ev.succeeded(r, PlanDestroy)
+
 			case config.Disabled:
 				// set the status
 				r.Info().Status = config.Destroyed
+				ev.skipped(r, PlanDestroy, "resource is disabled")
 			}
 		}
 
@@ -327,9 +978,16 @@ func (e *EngineImpl) Destroy(path string, allResources bool) error {
 		err = tf.Err()
 	}
 
-	// remove any destroyed nodes from the state
+	ev.finish()
+
+	// remove any destroyed nodes from the state and restore the status
+	// of any resources which were not destroyed due to an error
 	cn := config.New()
 	for _, i := range e.config.Resources {
+		if s, ok := status[i]; ok && i.Info().Status == config.PendingUpdate {
+			i.Info().Status = s
+		}
+
 		if i.Info().Status != config.Destroyed {
 			cn.AddResource(i)
 		}
@@ -337,18 +995,65 @@ func (e *EngineImpl) Destroy(path string, allResources bool) error {
 
 	// save the state regardless of error
 	if len(cn.Resources) > 0 {
-		err = cn.ToJSON(utils.StatePath())
+		err = cn.Save(e.state)
 		if err != nil {
 			return err
 		}
 	} else {
-		// if no resources in the state delete
-		os.RemoveAll(utils.StatePath())
+		// if no resources in the state delete, the backend config
+		// is removed so the next run can use a different backend
+		err = e.state.Delete()
+		if err != nil {
+			return err
+		}
+
+		err = config.RemoveStateBackendConfig()
+		if err != nil {
+			return err
+		}
 	}
 
 	return tf.Err()
 }
 
+// findTargets returns the resources matching the given target names
+// along with their transitive dependencies, when no targets are specified
+// a nil map is returned.
+func (e *EngineImpl) findTargets(d *dag.AcyclicGraph, names []string) (map[config.Resource]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	targets := map[config.Resource]bool{}
+	for _, n := range names {
+		// targets without an index select all instances of
+		// resources created with count or for_each
+		rs, err := e.config.FindResourceInstances(n)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to find target: %s", err)
+		}
+
+		for _, r := range rs {
+			targets[r] = true
+
+			// edges point from a dependency to the resource which depends on it
+			// walking up the graph returns all the transitive dependencies
+			deps, err := d.Descendents(r)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to find dependencies for target %s: %s", n, err)
+			}
+
+			for _, v := range deps.List() {
+				if dr, ok := v.(config.Resource); ok {
+					targets[dr] = true
+				}
+			}
+		}
+	}
+
+	return targets, nil
+}
+
 // ResourceCount defines the number of resources in a plan
 func (e *EngineImpl) ResourceCount() int {
 	return e.config.ResourceCount()
@@ -364,19 +1069,23 @@ func (e *EngineImpl) Blueprint() *config.Blueprint {
 	return e.config.Blueprint
 }
 
-func (e *EngineImpl) readConfig(path string, variables map[string]string, variablesFile string) (*dag.AcyclicGraph, error) {
+// readConfig parses the config at the given path and merges it with the
+// current state, returns the dependency graph for the merged config and
+// the config which was parsed from path
+func (e *EngineImpl) readConfig(path string, variables map[string]string, variablesFile string) (*dag.AcyclicGraph, *config.Config, error) {
+	// plugin resource types must be registered before the state or config is parsed
+	e.loadPlugins()
+
 	// create the new config
 	cc := config.New()
 
 	// load the existing state
 	sc := config.New()
-	if _, err := os.Stat(utils.StatePath()); err == nil {
-		err := sc.FromJSON(utils.StatePath())
-		if err != nil {
-			return nil, fmt.Errorf("Error parsing state: %s", err)
-		}
-	} else {
+	err := sc.Load(e.state)
+	if err == config.StateNotFoundError {
 		e.log.Debug("Statefile does not exist")
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("Error parsing state: %s", err)
 	}
 
 	// check to see we have an image cache
@@ -397,17 +1106,20 @@ func (e *EngineImpl) readConfig(path string, variables map[string]string, variab
 		if utils.IsHCLFile(path) {
 			err := config.ParseSingleFile(path, cc, variables, variablesFile)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		} else {
 			err := config.ParseFolder(path, cc, false, "", false, []string{}, variables, variablesFile)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
 		// if we are loading from files create the deps
-		config.ParseReferences(cc)
+		err = config.ParseReferences(cc)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// merge the state and items to be created or deleted
@@ -419,17 +1131,17 @@ func (e *EngineImpl) readConfig(path string, variables map[string]string, variab
 	// build a DAG
 	d, err := e.config.DoYaLikeDAGs()
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to create dependency graph: %w", err)
+		return nil, nil, xerrors.Errorf("Unable to create dependency graph: %w", err)
 	}
 
 	d.TransitiveReduction()
 
 	err = d.Validate()
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to validate dependency graph: %w", err)
+		return nil, nil, xerrors.Errorf("Unable to validate dependency graph: %w", err)
 	}
 
-	return d, nil
+	return d, cc, nil
 }
 
 // generateProviderImpl returns providers grouped together in order of execution
@@ -466,13 +1178,19 @@ func generateProviderImpl(c config.Resource, cc *Clients) providers.Provider {
 	case config.TypeNomadJob:
 		return providers.NewNomadJob(c.(*config.NomadJob), cc.Nomad, cc.Logger)
 	case config.TypeNetwork:
-		return providers.NewNetwork(c.(*config.Network), cc.Docker, cc.Logger)
+		return providers.NewNetwork(c.(*config.Network), cc.Docker, cc.Logger).WithContext(cc.context())
 	case config.TypeOutput:
 		return providers.NewNull(c.Info(), cc.Logger)
 	case config.TypeTemplate:
 		return providers.NewTemplate(c.(*config.Template), cc.Logger)
 	}
 
+	if pr, ok := c.(*config.PluginResource); ok && cc.Plugins != nil {
+		if p, ok := cc.Plugins.Provider(string(pr.Type)); ok {
+			return providers.NewPlugin(pr, p, cc.Logger)
+		}
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package shipyard
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -10,12 +11,15 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/hashicorp/go-hclog"
+	clients "github.com/shipyard-run/shipyard/pkg/clients/mocks"
 	"github.com/shipyard-run/shipyard/pkg/config"
 	"github.com/shipyard-run/shipyard/pkg/providers"
 	"github.com/shipyard-run/shipyard/pkg/providers/mocks"
 	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/stretchr/testify/mock"
 
 	assert "github.com/stretchr/testify/require"
 )
@@ -80,9 +84,20 @@ func generateProviderMock(mp *[]*mocks.MockProvider, returnVals map[string]error
 		lock.Lock()
 		defer lock.Unlock()
 
-		m := mocks.New(c)
-
 		val := returnVals[c.Info().Name]
+
+		// helm resources support in place updates
+		if c.Info().Type == config.TypeHelm {
+			m := mocks.NewUpdater(c)
+			m.On("Create").Return(val)
+			m.On("Destroy").Return(val)
+			m.On("Update").Return(val)
+
+			*mp = append(*mp, m.MockProvider)
+			return m
+		}
+
+		m := mocks.New(c)
 		m.On("Create").Return(val)
 		m.On("Destroy").Return(val)
 
@@ -145,7 +160,7 @@ func TestApplyWithSingleFileAndVariables(t *testing.T) {
 	e, mp, cleanup := setupTests(nil)
 	defer cleanup()
 
-	_, err := e.ApplyWithVariables("../../examples/single_file/container.hcl", nil, "../../examples/single_file/default.vars")
+	_, err := e.ApplyWithVariables(context.Background(), "../../examples/single_file/container.hcl", nil, "../../examples/single_file/default.vars")
 	assert.NoError(t, err)
 
 	assert.Equal(t, "onprem", (*mp)[0].Config().Info().Name)
@@ -242,6 +257,91 @@ func TestApplyReturnsErrorWhenProviderDestroyForResourcesPendingorFailed(t *test
 	testAssertMethodCalled(t, mp, "Create", 1) // ImageCache is always created
 }
 
+func setupRetryTests(t *testing.T, failures int) (Engine, *mocks.MockProvider) {
+	e, _, cleanup := setupTestsWithState(nil, retryState)
+	t.Cleanup(cleanup)
+
+	m := &mocks.MockProvider{}
+	e.(*EngineImpl).getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		if c.Info().Name != "dc1" {
+			ok := mocks.New(c)
+			ok.On("Create").Return(nil)
+			return ok
+		}
+
+		*m = *mocks.New(c)
+		if failures > 0 {
+			m.On("Create").Return(fmt.Errorf("boom")).Times(failures)
+		}
+		m.On("Create").Return(nil)
+		m.On("Destroy").Return(nil)
+
+		return m
+	}
+
+	return e, m
+}
+
+func TestApplyRetriesFailedResources(t *testing.T) {
+	e, m := setupRetryTests(t, 2)
+
+	_, err := e.Apply("")
+	assert.NoError(t, err)
+
+	m.AssertNumberOfCalls(t, "Create", 3)
+	m.AssertNumberOfCalls(t, "Destroy", 2)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestApplyRetryFailsWhenAttemptsExceeded(t *testing.T) {
+	e, m := setupRetryTests(t, 3)
+
+	_, err := e.Apply("")
+	assert.Error(t, err)
+
+	m.AssertNumberOfCalls(t, "Create", 3)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+}
+
+func TestApplyDoesNotRetryFailedUpdates(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, retryUpdateState)
+	t.Cleanup(cleanup)
+
+	m := mocks.NewUpdater(config.NewNetwork("dc1"))
+	e.(*EngineImpl).getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		if c.Info().Name != "dc1" {
+			ok := mocks.New(c)
+			ok.On("Create").Return(nil)
+			return ok
+		}
+
+		m.On("Update").Return(fmt.Errorf("boom"))
+		m.On("Create").Return(nil)
+		m.On("Destroy").Return(nil)
+
+		return m
+	}
+
+	_, err := e.Apply("")
+	assert.Error(t, err)
+
+	m.AssertNumberOfCalls(t, "Update", 1)
+	m.AssertNotCalled(t, "Destroy")
+	m.AssertNotCalled(t, "Create")
+}
+
 func TestApplyCallsProviderGenerateErrorStopsExecution(t *testing.T) {
 	e, mp, cleanup := setupTests(map[string]error{"cloud": fmt.Errorf("boom")})
 	defer cleanup()
@@ -271,8 +371,377 @@ func TestApplySetsStatusForEachResource(t *testing.T) {
 	_, err := e.Apply("")
 	assert.NoError(t, err)
 
-	// should only call create and destroy for the cache as this is pending update
+	// should only call create and destroy for the cache as this is applied
+	testAssertMethodCalled(t, mp, "Create", 1) // ImageCache is always created
+}
+
+func TestApplyCallsProviderDestroyAndCreateForResourcesPendingUpdate(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, updateState)
+	defer cleanup()
+
+	_, err := e.Apply("")
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+	testAssertMethodCalled(t, mp, "Create", 2) // ImageCache is always created
+}
+
+func TestApplyCallsProviderUpdateForResourcesPendingUpdateWhenSupported(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, updateHelmState)
+	defer cleanup()
+
+	_, err := e.Apply("")
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Update", 1)
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+	testAssertMethodCalled(t, mp, "Create", 1) // ImageCache is always created
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("helm.vault")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestApplyUpdateErrorSetsStatusFailed(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(map[string]error{"vault": fmt.Errorf("boom")}, updateHelmState)
+	defer cleanup()
+
+	_, err := e.Apply("")
+	assert.Error(t, err)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("helm.vault")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+}
+
+func TestApplyRecreatesResourcesWhenConfigChanged(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+	testAssertMethodCalled(t, mp, "Create", 3)
+
+	// apply again with a different image for the container
+	*mp = []*mocks.MockProvider{}
+	_, err = e.ApplyWithVariables(context.Background(), "../../examples/single_file/container.hcl", map[string]string{"version": "consul:1.8.1"}, "")
+	assert.NoError(t, err)
+
+	// only the container should be re-created, the image cache is always created
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+	testAssertMethodCalled(t, mp, "Create", 2)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+
+	h, _ := config.HashResource(r)
+	assert.Equal(t, h, r.Info().Hash)
+}
+
+func TestApplyDoesNotRecreateResourcesWhenConfigUnchanged(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+
+	*mp = []*mocks.MockProvider{}
+	_, err = e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+	testAssertMethodCalled(t, mp, "Create", 1) // ImageCache is always created
+}
+
+func TestApplyResolvesResourceReferences(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "config.hcl"), []byte(referencesConfig), os.ModePerm)
+
+	// set the runtime attributes for the database when the provider is created
+	gp := e.(*EngineImpl).getProvider
+	e.(*EngineImpl).getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		if db, ok := c.(*config.Container); ok && db.Name == "db" {
+			db.IPAddress = "10.5.0.2"
+		}
+
+		return gp(c, cc)
+	}
+
+	_, err := e.Apply(dir)
+	assert.NoError(t, err)
+	testAssertMethodCalled(t, mp, "Create", 4)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("container.web")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.5.0.2", r.(*config.Container).EnvVar["DB_ADDR"])
+	assert.Contains(t, r.Info().DependsOn, "container.db")
+
+	r, err = c.FindResource("output.db_addr")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.5.0.2:5432", r.(*config.Output).Value)
+}
+
+func TestApplyWithCountCreatesNetworksWithProviderNames(t *testing.T) {
+	e, _, cleanup := setupTests(nil)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "config.hcl"), []byte(networkCountConfig), os.ModePerm)
+
+	md := &clients.MockDocker{}
+	md.On("NetworkList", mock.Anything, mock.Anything).Return([]types.NetworkResource{}, nil)
+	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Return(types.NetworkCreateResponse{}, nil)
+
+	// use the network provider so that the Docker network names can be checked
+	gp := e.(*EngineImpl).getProvider
+	e.(*EngineImpl).getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		if n, ok := c.(*config.Network); ok {
+			return providers.NewNetwork(n, md, hclog.NewNullLogger())
+		}
+
+		return gp(c, cc)
+	}
+
+	_, err := e.Apply(dir)
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "NetworkCreate", mock.Anything, "cloud-0", mock.Anything)
+	md.AssertCalled(t, "NetworkCreate", mock.Anything, "cloud-1", mock.Anything)
+
+	// the state uses the config address for the instances
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("network.cloud[1]")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.6.0.0/16", r.(*config.Network).Subnet)
+}
+
+func TestApplyWithMissingReferenceReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "config.hcl"), []byte(missingReferenceConfig), os.ModePerm)
+
+	_, err := e.Apply(dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "network.missing")
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestApplyDoesNotDestroyResourcesDisabledAfterCreation(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, disabledAfterCreationState)
+	defer cleanup()
+
+	_, err := e.Apply("")
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
 	testAssertMethodCalled(t, mp, "Create", 1) // ImageCache is always created
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingUpdate, r.Info().Status)
+}
+
+func TestApplyCreatesResourcesWhichHaveBeenEnabled(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, enabledState)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "config.hcl"), []byte(enabledConfig), os.ModePerm)
+
+	_, err := e.Apply(dir)
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+	testAssertMethodCalled(t, mp, "Create", 2) // ImageCache is always created
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestApplyWithCancelledContextDoesNotCreateResources(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := e.ApplyWithVariables(ctx, "../../examples/single_file/container.hcl", nil, "")
+	assert.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+
+	// state should be saved with the resources still pending
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingCreation, r.Info().Status)
+}
+
+func TestApplyWithTargetsCreatesTargetAndDependencies(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.ApplyWithOptions(context.Background(), "../../examples/single_k3s_cluster", nil, "", ApplyOptions{Targets: []string{"helm.vault"}})
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 4)
+
+	created := []string{}
+	for _, m := range *mp {
+		created = append(created, m.Config().Info().Name)
+	}
+
+	assert.ElementsMatch(t, []string{"docker-cache", "cloud", "k3s", "vault"}, created)
+
+	// resources which are not targeted should not be changed
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	r, err := c.FindResource("helm.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingCreation, r.Info().Status)
+}
+
+func TestApplyWithTargetsRetainsStatusForResourcesNotTargeted(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+
+	// change the container and apply only the network
+	*mp = []*mocks.MockProvider{}
+	_, err = e.ApplyWithOptions(
+		context.Background(),
+		"../../examples/single_file/container.hcl",
+		map[string]string{"version": "consul:1.8.1"},
+		"",
+		ApplyOptions{Targets: []string{"network.onprem"}},
+	)
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	// the container should still be applied with the original hash so
+	// that the change is detected on the next run
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+
+	h, _ := config.HashResource(r)
+	assert.NotEqual(t, h, r.Info().Hash)
+}
+
+func TestApplyWithUnknownTargetReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.ApplyWithOptions(context.Background(), "../../examples/single_file/container.hcl", nil, "", ApplyOptions{Targets: []string{"container.missing"}})
+	assert.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestPlanReturnsCreateForNewResources(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	p, err := e.Plan("../../examples/single_file/container.hcl", nil, "")
+	assert.NoError(t, err)
+
+	assert.Len(t, p.Resources, 3)
+	assert.Equal(t, 3, p.Count(PlanCreate))
+
+	// network must be planned before the container which depends on it
+	assert.Equal(t, "onprem", p.Resources[0].Name)
+
+	// should not have called any providers
+	testAssertMethodCalled(t, mp, "Create", 0)
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
+func TestPlanReturnsActionsForState(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, planState)
+	defer cleanup()
+
+	p, err := e.Plan("", nil, "")
+	assert.NoError(t, err)
+
+	actions := map[string]PlanAction{}
+	for _, r := range p.Resources {
+		actions[fmt.Sprintf("%s.%s", r.Type, r.Name)] = r.Action
+	}
+
+	assert.Equal(t, PlanCreate, actions["image_cache.docker-cache"])
+	assert.Equal(t, PlanRecreate, actions["network.failed"])
+	assert.Equal(t, PlanRecreate, actions["network.tainted"])
+	assert.Equal(t, PlanNoOp, actions["network.applied"])
+	assert.Equal(t, PlanDisabled, actions["network.disabled_applied"])
+	assert.Equal(t, PlanDisabled, actions["container.disabled"])
+}
+
+func TestPlanReturnsUpdateWhenProviderSupportsUpdate(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, updateHelmState)
+	defer cleanup()
+
+	p, err := e.Plan("", nil, "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, p.Count(PlanUpdate))
+	assert.Equal(t, 0, p.Count(PlanRecreate))
+}
+
+func TestPlanDoesNotModifyState(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, planState)
+	defer cleanup()
+
+	_, err := e.Plan("", nil, "")
+	assert.NoError(t, err)
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("network.failed")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+
+	_, err = c.FindResource("image_cache.docker-cache")
+	assert.Error(t, err)
 }
 
 func TestParseConfig(t *testing.T) {
@@ -307,7 +776,7 @@ func TestDestroyCallsProviderDestroyForEachProvider(t *testing.T) {
 	e, mp, cleanup := setupTests(nil)
 	defer cleanup()
 
-	err := e.Destroy("../../examples/single_k3s_cluster", true)
+	err := e.Destroy(context.Background(), "../../examples/single_k3s_cluster", true)
 	assert.NoError(t, err)
 
 	// should have call create for each provider
@@ -318,7 +787,7 @@ func TestDestroyNotCallsProviderDestroyForResourcesDisabled(t *testing.T) {
 	e, mp, cleanup := setupTestsWithState(nil, disabledState)
 	defer cleanup()
 
-	err := e.Destroy("", true)
+	err := e.Destroy(context.Background(), "", true)
 	assert.NoError(t, err)
 
 	// should have call create for each provider
@@ -333,11 +802,171 @@ func TestDestroyNotCallsProviderDestroyForResourcesDisabled(t *testing.T) {
 	assert.Error(t, err) // resource should not exist
 }
 
+func TestDestroyWithCancelledContextRestoresStatus(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, mergedState)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := e.Destroy(ctx, "", true)
+	assert.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestDestroyConfigDestroysResourcesInStateWithAnyStatus(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_k3s_cluster")
+	assert.NoError(t, err)
+
+	// mark one resource as failed and change the hash of another
+	// so that it is pending update when the config is read
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, _ := c.FindResource("helm.vault")
+	r.Info().Status = config.Failed
+
+	r, _ = c.FindResource("helm.consul")
+	r.Info().Hash = "changed"
+
+	err = c.ToJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	*mp = []*mocks.MockProvider{}
+	err = e.Destroy(context.Background(), "../../examples/single_k3s_cluster", false)
+	assert.NoError(t, err)
+
+	destroyed := []string{}
+	for _, m := range *mp {
+		destroyed = append(destroyed, fmt.Sprintf("%s.%s", m.Config().Info().Type, m.Config().Info().Name))
+	}
+
+	assert.Contains(t, destroyed, "helm.vault")
+	assert.Contains(t, destroyed, "helm.consul")
+	assert.NotContains(t, destroyed, "image_cache.docker-cache")
+	testAssertMethodCalled(t, mp, "Destroy", 7)
+}
+
+func TestDestroyTargetsDestroysTargetAndDependents(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_k3s_cluster")
+	assert.NoError(t, err)
+
+	*mp = []*mocks.MockProvider{}
+	err = e.DestroyTargets(context.Background(), []string{"k8s_cluster.k3s"})
+	assert.NoError(t, err)
+
+	destroyed := []string{}
+	for _, m := range *mp {
+		destroyed = append(destroyed, fmt.Sprintf("%s.%s", m.Config().Info().Type, m.Config().Info().Name))
+	}
+
+	assert.NotContains(t, destroyed, "network.cloud")
+	assert.NotContains(t, destroyed, "image_cache.docker-cache")
+	assert.Contains(t, destroyed, "k8s_cluster.k3s")
+	assert.Contains(t, destroyed, "helm.vault")
+
+	// the cluster must be destroyed after the resources which depend on it
+	assert.Equal(t, "k3s", (*mp)[len(*mp)-1].Config().Info().Name)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	assert.Len(t, c.Resources, 2)
+
+	r, err := c.FindResource("network.cloud")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestDestroyTargetsDoesNotDestroyDependencies(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	_, err := e.Apply("../../examples/single_k3s_cluster")
+	assert.NoError(t, err)
+
+	*mp = []*mocks.MockProvider{}
+	err = e.DestroyTargets(context.Background(), []string{"helm.vault"})
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+	assert.Equal(t, "vault", (*mp)[0].Config().Info().Name)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	_, err = c.FindResource("helm.vault")
+	assert.Error(t, err)
+
+	r, err := c.FindResource("k8s_cluster.k3s")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestDestroyTargetsWithoutIndexDestroysAllInstances(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "config.hcl"), []byte(countConfig), os.ModePerm)
+
+	_, err := e.Apply(dir)
+	assert.NoError(t, err)
+
+	*mp = []*mocks.MockProvider{}
+	err = e.DestroyTargets(context.Background(), []string{"container.consul"})
+	assert.NoError(t, err)
+
+	destroyed := []string{}
+	for _, m := range *mp {
+		destroyed = append(destroyed, fmt.Sprintf("%s.%s", m.Config().Info().Type, m.Config().Info().Name))
+	}
+
+	assert.ElementsMatch(t, []string{"container.consul[0]", "container.consul[1]"}, destroyed)
+	testAssertMethodCalled(t, mp, "Destroy", 2)
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	_, err = c.FindResourceInstances("container.consul")
+	assert.Error(t, err)
+
+	r, err := c.FindResource("container.vault")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestDestroyTargetsWithUnknownTargetReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, mergedState)
+	defer cleanup()
+
+	err := e.DestroyTargets(context.Background(), []string{"helm.missing"})
+	assert.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
 func TestDestroyCallsProviderGenerateErrorStopsExecution(t *testing.T) {
 	e, mp, cleanup := setupTests(map[string]error{"k3s": fmt.Errorf("boom")})
 	defer cleanup()
 
-	err := e.Destroy("../../examples/single_k3s_cluster", true)
+	err := e.Destroy(context.Background(), "../../examples/single_k3s_cluster", true)
 	assert.Error(t, err)
 
 	// should have call create for each provider
@@ -348,7 +977,7 @@ func TestDestroyFailSetsStatus(t *testing.T) {
 	e, mp, cleanup := setupTests(map[string]error{"cloud": fmt.Errorf("boom")})
 	defer cleanup()
 
-	err := e.Destroy("../../examples/single_k3s_cluster", true)
+	err := e.Destroy(context.Background(), "../../examples/single_k3s_cluster", true)
 	assert.Error(t, err)
 
 	// should have call create for each provider
@@ -360,7 +989,7 @@ func TestDestroyCallsProviderDestroyInCorrectOrder(t *testing.T) {
 	e, mp, cleanup := setupTests(nil)
 	defer cleanup()
 
-	err := e.Destroy("../../examples/single_k3s_cluster", true)
+	err := e.Destroy(context.Background(), "../../examples/single_k3s_cluster", true)
 	assert.NoError(t, err)
 
 	// due to paralel nature of the DAG, these elements can appear in any order
@@ -427,7 +1056,7 @@ var mergedState = `
   "resources": [
 	{
       "name": "dc1",
-      "status": "pending_update",
+      "status": "applied",
       "subnet": "10.15.0.0/16",
       "type": "network"
 	}
@@ -453,3 +1082,199 @@ var disabledState = `
   ]
 }
 `
+
+var disabledAfterCreationState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_update",
+      "disabled": true,
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	}
+  ]
+}
+`
+
+var enabledState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "disabled",
+      "disabled": true,
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	}
+  ]
+}
+`
+
+var enabledConfig = `
+network "dc1" {
+  subnet = "10.15.0.0/16"
+}
+`
+
+var planState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "failed",
+      "status": "failed",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "tainted",
+      "status": "pending_modification",
+      "subnet": "10.16.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "applied",
+      "status": "applied",
+      "subnet": "10.17.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "disabled_applied",
+      "status": "applied",
+      "disabled": true,
+      "subnet": "10.18.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "disabled",
+      "status": "disabled",
+      "type": "container"
+	}
+  ]
+}
+`
+
+var updateState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_update",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	}
+  ]
+}
+`
+
+var updateHelmState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "vault",
+      "status": "pending_update",
+      "cluster": "k8s_cluster.k3s",
+      "chart": "./charts/vault",
+      "type": "helm"
+	}
+  ]
+}
+`
+
+var retryState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_creation",
+      "subnet": "10.15.0.0/16",
+      "type": "network",
+      "retry": {
+        "attempts": 3,
+        "backoff": "1ms"
+      }
+	}
+  ]
+}
+`
+
+var retryUpdateState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_update",
+      "subnet": "10.15.0.0/16",
+      "type": "network",
+      "retry": {
+        "attempts": 3,
+        "backoff": "1ms"
+      }
+	}
+  ]
+}
+`
+
+var networkCountConfig = `
+network "cloud" {
+  count  = 2
+  subnet = "10.${count.index + 5}.0.0/16"
+}
+`
+
+var countConfig = `
+container "consul" {
+  count = 2
+
+  image {
+    name = "consul:1.8.1"
+  }
+}
+
+container "vault" {
+  image {
+    name = "vault:1.6.1"
+  }
+}
+`
+
+var referencesConfig = `
+container "db" {
+  image {
+    name = "postgres:13"
+  }
+}
+
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  env_var = {
+    DB_ADDR = resource.container.db.ip_address
+  }
+}
+
+output "db_addr" {
+  value = "${resource.container.db.ip_address}:5432"
+}
+`
+
+var missingReferenceConfig = `
+container "web" {
+  image {
+    name = "web:1.0"
+  }
+
+  network {
+    name = network.missing
+  }
+}
+`
@@ -0,0 +1,286 @@
+package shipyard
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	"github.com/shipyard-run/shipyard/pkg/config"
+)
+
+// EventType defines the type of an event emitted by the engine
+type EventType string
+
+// EventResourceQueued is emitted for every resource which will be processed
+// by an apply or destroy before any resources are started
+const EventResourceQueued EventType = "resource_queued"
+
+// EventResourceStarted is emitted when the engine starts to create,
+// update, or destroy a resource
+const EventResourceStarted EventType = "resource_started"
+
+// EventResourceSucceeded is emitted when a resource has been successfully
+// created, updated, or destroyed
+const EventResourceSucceeded EventType = "resource_succeeded"
+
+// EventResourceFailed is emitted when the provider for a resource returns an error
+const EventResourceFailed EventType = "resource_failed"
+
+// EventResourceSkipped is emitted when a resource does not need to be changed
+// or could not be processed because a dependency failed or the operation was cancelled
+const EventResourceSkipped EventType = "resource_skipped"
+
+// EventResourceRetry is emitted when a resource has failed and
+// will be retried using the retry policy for the resource
+const EventResourceRetry EventType = "resource_retry"
+
+// EventHealthCheck is emitted when a health check for a resource starts
+// and when it completes
+const EventHealthCheck EventType = "health_check"
+
+// EventLifecycleHook is emitted when a lifecycle hook for a resource
+// starts and when it completes
+const EventLifecycleHook EventType = "lifecycle_hook"
+
+// Event is emitted by the engine to report the progress of an operation
+type Event struct {
+	Type         EventType           `json:"type"`
+	Time         time.Time           `json:"time"`
+	ResourceType config.ResourceType `json:"resource_type"`
+	Name         string              `json:"name"`
+	Module       string              `json:"module,omitempty"`
+	// Action is the action which is being performed on the resource
+	Action PlanAction `json:"action,omitempty"`
+	// Message contains human readable details for the event
+	Message string `json:"message,omitempty"`
+	// Error is set for failed events and failed health checks
+	Error error `json:"-"`
+}
+
+// Address returns the address of the resource i.e. container.consul
+func (e Event) Address() string {
+	return fmt.Sprintf("%s.%s", e.ResourceType, e.Name)
+}
+
+// Observer receives events emitted by the engine, OnEvent is called
+// synchronously from the goroutine processing the resource and should
+// return quickly.
+type Observer interface {
+	OnEvent(e Event)
+}
+
+// ObserverFunc allows a function to be used as an Observer
+type ObserverFunc func(e Event)
+
+// OnEvent calls the function with the event
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+type subscription struct {
+	id       int
+	observer Observer
+}
+
+// Subscribe adds an observer which receives the events emitted by the engine,
+// the returned function removes the observer.
+func (e *EngineImpl) Subscribe(o Observer) func() {
+	e.observerMutex.Lock()
+	defer e.observerMutex.Unlock()
+
+	e.observerID++
+	id := e.observerID
+	e.observers = append(e.observers, subscription{id, o})
+
+	return func() {
+		e.observerMutex.Lock()
+		defer e.observerMutex.Unlock()
+
+		for i, s := range e.observers {
+			if s.id == id {
+				e.observers = append(e.observers[:i], e.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit sends the event to all observers
+func (e *EngineImpl) emit(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	e.observerMutex.Lock()
+	observers := make([]Observer, len(e.observers))
+	for i, s := range e.observers {
+		observers[i] = s.observer
+	}
+	e.observerMutex.Unlock()
+
+	for _, o := range observers {
+		o.OnEvent(ev)
+	}
+}
+
+// emitResource sends an event for the given resource to all observers
+func (e *EngineImpl) emitResource(t EventType, r config.Resource, a PlanAction, message string, err error) {
+	e.emit(Event{
+		Type:         t,
+		ResourceType: r.Info().Type,
+		Name:         r.Info().Name,
+		Module:       r.Info().Module,
+		Action:       a,
+		Message:      message,
+		Error:        err,
+	})
+}
+
+// walkEvents emits the events for a single walk of the graph, it tracks
+// which resources have completed so that resources which are never reached
+// can be reported as skipped.
+type walkEvents struct {
+	e       *EngineImpl
+	mutex   sync.Mutex
+	pending map[config.Resource]bool
+}
+
+// newWalkEvents emits a queued event for each of the resources
+func (e *EngineImpl) newWalkEvents(resources []config.Resource) *walkEvents {
+	w := &walkEvents{e: e, pending: map[config.Resource]bool{}}
+
+	for _, r := range resources {
+		w.pending[r] = true
+		e.emitResource(EventResourceQueued, r, "", "", nil)
+	}
+
+	return w
+}
+
+func (w *walkEvents) started(r config.Resource, a PlanAction) {
+	w.e.emitResource(EventResourceStarted, r, a, "", nil)
+}
+
+func (w *walkEvents) succeeded(r config.Resource, a PlanAction) {
+	w.complete(r)
+	w.e.emitResource(EventResourceSucceeded, r, a, "", nil)
+}
+
+func (w *walkEvents) failed(r config.Resource, a PlanAction, err error) {
+	w.complete(r)
+	w.e.emitResource(EventResourceFailed, r, a, err.Error(), err)
+}
+
+func (w *walkEvents) skipped(r config.Resource, a PlanAction, message string) {
+	w.complete(r)
+	w.e.emitResource(EventResourceSkipped, r, a, message, nil)
+}
+
+func (w *walkEvents) retry(r config.Resource, a PlanAction, message string) {
+	w.e.emitResource(EventResourceRetry, r, a, message, nil)
+}
+
+// finish emits skipped events for any resources which were queued
+// but not processed
+func (w *walkEvents) finish() {
+	w.mutex.Lock()
+	pending := []config.Resource{}
+	for r := range w.pending {
+		pending = append(pending, r)
+	}
+	w.mutex.Unlock()
+
+	for _, r := range pending {
+		w.skipped(r, "", "dependency failed or operation cancelled")
+	}
+}
+
+func (w *walkEvents) complete(r config.Resource) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	delete(w.pending, r)
+}
+
+// forResource returns a copy of the clients where the health check
+// methods emit events for the given resource
+func (c *Clients) forResource(e *EngineImpl, r config.Resource) *Clients {
+	cl := *c
+
+	if c.HTTP != nil {
+		cl.HTTP = &healthCheckHTTP{c.HTTP, e, r}
+	}
+
+	if c.Kubernetes != nil {
+		cl.Kubernetes = &healthCheckKubernetes{c.Kubernetes, e, r}
+	}
+
+	if c.Nomad != nil {
+		cl.Nomad = &healthCheckNomad{c.Nomad, e, r}
+	}
+
+	return &cl
+}
+
+// healthCheck emits events before and after the health check function is run
+func (e *EngineImpl) healthCheck(r config.Resource, name string, f func() error) error {
+	e.emitResource(EventHealthCheck, r, "", fmt.Sprintf("Checking %s", name), nil)
+
+	err := f()
+	if err != nil {
+		e.emitResource(EventHealthCheck, r, "", fmt.Sprintf("Health check failed for %s", name), err)
+		return err
+	}
+
+	e.emitResource(EventHealthCheck, r, "", fmt.Sprintf("%s is healthy", name), nil)
+	return nil
+}
+
+type healthCheckHTTP struct {
+	clients.HTTP
+	e *EngineImpl
+	r config.Resource
+}
+
+func (h *healthCheckHTTP) HealthCheckHTTP(uri string, codes []int, timeout time.Duration) error {
+	return h.e.healthCheck(h.r, fmt.Sprintf("HTTP endpoint %s", uri), func() error {
+		return h.HTTP.HealthCheckHTTP(uri, codes, timeout)
+	})
+}
+
+type healthCheckKubernetes struct {
+	clients.Kubernetes
+	e *EngineImpl
+	r config.Resource
+}
+
+// SetConfig returns a new client so this needs to be wrapped to
+// ensure health checks on the returned client emit events
+func (h *healthCheckKubernetes) SetConfig(kubeconfig string) (clients.Kubernetes, error) {
+	kc, err := h.Kubernetes.SetConfig(kubeconfig)
+	if err != nil || kc == nil {
+		return kc, err
+	}
+
+	return &healthCheckKubernetes{kc, h.e, h.r}, nil
+}
+
+func (h *healthCheckKubernetes) HealthCheckPods(selectors []string, timeout time.Duration) error {
+	return h.e.healthCheck(h.r, fmt.Sprintf("pods %s", strings.Join(selectors, ", ")), func() error {
+		return h.Kubernetes.HealthCheckPods(selectors, timeout)
+	})
+}
+
+type healthCheckNomad struct {
+	clients.Nomad
+	e *EngineImpl
+	r config.Resource
+}
+
+func (h *healthCheckNomad) HealthCheckAPI(timeout time.Duration) error {
+	return h.e.healthCheck(h.r, "Nomad API", func() error {
+		return h.Nomad.HealthCheckAPI(timeout)
+	})
+}
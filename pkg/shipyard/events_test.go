@@ -0,0 +1,145 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	clients "github.com/shipyard-run/shipyard/pkg/clients/mocks"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupEvents(e Engine) (*[]Event, func()) {
+	events := &[]Event{}
+	m := sync.Mutex{}
+
+	unsubscribe := e.Subscribe(ObserverFunc(func(ev Event) {
+		m.Lock()
+		defer m.Unlock()
+
+		*events = append(*events, ev)
+	}))
+
+	return events, unsubscribe
+}
+
+func countEvents(events *[]Event, t EventType, address string) int {
+	count := 0
+	for _, e := range *events {
+		if e.Type == t && (address == "" || e.Address() == address) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestApplyEmitsEventsForEachResource(t *testing.T) {
+	e, _, cleanup := setupTests(nil)
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	defer unsubscribe()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, countEvents(events, EventResourceQueued, ""))
+	assert.Equal(t, 3, countEvents(events, EventResourceStarted, ""))
+	assert.Equal(t, 3, countEvents(events, EventResourceSucceeded, ""))
+	assert.Equal(t, 0, countEvents(events, EventResourceFailed, ""))
+
+	// all resources are queued before any are started
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, EventResourceQueued, (*events)[i].Type)
+	}
+
+	for _, ev := range *events {
+		if ev.Type == EventResourceStarted {
+			assert.Equal(t, PlanCreate, ev.Action)
+		}
+	}
+}
+
+func TestApplyEmitsSkippedForUnchangedResources(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, mergedState)
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	defer unsubscribe()
+
+	_, err := e.Apply("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, countEvents(events, EventResourceSkipped, "network.dc1"))
+	assert.Equal(t, 0, countEvents(events, EventResourceStarted, "network.dc1"))
+}
+
+func TestApplyEmitsFailedAndSkipsDependents(t *testing.T) {
+	e, _, cleanup := setupTests(map[string]error{"onprem": fmt.Errorf("boom")})
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	defer unsubscribe()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, countEvents(events, EventResourceFailed, "network.onprem"))
+	assert.Equal(t, 1, countEvents(events, EventResourceSkipped, "container.consul"))
+	assert.Equal(t, 0, countEvents(events, EventResourceStarted, "container.consul"))
+}
+
+func TestDestroyEmitsEventsForEachResource(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, mergedState)
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	defer unsubscribe()
+
+	err := e.Destroy(context.Background(), "", true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, countEvents(events, EventResourceStarted, "network.dc1"))
+	assert.Equal(t, 1, countEvents(events, EventResourceSucceeded, "network.dc1"))
+	assert.Equal(t, PlanDestroy, (*events)[len(*events)-1].Action)
+}
+
+func TestUnsubscribeStopsEvents(t *testing.T) {
+	e, _, cleanup := setupTests(nil)
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	unsubscribe()
+
+	_, err := e.Apply("../../examples/single_file/container.hcl")
+	assert.NoError(t, err)
+
+	assert.Len(t, *events, 0)
+}
+
+func TestHealthCheckEmitsEvents(t *testing.T) {
+	e, _, cleanup := setupTests(nil)
+	defer cleanup()
+
+	events, unsubscribe := setupEvents(e)
+	defer unsubscribe()
+
+	mh := &clients.MockHTTP{}
+	mh.On("HealthCheckHTTP", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("boom"))
+
+	r := config.NewContainer("test")
+	cl := (&Clients{HTTP: mh}).forResource(e.(*EngineImpl), r)
+
+	err := cl.HTTP.HealthCheckHTTP("http://localhost", []int{200}, time.Second)
+	assert.Error(t, err)
+
+	mh.AssertCalled(t, "HealthCheckHTTP", "http://localhost", []int{200}, time.Second)
+
+	assert.Equal(t, 2, countEvents(events, EventHealthCheck, "container.test"))
+	assert.Error(t, (*events)[1].Error)
+}
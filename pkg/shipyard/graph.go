@@ -0,0 +1,187 @@
+package shipyard
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/dag"
+	"github.com/shipyard-run/shipyard/pkg/config"
+)
+
+// GraphNode is a resource in the dependency graph
+type GraphNode struct {
+	Name   string              `json:"name"`
+	Type   config.ResourceType `json:"type"`
+	Module string              `json:"module,omitempty"`
+	Status config.Status       `json:"status"`
+}
+
+// Address returns the address of the resource i.e. container.consul
+func (n GraphNode) Address() string {
+	return fmt.Sprintf("%s.%s", n.Type, n.Name)
+}
+
+// GraphEdge is a dependency between two resources, the resource
+// From depends on the resource To
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the reduced dependency graph for a configuration, edges which
+// are implied by other edges are removed
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// statusColors are the Graphviz colors used for each resource status
+var statusColors = map[config.Status]string{
+	config.Applied:             "green",
+	config.PendingCreation:     "lightblue",
+	config.PendingModification: "yellow",
+	config.PendingUpdate:       "purple",
+	config.Failed:              "red",
+	config.Disabled:            "gray",
+}
+
+// Graph returns the dependency graph for the configuration at path merged with
+// the current state. Graph does not create, destroy, or modify any resources or
+// the state.
+func (e *EngineImpl) Graph(path string, vars map[string]string, variablesFile string) (*Graph, error) {
+	var err error
+	if path != "" {
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if variablesFile != "" {
+		variablesFile, err = filepath.Abs(variablesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = e.openState(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	d, _, err := e.readConfig(path, vars, variablesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGraph(d), nil
+}
+
+// newGraph converts the dag into a Graph, vertices which are
+// not resources such as the root node are ignored
+func newGraph(d *dag.AcyclicGraph) *Graph {
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	for _, v := range d.Vertices() {
+		r, ok := v.(config.Resource)
+		if !ok {
+			continue
+		}
+
+		g.Nodes = append(g.Nodes, GraphNode{
+			Name:   r.Info().Name,
+			Type:   r.Info().Type,
+			Module: r.Info().Module,
+			Status: r.Info().Status,
+		})
+	}
+
+	// edges in the dag point from a dependency to the resource which depends on it
+	for _, ed := range d.Edges() {
+		from, fok := ed.Target().(config.Resource)
+		to, tok := ed.Source().(config.Resource)
+		if !fok || !tok {
+			continue
+		}
+
+		g.Edges = append(g.Edges, GraphEdge{
+			From: fmt.Sprintf("%s.%s", from.Info().Type, from.Info().Name),
+			To:   fmt.Sprintf("%s.%s", to.Info().Type, to.Info().Name),
+		})
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].Address() < g.Nodes[j].Address()
+	})
+
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From == g.Edges[j].From {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+
+		return g.Edges[i].From < g.Edges[j].From
+	})
+
+	return g
+}
+
+// DOT returns the graph in Graphviz DOT format, resources which belong to a module
+// are grouped in a subgraph. When colorStatus is true resources are filled with
+// a color for their status in the state.
+func (g *Graph) DOT(colorStatus bool) string {
+	sb := &strings.Builder{}
+
+	sb.WriteString("digraph shipyard {\n")
+	sb.WriteString("  compound = \"true\"\n")
+	sb.WriteString("  newrank = \"true\"\n")
+
+	modules := []string{}
+	nodes := map[string][]GraphNode{}
+
+	for _, n := range g.Nodes {
+		if _, ok := nodes[n.Module]; !ok && n.Module != "" {
+			modules = append(modules, n.Module)
+		}
+
+		nodes[n.Module] = append(nodes[n.Module], n)
+	}
+
+	sort.Strings(modules)
+
+	for _, n := range nodes[""] {
+		writeDOTNode(sb, n, colorStatus, "  ")
+	}
+
+	for _, m := range modules {
+		fmt.Fprintf(sb, "  subgraph %q {\n", "cluster_module."+m)
+		fmt.Fprintf(sb, "    label = %q\n", "module."+m)
+
+		for _, n := range nodes[m] {
+			writeDOTNode(sb, n, colorStatus, "    ")
+		}
+
+		sb.WriteString("  }\n")
+	}
+
+	for _, ed := range g.Edges {
+		fmt.Fprintf(sb, "  %q -> %q\n", ed.From, ed.To)
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func writeDOTNode(sb *strings.Builder, n GraphNode, colorStatus bool, indent string) {
+	attrs := fmt.Sprintf("label = %q, shape = \"box\"", n.Address())
+
+	if colorStatus {
+		if c, ok := statusColors[n.Status]; ok {
+			attrs = fmt.Sprintf("%s, style = \"filled\", fillcolor = %q", attrs, c)
+		}
+	}
+
+	fmt.Fprintf(sb, "%s%q [%s]\n", indent, n.Address(), attrs)
+}
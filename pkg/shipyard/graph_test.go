@@ -0,0 +1,95 @@
+package shipyard
+
+import (
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestGraphReturnsResourcesAndDependencies(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	g, err := e.Graph("../../examples/single_file/container.hcl", nil, "")
+	assert.NoError(t, err)
+
+	assert.Len(t, g.Nodes, 3)
+	assert.Contains(t, g.Edges, GraphEdge{From: "container.consul", To: "network.onprem"})
+
+	// should not have called any providers
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestGraphRemovesTransitiveEdges(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, graphState)
+	defer cleanup()
+
+	g, err := e.Graph("", nil, "")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []GraphEdge{
+		GraphEdge{From: "container.consul", To: "network.cloud"},
+		GraphEdge{From: "container.web", To: "container.consul"},
+	}, g.Edges)
+}
+
+func TestGraphDOTGroupsModules(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, graphState)
+	defer cleanup()
+
+	g, err := e.Graph("", nil, "")
+	assert.NoError(t, err)
+
+	dot := g.DOT(false)
+	assert.Contains(t, dot, `subgraph "cluster_module.consul" {`)
+	assert.Contains(t, dot, `label = "module.consul"`)
+	assert.Contains(t, dot, `    "container.consul" [label = "container.consul", shape = "box"]`)
+	assert.Contains(t, dot, `  "container.web" -> "container.consul"`)
+	assert.NotContains(t, dot, "fillcolor")
+}
+
+func TestGraphDOTColorsStatus(t *testing.T) {
+	g := &Graph{
+		Nodes: []GraphNode{
+			GraphNode{Name: "cloud", Type: config.TypeNetwork, Status: config.Applied},
+			GraphNode{Name: "consul", Type: config.TypeContainer, Status: config.Failed},
+		},
+	}
+
+	dot := g.DOT(true)
+	assert.Contains(t, dot, `"network.cloud" [label = "network.cloud", shape = "box", style = "filled", fillcolor = "green"]`)
+	assert.Contains(t, dot, `"container.consul" [label = "container.consul", shape = "box", style = "filled", fillcolor = "red"]`)
+}
+
+var graphState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "docker-cache",
+      "type": "image_cache",
+      "status": "applied"
+	},
+	{
+      "name": "cloud",
+      "type": "network",
+      "subnet": "10.0.0.0/16",
+      "status": "applied"
+	},
+	{
+      "name": "consul",
+      "type": "container",
+      "module": "consul",
+      "status": "failed",
+      "depends_on": ["network.cloud"]
+	},
+	{
+      "name": "web",
+      "type": "container",
+      "status": "pending_creation",
+      "depends_on": ["network.cloud", "container.consul"]
+	}
+  ]
+}
+`
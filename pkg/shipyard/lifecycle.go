@@ -0,0 +1,114 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+)
+
+const (
+	hookPostCreate = "post_create"
+	hookPreDestroy = "pre_destroy"
+)
+
+// runPostCreateHooks runs the post_create hooks for a resource
+// which has been created
+func (e *EngineImpl) runPostCreateHooks(r config.Resource, cc *Clients) error {
+	if r.Info().Lifecycle == nil {
+		return nil
+	}
+
+	return e.runHooks(r, hookPostCreate, r.Info().Lifecycle.PostCreate, cc)
+}
+
+// runPreDestroyHooks runs the pre_destroy hooks for a resource which is about
+// to be destroyed, failed resources may not be running so the hooks are not run
+func (e *EngineImpl) runPreDestroyHooks(r config.Resource, s config.Status, cc *Clients) error {
+	if r.Info().Lifecycle == nil || s == config.Failed {
+		return nil
+	}
+
+	return e.runHooks(r, hookPreDestroy, r.Info().Lifecycle.PreDestroy, cc)
+}
+
+// runHooks runs the hooks in order, the remaining hooks are
+// not run when a hook fails
+func (e *EngineImpl) runHooks(r config.Resource, stage string, hooks []config.Hook, cc *Clients) error {
+	for i, h := range hooks {
+		msg := fmt.Sprintf("Running %s hook %d", stage, i+1)
+		e.log.Debug(msg, "ref", r.Info().Name, "type", r.Info().Type, "command", h.Command, "target", h.Target)
+		e.emitResource(EventLifecycleHook, r, "", msg, nil)
+
+		err := runHook(r, h, cc)
+		if err != nil {
+			err = fmt.Errorf("Lifecycle hook %s %d for resource %s.%s failed: %s", stage, i+1, r.Info().Type, r.Info().Name, err)
+			e.emitResource(EventLifecycleHook, r, "", fmt.Sprintf("Hook %s %d failed", stage, i+1), err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runHook runs the command locally or, when the hook has a target, inside the
+// container for the target resource. The command is cancelled when the hook
+// timeout elapses or the context of the clients, the resource context, is done.
+func runHook(r config.Resource, h config.Hook, cc *Clients) error {
+	if len(h.Command) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+
+	timeout, err := h.TimeoutDuration()
+	if err != nil {
+		return err
+	}
+
+	ctx := cc.context()
+	hctx, cancel := resourceContext(ctx, timeout)
+	defer cancel()
+
+	err = runHookCommand(r, h, timeout, cc.withContext(hctx))
+	if err != nil && ctx.Err() == nil && hctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command did not complete within %s", timeout)
+	}
+
+	return err
+}
+
+func runHookCommand(r config.Resource, h config.Hook, timeout time.Duration, cc *Clients) error {
+	if h.Target == "" {
+		_, err := cc.Command.Execute(clients.CommandConfig{
+			Command:          h.Command[0],
+			Args:             h.Command[1:],
+			WorkingDirectory: h.WorkingDirectory,
+			LogFilePath:      filepath.Join(utils.LogsDir(), fmt.Sprintf("hook_%s_%s.log", r.Info().Type, r.Info().Name)),
+			Timeout:          timeout,
+			CheckExitCode:    true,
+		})
+
+		return err
+	}
+
+	t, err := r.Info().FindDependentResource(h.Target)
+	if err != nil {
+		return fmt.Errorf("unable to find target %s: %s", h.Target, err)
+	}
+
+	ids, err := cc.ContainerTasks.FindContainerIDs(t.Info().Name, t.Info().Type)
+	if err != nil {
+		return fmt.Errorf("unable to find container for target %s: %s", h.Target, err)
+	}
+
+	if len(ids) == 0 {
+		return fmt.Errorf("unable to find container for target %s", h.Target)
+	}
+
+	return cc.ContainerTasks.ExecuteCommand(ids[0], h.Command, nil, h.WorkingDirectory, cc.Logger.StandardWriter(&hclog.StandardLoggerOptions{ForceLevel: hclog.Debug}))
+}
@@ -0,0 +1,191 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/clients"
+	clientmocks "github.com/shipyard-run/shipyard/pkg/clients/mocks"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers/mocks"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupLifecycleTests(t *testing.T, state string, commandErr error) (*EngineImpl, *[]*mocks.MockProvider, *clients.CommandMock, *clientmocks.MockContainerTasks) {
+	e, mp, cleanup := setupTestsWithState(nil, state)
+	t.Cleanup(cleanup)
+
+	mc := &clients.CommandMock{}
+	mc.On("Execute", mock.Anything).Return(123, commandErr)
+
+	mt := &clientmocks.MockContainerTasks{}
+	mt.On("FindContainerIDs", mock.Anything, mock.Anything).Return([]string{"abc123"}, nil)
+	mt.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ei := e.(*EngineImpl)
+	ei.clients = &Clients{Command: mc, ContainerTasks: mt, Logger: hclog.NewNullLogger()}
+
+	return ei, mp, mc, mt
+}
+
+func TestApplyRunsPostCreateHooks(t *testing.T) {
+	e, _, mc, mt := setupLifecycleTests(t, lifecycleState, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+
+	mc.AssertNumberOfCalls(t, "Execute", 1)
+	cc := mc.Calls[0].Arguments[0].(clients.CommandConfig)
+	assert.Equal(t, "./seed.sh", cc.Command)
+	assert.Equal(t, []string{"--all"}, cc.Args)
+	assert.True(t, cc.CheckExitCode)
+
+	mt.AssertCalled(t, "FindContainerIDs", "consul", config.TypeContainer)
+	mt.AssertCalled(t, "ExecuteCommand", "abc123", []string{"consul", "kv", "put", "seed", "true"}, mock.Anything, "/tmp", mock.Anything)
+}
+
+func TestApplyWithFailingPostCreateHookFailsResource(t *testing.T) {
+	e, _, _, mt := setupLifecycleTests(t, lifecycleState, fmt.Errorf("boom"))
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Lifecycle hook post_create 1 for resource container.consul failed")
+
+	// the remaining hooks are not run
+	mt.AssertNotCalled(t, "ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+}
+
+func TestApplyWithTargetHookTimeoutFailsResource(t *testing.T) {
+	e, _, _, mt := setupLifecycleTests(t, lifecycleTimeoutState, nil)
+
+	// the mock does not use the context, return the error a cancelled exec returns
+	mt.ExpectedCalls = nil
+	mt.On("FindContainerIDs", mock.Anything, mock.Anything).Return([]string{"abc123"}, nil)
+	mt.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		After(200 * time.Millisecond).
+		Return(context.DeadlineExceeded)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "command did not complete within 50ms")
+}
+
+func TestApplyDoesNotRunHooksForUnchangedResources(t *testing.T) {
+	e, _, mc, _ := setupLifecycleTests(t, lifecycleAppliedState, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+
+	mc.AssertNotCalled(t, "Execute", mock.Anything)
+}
+
+func TestDestroyRunsPreDestroyHooks(t *testing.T) {
+	e, mp, mc, _ := setupLifecycleTests(t, lifecycleAppliedState, nil)
+
+	err := e.Destroy(context.Background(), "", true)
+	assert.NoError(t, err)
+
+	mc.AssertNumberOfCalls(t, "Execute", 1)
+	cc := mc.Calls[0].Arguments[0].(clients.CommandConfig)
+	assert.Equal(t, "./export_logs.sh", cc.Command)
+
+	assert.Contains(t, destroyedResources(mp), "consul")
+	assert.Contains(t, destroyedResources(mp), "dc1")
+}
+
+func TestDestroyWithFailingPreDestroyHookDoesNotDestroyResource(t *testing.T) {
+	e, mp, _, _ := setupLifecycleTests(t, lifecycleAppliedState, fmt.Errorf("boom"))
+
+	err := e.Destroy(context.Background(), "", true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Lifecycle hook pre_destroy 1 for resource container.consul failed")
+
+	// the network is not destroyed as the container still exists
+	assert.NotContains(t, destroyedResources(mp), "consul")
+	assert.NotContains(t, destroyedResources(mp), "dc1")
+}
+
+var lifecycleState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_creation",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "consul",
+      "status": "pending_creation",
+      "image": {"name": "consul"},
+      "depends_on": ["network.dc1"],
+      "type": "container",
+      "lifecycle": {
+        "post_create": [
+          {"command": ["./seed.sh", "--all"]},
+          {"command": ["consul", "kv", "put", "seed", "true"], "target": "container.consul", "working_directory": "/tmp"}
+        ]
+      }
+	}
+  ]
+}
+`
+
+var lifecycleTimeoutState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "consul",
+      "status": "pending_creation",
+      "image": {"name": "consul"},
+      "type": "container",
+      "lifecycle": {
+        "post_create": [
+          {"command": ["consul", "kv", "put", "seed", "true"], "target": "container.consul", "timeout": "50ms"}
+        ]
+      }
+	}
+  ]
+}
+`
+
+var lifecycleAppliedState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "applied",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "consul",
+      "status": "applied",
+      "image": {"name": "consul"},
+      "depends_on": ["network.dc1"],
+      "type": "container",
+      "lifecycle": {
+        "pre_destroy": [
+          {"command": ["./export_logs.sh"]}
+        ]
+      }
+	}
+  ]
+}
+`
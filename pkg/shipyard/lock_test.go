@@ -0,0 +1,76 @@
+package shipyard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestApplyWhenStateLockedReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	l, err := config.LockState(utils.StatePath(), 0)
+	assert.NoError(t, err)
+	defer l.Unlock()
+
+	_, err = e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.IsType(t, config.StateLockedError{}, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestDestroyWhenStateLockedReturnsError(t *testing.T) {
+	e, mp, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	l, err := config.LockState(utils.StatePath(), 0)
+	assert.NoError(t, err)
+	defer l.Unlock()
+
+	err = e.Destroy(context.Background(), "", true)
+	assert.Error(t, err)
+
+	err = e.DestroyTargets(context.Background(), []string{"container.consul"})
+	assert.Error(t, err)
+
+	_, err = e.Refresh(context.Background())
+	assert.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
+func TestApplyWaitsForLockTimeout(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	l, err := config.LockState(utils.StatePath(), 0)
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l.Unlock()
+	}()
+
+	e.SetLockTimeout(5 * time.Second)
+
+	_, err = e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+}
+
+func TestApplyReleasesLock(t *testing.T) {
+	e, _, cleanup := setupTestsWithState(nil, refreshState)
+	defer cleanup()
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{Refresh: true})
+	assert.NoError(t, err)
+
+	h, err := config.ReadStateLock(utils.StatePath())
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
@@ -1,6 +1,9 @@
 package mocks
 
 import (
+	"context"
+	"time"
+
 	"github.com/shipyard-run/shipyard/pkg/config"
 	"github.com/shipyard-run/shipyard/pkg/shipyard"
 	"github.com/stretchr/testify/mock"
@@ -32,8 +35,8 @@ func (e *Engine) Apply(path string) ([]config.Resource, error) {
 	return nil, args.Error(1)
 }
 
-func (e *Engine) ApplyWithVariables(path string, vars map[string]string, varsFile string) ([]config.Resource, error) {
-	args := e.Called(path, vars, varsFile)
+func (e *Engine) ApplyWithVariables(ctx context.Context, path string, vars map[string]string, varsFile string) ([]config.Resource, error) {
+	args := e.Called(ctx, path, vars, varsFile)
 
 	if r, ok := args.Get(0).([]config.Resource); ok {
 		return r, args.Error(1)
@@ -42,12 +45,58 @@ func (e *Engine) ApplyWithVariables(path string, vars map[string]string, varsFil
 	return nil, args.Error(1)
 }
 
-func (e *Engine) Destroy(path string, all bool) error {
-	args := e.Called(path, all)
+func (e *Engine) ApplyWithOptions(ctx context.Context, path string, vars map[string]string, varsFile string, options shipyard.ApplyOptions) ([]config.Resource, error) {
+	args := e.Called(ctx, path, vars, varsFile, options)
+
+	if r, ok := args.Get(0).([]config.Resource); ok {
+		return r, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (e *Engine) Plan(path string, vars map[string]string, varsFile string) (*shipyard.Plan, error) {
+	args := e.Called(path, vars, varsFile)
+
+	if p, ok := args.Get(0).(*shipyard.Plan); ok {
+		return p, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (e *Engine) Graph(path string, vars map[string]string, varsFile string) (*shipyard.Graph, error) {
+	args := e.Called(path, vars, varsFile)
+
+	if g, ok := args.Get(0).(*shipyard.Graph); ok {
+		return g, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (e *Engine) Destroy(ctx context.Context, path string, all bool) error {
+	args := e.Called(ctx, path, all)
+
+	return args.Error(0)
+}
+
+func (e *Engine) DestroyTargets(ctx context.Context, targets []string) error {
+	args := e.Called(ctx, targets)
 
 	return args.Error(0)
 }
 
+func (e *Engine) Refresh(ctx context.Context) (*shipyard.Drift, error) {
+	args := e.Called(ctx)
+
+	if d, ok := args.Get(0).(*shipyard.Drift); ok {
+		return d, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (e *Engine) ResourceCount() int {
 	return e.Called().Int(0)
 }
@@ -73,3 +122,21 @@ func (e *Engine) ParseConfigWithVariables(path string, vars map[string]string, v
 	args := e.Called(path, vars, varsFile)
 	return args.Error(0)
 }
+
+func (e *Engine) Subscribe(o shipyard.Observer) func() {
+	args := e.Called(o)
+
+	if f, ok := args.Get(0).(func()); ok {
+		return f
+	}
+
+	return func() {}
+}
+
+func (e *Engine) SetLockTimeout(timeout time.Duration) {
+	e.Called(timeout)
+}
+
+func (e *Engine) SetStateBackend(b config.StateBackend) {
+	e.Called(b)
+}
@@ -0,0 +1,87 @@
+package shipyard
+
+import (
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+)
+
+// PlanAction defines the action the engine will take for a resource
+// when the configuration is applied
+type PlanAction string
+
+// PlanCreate means the resource does not exist and will be created
+const PlanCreate PlanAction = "create"
+
+// PlanRecreate means the resource exists and will be destroyed
+// before being created again, this happens when the resource has
+// failed, been tainted, or the config has changed
+const PlanRecreate PlanAction = "recreate"
+
+// PlanUpdate means the config for the resource has changed and
+// the provider will update it in place
+const PlanUpdate PlanAction = "update"
+
+// PlanNoOp means the resource exists and no changes will be made
+const PlanNoOp PlanAction = "no-op"
+
+// PlanDestroy means the resource exists and will be destroyed, this is only
+// used for the events emitted by destroy and rollback, apply does not destroy
+// resources so it is never returned by Plan
+const PlanDestroy PlanAction = "destroy"
+
+// PlanDisabled means the resource is disabled and will be ignored
+const PlanDisabled PlanAction = "disabled"
+
+// PlanItem defines the planned action for a single resource
+type PlanItem struct {
+	Name   string              `json:"name"`
+	Type   config.ResourceType `json:"type"`
+	Module string              `json:"module,omitempty"`
+	Status config.Status       `json:"status"`
+	Action PlanAction          `json:"action"`
+}
+
+// Plan describes the actions the engine will take when applying a configuration,
+// resources are ordered by their position in the dependency graph
+type Plan struct {
+	Resources []PlanItem `json:"resources"`
+}
+
+// Count returns the number of resources in the plan with the given action
+func (p *Plan) Count(a PlanAction) int {
+	count := 0
+	for _, r := range p.Resources {
+		if r.Action == a {
+			count++
+		}
+	}
+
+	return count
+}
+
+// planAction returns the action which the engine will take for the
+// given resource, this must mirror the decisions made in ApplyWithVariables
+func planAction(r config.Resource, p providers.Provider) PlanAction {
+	// disabled resources are ignored by the engine, resources which
+	// have been disabled after they have been created are not destroyed
+	if r.Info().Disabled {
+		return PlanDisabled
+	}
+
+	switch r.Info().Status {
+	case config.PendingCreation:
+		return PlanCreate
+	case config.PendingUpdate:
+		if _, ok := p.(providers.Updater); ok {
+			return PlanUpdate
+		}
+
+		return PlanRecreate
+	case config.PendingModification, config.Failed:
+		return PlanRecreate
+	case config.Disabled:
+		return PlanDisabled
+	}
+
+	return PlanNoOp
+}
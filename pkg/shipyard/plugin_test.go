@@ -0,0 +1,37 @@
+package shipyard
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/plugins"
+	"github.com/shipyard-run/shipyard/pkg/plugins/mocks"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupPluginHost(t *testing.T) *plugins.HostImpl {
+	mp := &mocks.MockProvider{}
+	mp.On("Schema").Return([]plugins.ResourceSchema{plugins.ResourceSchema{Type: "mock_saas"}}, nil)
+
+	h := plugins.NewHost(hclog.NewNullLogger())
+	err := h.Register(mp)
+	assert.NoError(t, err)
+
+	return h
+}
+
+func TestGenerateProviderReturnsPluginProvider(t *testing.T) {
+	cl := &Clients{Plugins: setupPluginHost(t), Logger: hclog.NewNullLogger()}
+
+	p := generateProviderImpl(config.NewPluginResource("mock_saas", "api"), cl)
+	assert.IsType(t, &providers.Plugin{}, p)
+}
+
+func TestGenerateProviderWithUnknownPluginTypeReturnsNil(t *testing.T) {
+	cl := &Clients{Plugins: setupPluginHost(t), Logger: hclog.NewNullLogger()}
+
+	p := generateProviderImpl(config.NewPluginResource("other_saas", "api"), cl)
+	assert.Nil(t, p)
+}
@@ -0,0 +1,187 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/terraform/dag"
+	"github.com/hashicorp/terraform/tfdiags"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+)
+
+// DriftItem describes a resource in the state which no longer
+// matches the objects which exist
+type DriftItem struct {
+	Name   string              `json:"name"`
+	Type   config.ResourceType `json:"type"`
+	Module string              `json:"module,omitempty"`
+	// Status is the new status of the resource in the state
+	Status config.Status `json:"status"`
+	// Message contains human readable details for the drift
+	Message string `json:"message"`
+}
+
+// Address returns the address of the resource i.e. container.consul
+func (d DriftItem) Address() string {
+	return fmt.Sprintf("%s.%s", d.Type, d.Name)
+}
+
+// Drift describes the resources which have been changed by a refresh
+type Drift struct {
+	Resources []DriftItem `json:"resources"`
+}
+
+// Refresh checks that the resources in the state still exist, resources which
+// have been removed outside of Shipyard are marked as PendingCreation so that
+// they are created by the next run. Resources which depend on a missing
+// resource are marked as PendingModification so that they are recreated.
+//
+// Only resources which have a provider implementing providers.Refresher are
+// checked, all other resources are assumed to exist.
+func (e *EngineImpl) Refresh(ctx context.Context) (*Drift, error) {
+	err := e.openState("", false)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := e.lockState()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return e.refresh(ctx)
+}
+
+func (e *EngineImpl) refresh(ctx context.Context) (*Drift, error) {
+	drift := &Drift{Resources: []DriftItem{}}
+
+	sd, err := e.state.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if sd == nil {
+		e.log.Debug("Statefile does not exist, nothing to refresh")
+		return drift, nil
+	}
+
+	d, _, err := e.readConfig("", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cl := e.clients.withContext(ctx)
+
+	mutex := sync.Mutex{}
+	missing := map[config.Resource]bool{}
+
+	w := dag.Walker{}
+	w.Callback = func(v dag.Vertex) (diags tfdiags.Diagnostics) {
+		r, ok := v.(config.Resource)
+		if !ok {
+			return nil
+		}
+
+		// only resources which have been created need to be checked
+		if r.Info().Status != config.Applied && r.Info().Status != config.PendingUpdate {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return diags.Append(fmt.Errorf("Refresh cancelled"))
+		}
+
+		// dependencies are always processed before the resource
+		if dep := e.missingDependency(d, r, missing, &mutex); dep != nil {
+			r.Info().Status = config.PendingModification
+
+			mutex.Lock()
+			missing[r] = true
+			drift.Resources = append(drift.Resources, newDriftItem(r, fmt.Sprintf("Dependency %s.%s no longer exists", dep.Info().Type, dep.Info().Name)))
+			mutex.Unlock()
+
+			return nil
+		}
+
+		p := e.getProvider(r, cl)
+		rf, ok := p.(providers.Refresher)
+		if !ok {
+			return nil
+		}
+
+		e.log.Debug("Refreshing resource", "ref", r.Info().Name, "type", r.Info().Type)
+
+		exists, err := rf.Exists()
+		if err != nil {
+			return diags.Append(fmt.Errorf("Unable to refresh resource %s.%s: %s", r.Info().Type, r.Info().Name, err))
+		}
+
+		if !exists {
+			e.log.Info("Resource no longer exists", "ref", r.Info().Name, "type", r.Info().Type)
+			r.Info().Status = config.PendingCreation
+
+			mutex.Lock()
+			missing[r] = true
+			drift.Resources = append(drift.Resources, newDriftItem(r, "Resource no longer exists"))
+			mutex.Unlock()
+		}
+
+		return nil
+	}
+
+	w.Update(d)
+	diags := w.Wait()
+
+	sort.Slice(drift.Resources, func(i, j int) bool {
+		return drift.Resources[i].Address() < drift.Resources[j].Address()
+	})
+
+	// save the state when resources have changed, even if some
+	// resources could not be refreshed
+	if len(drift.Resources) > 0 {
+		jerr := e.config.Save(e.state)
+		if jerr != nil {
+			return nil, fmt.Errorf("Unable to save state: %s", jerr)
+		}
+	}
+
+	if diags.HasErrors() {
+		return drift, diags.Err()
+	}
+
+	return drift, nil
+}
+
+// missingDependency returns the first dependency of the resource
+// which has been found to be missing
+func (e *EngineImpl) missingDependency(d *dag.AcyclicGraph, r config.Resource, missing map[config.Resource]bool, mutex *sync.Mutex) config.Resource {
+	deps, err := d.Descendents(r)
+	if err != nil {
+		return nil
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, v := range deps.List() {
+		if dr, ok := v.(config.Resource); ok && missing[dr] {
+			return dr
+		}
+	}
+
+	return nil
+}
+
+func newDriftItem(r config.Resource, message string) DriftItem {
+	return DriftItem{
+		Name:    r.Info().Name,
+		Type:    r.Info().Type,
+		Module:  r.Info().Module,
+		Status:  r.Info().Status,
+		Message: message,
+	}
+}
@@ -0,0 +1,146 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+	"github.com/shipyard-run/shipyard/pkg/providers/mocks"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+// setupRefreshTests creates an engine where every provider implements
+// providers.Refresher, exists maps the resource name to the result of Exists
+func setupRefreshTests(t *testing.T, exists map[string]bool, existsErr error) (*EngineImpl, *[]*mocks.MockProvider) {
+	e, mp, cleanup := setupTestsWithState(nil, refreshState)
+	t.Cleanup(cleanup)
+
+	ei := e.(*EngineImpl)
+	ei.getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		lock.Lock()
+		defer lock.Unlock()
+
+		m := mocks.NewRefresher(c)
+		m.On("Create").Return(nil)
+		m.On("Destroy").Return(nil)
+		m.On("Exists").Return(exists[c.Info().Name], existsErr)
+
+		*mp = append(*mp, m.MockProvider)
+		return m
+	}
+
+	return ei, mp
+}
+
+func TestRefreshWithNoStateReturnsEmptyDrift(t *testing.T) {
+	e, mp, cleanup := setupTests(nil)
+	defer cleanup()
+
+	d, err := e.Refresh(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, d.Resources, 0)
+
+	testAssertMethodCalled(t, mp, "Exists", 0)
+
+	_, err = os.Stat(utils.StatePath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRefreshChecksAppliedResources(t *testing.T) {
+	e, mp := setupRefreshTests(t, map[string]bool{"dc1": true, "consul": true, "api": true}, nil)
+
+	d, err := e.Refresh(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, d.Resources, 0)
+
+	// pending resources are not checked
+	testAssertMethodCalled(t, mp, "Exists", 3)
+}
+
+func TestRefreshMarksMissingResourcesPendingCreation(t *testing.T) {
+	e, _ := setupRefreshTests(t, map[string]bool{"dc1": true, "consul": false, "api": true}, nil)
+
+	d, err := e.Refresh(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, d.Resources, 2)
+
+	assert.Equal(t, "container.api", d.Resources[0].Address())
+	assert.Equal(t, config.PendingModification, d.Resources[0].Status)
+	assert.Contains(t, d.Resources[0].Message, "container.consul")
+
+	assert.Equal(t, "container.consul", d.Resources[1].Address())
+	assert.Equal(t, config.PendingCreation, d.Resources[1].Status)
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingCreation, r.Info().Status)
+
+	r, err = c.FindResource("container.api")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingModification, r.Info().Status)
+
+	r, err = c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestRefreshWithErrorReturnsError(t *testing.T) {
+	e, _ := setupRefreshTests(t, nil, fmt.Errorf("boom"))
+
+	_, err := e.Refresh(context.Background())
+	assert.Error(t, err)
+}
+
+func TestApplyWithRefreshCreatesMissingResources(t *testing.T) {
+	e, mp := setupRefreshTests(t, map[string]bool{"dc1": true, "consul": false, "api": true}, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{Refresh: true})
+	assert.NoError(t, err)
+
+	// consul is created and api is destroyed and created, the pending
+	// container and the image cache are also created
+	testAssertMethodCalled(t, mp, "Create", 4)
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+}
+
+var refreshState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "applied",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "consul",
+      "status": "applied",
+      "image": {"name": "consul"},
+      "depends_on": ["network.dc1"],
+      "type": "container"
+	},
+	{
+      "name": "api",
+      "status": "pending_update",
+      "image": {"name": "api"},
+      "depends_on": ["container.consul"],
+      "type": "container"
+	},
+	{
+      "name": "pending",
+      "status": "pending_creation",
+      "image": {"name": "pending"},
+      "type": "container"
+	}
+  ]
+}
+`
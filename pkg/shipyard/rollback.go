@@ -0,0 +1,76 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+)
+
+// rollback destroys the given resources in the reverse of the order they were
+// created, resources which are destroyed are marked as PendingCreation so that
+// they are created by the next run. All resources are processed even when one
+// fails to be destroyed, the errors are combined into the returned error.
+func (e *EngineImpl) rollback(ctx context.Context, resources []config.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	e.log.Info("Rolling back created resources", "count", len(resources))
+
+	ev := e.newWalkEvents(resources)
+	defer ev.finish()
+
+	errs := []string{}
+
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+
+		err := e.rollbackResource(ctx, r, ev)
+		if err != nil {
+			r.Info().Status = config.Failed
+			ev.failed(r, PlanDestroy, err)
+
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		r.Info().Status = config.PendingCreation
+		ev.succeeded(r, PlanDestroy)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (e *EngineImpl) rollbackResource(ctx context.Context, r config.Resource, ev *walkEvents) error {
+	timeout, err := resourceTimeout(r, PlanDestroy)
+	if err != nil {
+		return err
+	}
+
+	rctx, cancel := resourceContext(ctx, timeout)
+	defer cancel()
+
+	cc := e.clients.withContext(rctx).forResource(e, r)
+	p := e.getProvider(r, cc)
+	if p == nil {
+		return fmt.Errorf("Unable to create provider for resource Name: %s, Type: %s", r.Info().Name, r.Info().Type)
+	}
+
+	e.log.Debug("Rolling back resource", "ref", r.Info().Name, "type", r.Info().Type)
+	ev.started(r, PlanDestroy)
+
+	return waitForResource(ctx, rctx, r, PlanDestroy, timeout, func() error {
+		err := e.runPreDestroyHooks(r, r.Info().Status, cc)
+		if err != nil {
+			return err
+		}
+
+		return p.Destroy()
+	})
+}
@@ -0,0 +1,143 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+	"github.com/shipyard-run/shipyard/pkg/providers/mocks"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+// destroyedResources returns the names of the resources which have been
+// destroyed in the order the providers were created
+func destroyedResources(mp *[]*mocks.MockProvider) []string {
+	names := []string{}
+	for _, m := range *mp {
+		for _, c := range m.Calls {
+			if c.Method == "Destroy" {
+				names = append(names, m.Config().Info().Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// setupRollbackTests creates an engine where the create and destroy methods
+// for the providers return the given errors
+func setupRollbackTests(t *testing.T, createErrors, destroyErrors map[string]error) (Engine, *[]*mocks.MockProvider) {
+	e, mp, cleanup := setupTestsWithState(nil, rollbackState)
+	t.Cleanup(cleanup)
+
+	ei := e.(*EngineImpl)
+	ei.getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		lock.Lock()
+		defer lock.Unlock()
+
+		m := mocks.New(c)
+		m.On("Create").Return(createErrors[c.Info().Name])
+		m.On("Destroy").Return(destroyErrors[c.Info().Name])
+
+		*mp = append(*mp, m)
+		return m
+	}
+
+	return e, mp
+}
+
+func TestApplyWithRollbackDestroysCreatedResourcesInReverseOrder(t *testing.T) {
+	e, mp := setupRollbackTests(t, map[string]error{"api": fmt.Errorf("boom")}, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{RollbackOnFailure: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+
+	// the failed resource is destroyed before the resources it depends on,
+	// the network existed before the apply so is not destroyed
+	assert.Equal(t, []string{"api", "consul"}, destroyedResources(mp))
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.api")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingCreation, r.Info().Status)
+
+	r, err = c.FindResource("container.consul")
+	assert.NoError(t, err)
+	assert.Equal(t, config.PendingCreation, r.Info().Status)
+
+	r, err = c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestApplyWithoutRollbackDoesNotDestroyResources(t *testing.T) {
+	e, mp := setupRollbackTests(t, map[string]error{"api": fmt.Errorf("boom")}, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+
+	assert.Len(t, destroyedResources(mp), 0)
+}
+
+func TestApplyWithRollbackAndNoFailureDoesNotDestroyResources(t *testing.T) {
+	e, mp := setupRollbackTests(t, nil, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{RollbackOnFailure: true})
+	assert.NoError(t, err)
+
+	assert.Len(t, destroyedResources(mp), 0)
+}
+
+func TestApplyWithRollbackFailureReturnsError(t *testing.T) {
+	e, mp := setupRollbackTests(t, map[string]error{"api": fmt.Errorf("boom")}, map[string]error{"api": fmt.Errorf("destroy failed")})
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{RollbackOnFailure: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to roll back created resources: destroy failed")
+
+	// remaining resources are rolled back when a resource fails to be destroyed
+	assert.Equal(t, []string{"api", "consul"}, destroyedResources(mp))
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("container.api")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+}
+
+var rollbackState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "applied",
+      "subnet": "10.15.0.0/16",
+      "type": "network"
+	},
+	{
+      "name": "consul",
+      "status": "pending_creation",
+      "image": {"name": "consul"},
+      "depends_on": ["network.dc1"],
+      "type": "container"
+	},
+	{
+      "name": "api",
+      "status": "pending_creation",
+      "image": {"name": "api"},
+      "depends_on": ["container.consul"],
+      "type": "container"
+	}
+  ]
+}
+`
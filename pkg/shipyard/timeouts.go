@@ -0,0 +1,56 @@
+package shipyard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+)
+
+// resourceTimeout returns the timeout for the action from the timeouts block
+// of the resource, 0 is returned when no timeout has been set
+func resourceTimeout(r config.Resource, a PlanAction) (time.Duration, error) {
+	if a == PlanDestroy {
+		return r.Info().Timeouts.DestroyDuration()
+	}
+
+	return r.Info().Timeouts.CreateDuration()
+}
+
+// resourceContext returns a context which is cancelled when the timeout
+// elapses, a timeout of 0 means the context is only cancelled with the parent
+func resourceContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// waitForResource runs f, the clients used by the provider share rctx so any in flight
+// operations are aborted when the timeout elapses. When f fails after rctx has timed out
+// an error naming the resource is returned, a provider which completes successfully
+// after the deadline is not treated as having timed out.
+func waitForResource(ctx, rctx context.Context, r config.Resource, a PlanAction, timeout time.Duration, f func() error) error {
+	err := f()
+	if err != nil && ctx.Err() == nil && rctx.Err() == context.DeadlineExceeded {
+		return timeoutError(r, a, timeout)
+	}
+
+	return err
+}
+
+func timeoutError(r config.Resource, a PlanAction, timeout time.Duration) error {
+	verb := "creating"
+	switch a {
+	case PlanUpdate:
+		verb = "updating"
+	case PlanRecreate:
+		verb = "recreating"
+	case PlanDestroy:
+		verb = "destroying"
+	}
+
+	return fmt.Errorf("Timeout %s resource %s.%s, operation did not complete within %s", verb, r.Info().Type, r.Info().Name, timeout)
+}
@@ -0,0 +1,140 @@
+package shipyard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/providers"
+	"github.com/shipyard-run/shipyard/pkg/providers/mocks"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	assert "github.com/stretchr/testify/require"
+)
+
+// setupTimeoutTests creates an engine where the provider methods
+// take the given duration to complete and return err
+func setupTimeoutTests(t *testing.T, state string, d time.Duration, err error) (*EngineImpl, *[]*mocks.MockProvider) {
+	e, mp, cleanup := setupTestsWithState(nil, state)
+	t.Cleanup(cleanup)
+
+	ei := e.(*EngineImpl)
+	ei.getProvider = func(c config.Resource, cc *Clients) providers.Provider {
+		lock.Lock()
+		defer lock.Unlock()
+
+		m := mocks.New(c)
+		m.On("Create").After(d).Return(err)
+		m.On("Destroy").After(d).Return(err)
+
+		*mp = append(*mp, m)
+		return m
+	}
+
+	return ei, mp
+}
+
+func TestApplyWithCreateTimeoutReturnsErrorNamingResource(t *testing.T) {
+	e, _ := setupTimeoutTests(t, timeoutsState, 500*time.Millisecond, context.DeadlineExceeded)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Timeout creating resource network.dc1")
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Failed, r.Info().Status)
+}
+
+func TestApplyWithCreateTimeoutWaitsForProviderBeforeSavingState(t *testing.T) {
+	e, _ := setupTimeoutTests(t, timeoutsState, 500*time.Millisecond, context.DeadlineExceeded)
+
+	start := time.Now()
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+
+	// the provider does not use the context so the state must
+	// not be saved until the provider has returned
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(500*time.Millisecond))
+}
+
+func TestApplyDoesNotFailResourceCompletingAfterTimeout(t *testing.T) {
+	e, _ := setupTimeoutTests(t, timeoutsState, 500*time.Millisecond, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+
+	c := config.New()
+	err = c.FromJSON(utils.StatePath())
+	assert.NoError(t, err)
+
+	r, err := c.FindResource("network.dc1")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Applied, r.Info().Status)
+}
+
+func TestApplyCompletesWithinTimeout(t *testing.T) {
+	e, mp := setupTimeoutTests(t, timeoutsState, time.Millisecond, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 2)
+}
+
+func TestApplyWithInvalidTimeoutReturnsError(t *testing.T) {
+	e, mp := setupTimeoutTests(t, invalidTimeoutsState, time.Millisecond, nil)
+
+	_, err := e.ApplyWithOptions(context.Background(), "", nil, "", ApplyOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid create timeout")
+
+	testAssertMethodCalled(t, mp, "Create", 1)
+}
+
+func TestDestroyWithDestroyTimeoutReturnsErrorNamingResource(t *testing.T) {
+	e, _ := setupTimeoutTests(t, timeoutsState, 500*time.Millisecond, context.DeadlineExceeded)
+
+	err := e.Destroy(context.Background(), "", true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Timeout destroying resource network.dc1")
+}
+
+var timeoutsState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_creation",
+      "subnet": "10.15.0.0/16",
+      "type": "network",
+      "timeouts": {
+        "create": "100ms",
+        "destroy": "100ms"
+      }
+	}
+  ]
+}
+`
+
+var invalidTimeoutsState = `
+{
+  "blueprint": null,
+  "resources": [
+	{
+      "name": "dc1",
+      "status": "pending_creation",
+      "subnet": "10.15.0.0/16",
+      "type": "network",
+      "timeouts": {
+        "create": "abc"
+      }
+	}
+  ]
+}
+`
@@ -0,0 +1,281 @@
+// Package sytest allows Shipyard blueprints to be used from Go tests.
+//
+// Run applies a blueprint and registers a cleanup function which destroys the
+// resources defined in the blueprint when the test completes:
+//
+//	func TestAPI(t *testing.T) {
+//		s := sytest.Run(t, "./blueprint", map[string]string{"version": "1.9.0"})
+//
+//		addr := s.Output("API_ADDR")
+//		kc := s.KubeConfigPath("k3s")
+//		...
+//	}
+//
+// The blueprint is applied to the current workspace, tests which apply
+// blueprints should not be run in parallel.
+package sytest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+)
+
+// T is the subset of testing.T used by the harness
+type T interface {
+	Helper()
+	Cleanup(func())
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Stack is a blueprint which has been applied by Run
+type Stack struct {
+	t         T
+	engine    shipyard.Engine
+	blueprint string
+	resources []config.Resource
+}
+
+// Run applies the blueprint at the given path with the given variables, the test
+// fails if the blueprint can not be applied. The resources defined in the blueprint
+// are destroyed when the test and all its subtests complete.
+// Remote blueprints such as github.com/shipyard-run/blueprints//vault-k8s are
+// downloaded before they are applied.
+func Run(t T, blueprint string, vars map[string]string) *Stack {
+	t.Helper()
+
+	l := hclog.New(&hclog.LoggerOptions{
+		Name:   "shipyard",
+		Level:  hclog.Info,
+		Output: &logWriter{t},
+	})
+
+	e, err := shipyard.New(l)
+	if err != nil {
+		t.Fatalf("Unable to create Shipyard engine: %s", err)
+	}
+
+	// cleanup functions run last added first, the plugins
+	// must be stopped after the stack has been destroyed
+	t.Cleanup(func() {
+		if p := e.GetClients().Plugins; p != nil {
+			p.Close()
+		}
+	})
+
+	return RunWithEngine(t, e, blueprint, vars)
+}
+
+// RunWithEngine applies the blueprint using the given engine, see Run
+func RunWithEngine(t T, e shipyard.Engine, blueprint string, vars map[string]string) *Stack {
+	t.Helper()
+
+	if !utils.IsLocalFolder(blueprint) && !utils.IsHCLFile(blueprint) {
+		dst := utils.GetBlueprintLocalFolder(blueprint)
+
+		err := e.GetClients().Getter.Get(blueprint, dst)
+		if err != nil {
+			t.Fatalf("Unable to retrieve blueprint %s: %s", blueprint, err)
+		}
+
+		blueprint = dst
+	}
+
+	abs, err := filepath.Abs(blueprint)
+	if err != nil {
+		t.Fatalf("Unable to find blueprint %s: %s", blueprint, err)
+	}
+
+	s := &Stack{t: t, engine: e, blueprint: abs}
+
+	// the cleanup is registered before the blueprint is applied so
+	// that resources created by a failed apply are destroyed
+	t.Cleanup(s.destroy)
+
+	res, err := e.ApplyWithVariables(context.Background(), abs, vars, "")
+	if err != nil {
+		t.Fatalf("Unable to apply blueprint %s: %s", blueprint, err)
+	}
+
+	s.resources = res
+
+	return s
+}
+
+func (s *Stack) destroy() {
+	s.t.Logf("Destroying blueprint %s", s.blueprint)
+
+	// all the resources in the state which are defined by the blueprint are
+	// destroyed, including resources which failed and may be partially created
+	err := s.engine.Destroy(context.Background(), s.blueprint, false)
+	if err != nil {
+		s.t.Errorf("Unable to destroy blueprint %s: %s", s.blueprint, err)
+	}
+}
+
+// Engine returns the engine which applied the blueprint
+func (s *Stack) Engine() shipyard.Engine {
+	return s.engine
+}
+
+// Resource returns the resource with the given address i.e. container.consul,
+// the test fails if the resource was not created by the blueprint
+func (s *Stack) Resource(address string) config.Resource {
+	s.t.Helper()
+
+	for _, r := range s.resources {
+		if fmt.Sprintf("%s.%s", r.Info().Type, r.Info().Name) == address {
+			return r
+		}
+	}
+
+	s.t.Fatalf("Resource %s was not created by the blueprint", address)
+	return nil
+}
+
+// Output returns the value of the output with the given name
+func (s *Stack) Output(name string) string {
+	s.t.Helper()
+
+	o, ok := s.Resource(fmt.Sprintf("%s.%s", config.TypeOutput, name)).(*config.Output)
+	if !ok {
+		s.t.Fatalf("Resource output.%s is not an output", name)
+	}
+
+	return o.Value
+}
+
+// OutputInt returns the value of the output with the given name as an int,
+// the test fails if the value is not an integer
+func (s *Stack) OutputInt(name string) int {
+	s.t.Helper()
+
+	v := s.Output(name)
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		s.t.Fatalf("Output %s with value %s is not an integer", name, v)
+	}
+
+	return i
+}
+
+// OutputBool returns the value of the output with the given name as a bool,
+// the test fails if the value is not a boolean
+func (s *Stack) OutputBool(name string) bool {
+	s.t.Helper()
+
+	v := s.Output(name)
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		s.t.Fatalf("Output %s with value %s is not a boolean", name, v)
+	}
+
+	return b
+}
+
+// ContainerFQDN returns the fully qualified domain name for the container
+// with the given name i.e. consul.container.shipyard.run, the name can be
+// resolved by other resources attached to the same network
+func (s *Stack) ContainerFQDN(name string) string {
+	s.t.Helper()
+
+	c := s.container(name)
+
+	return utils.FQDN(c.Name, string(c.Type))
+}
+
+// ContainerIPAddress returns the IP address of the container with the given
+// name on the given network, the test fails if the container is not attached
+// to the network
+func (s *Stack) ContainerIPAddress(name, network string) string {
+	s.t.Helper()
+
+	c := s.container(name)
+
+	// networks can be referenced as network.[name] or [name]
+	network = strings.TrimPrefix(network, fmt.Sprintf("%s.", config.TypeNetwork))
+
+	attached := false
+	for _, n := range c.Networks {
+		if strings.TrimPrefix(n.Name, fmt.Sprintf("%s.", config.TypeNetwork)) != network {
+			continue
+		}
+
+		// static addresses do not need to be looked up
+		if n.IPAddress != "" {
+			return n.IPAddress
+		}
+
+		attached = true
+	}
+
+	if !attached {
+		s.t.Fatalf("Container %s is not attached to network %s", name, network)
+	}
+
+	cl := s.engine.GetClients()
+
+	ids, err := cl.ContainerTasks.FindContainerIDs(c.Name, c.Type)
+	if err != nil || len(ids) == 0 {
+		s.t.Fatalf("Unable to find container %s: %s", name, err)
+	}
+
+	info, err := cl.Docker.ContainerInspect(context.Background(), ids[0])
+	if err != nil {
+		s.t.Fatalf("Unable to inspect container %s: %s", name, err)
+	}
+
+	if info.NetworkSettings != nil {
+		if n, ok := info.NetworkSettings.Networks[utils.DockerNetworkName(network)]; ok && n.IPAddress != "" {
+			return n.IPAddress
+		}
+	}
+
+	s.t.Fatalf("Unable to find the IP address for container %s on network %s", name, network)
+	return ""
+}
+
+// KubeConfigPath returns the path to the Kubernetes config file for the
+// cluster with the given name, the file can be used with kubectl or client-go
+func (s *Stack) KubeConfigPath(cluster string) string {
+	s.t.Helper()
+
+	s.Resource(fmt.Sprintf("%s.%s", config.TypeK8sCluster, cluster))
+
+	_, path, _ := utils.CreateKubeConfigPath(cluster)
+
+	return path
+}
+
+func (s *Stack) container(name string) *config.Container {
+	s.t.Helper()
+
+	c, ok := s.Resource(fmt.Sprintf("%s.%s", config.TypeContainer, name)).(*config.Container)
+	if !ok {
+		s.t.Fatalf("Resource container.%s is not a container", name)
+	}
+
+	return c
+}
+
+// logWriter writes the engine logs to the test log so
+// that they are only shown when a test fails or with -v
+type logWriter struct {
+	t T
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.t.Logf("%s", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,299 @@
+package sytest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/shipyard-run/shipyard/pkg/clients/mocks"
+	"github.com/shipyard-run/shipyard/pkg/config"
+	"github.com/shipyard-run/shipyard/pkg/shipyard"
+	enginemocks "github.com/shipyard-run/shipyard/pkg/shipyard/mocks"
+	"github.com/shipyard-run/shipyard/pkg/utils"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+// mockT records failures rather than failing the test, Fatalf
+// stops the goroutine in the same way as testing.T
+type mockT struct {
+	cleanups []func()
+	errors   []string
+	fatal    string
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Logf(format string, args ...interface{}) {}
+
+func (m *mockT) Cleanup(f func()) {
+	m.cleanups = append(m.cleanups, f)
+}
+
+func (m *mockT) Errorf(format string, args ...interface{}) {
+	m.errors = append(m.errors, fmt.Sprintf(format, args...))
+}
+
+func (m *mockT) Fatalf(format string, args ...interface{}) {
+	m.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls f in a new goroutine so that Fatalf can stop it
+func (m *mockT) run(f func()) {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	<-done
+}
+
+func (m *mockT) runCleanup() {
+	for i := len(m.cleanups) - 1; i >= 0; i-- {
+		m.cleanups[i]()
+	}
+}
+
+func setupStack(t *testing.T, applyErr error) (*mockT, *enginemocks.Engine, *mocks.MockDocker, string) {
+	home := os.Getenv(utils.HomeEnvName())
+	os.Setenv(utils.HomeEnvName(), t.TempDir())
+	t.Cleanup(func() { os.Setenv(utils.HomeEnvName(), home) })
+
+	bp := t.TempDir()
+
+	con := config.NewContainer("consul")
+	con.Networks = []config.NetworkAttachment{
+		config.NetworkAttachment{Name: "network.cloud"},
+		config.NetworkAttachment{Name: "network.static", IPAddress: "10.6.0.200"},
+	}
+
+	out := config.NewOutput("port")
+	out.Value = "8500"
+
+	enabled := config.NewOutput("enabled")
+	enabled.Value = "true"
+
+	res := []config.Resource{
+		config.NewNetwork("cloud"),
+		con,
+		config.NewK8sCluster("k3s"),
+		out,
+		enabled,
+	}
+
+	md := &mocks.MockDocker{}
+	md.On("ContainerInspect", mock.Anything, "abc").Return(types.ContainerJSON{
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"cloud": &network.EndpointSettings{IPAddress: "10.5.0.2"},
+			},
+		},
+	}, nil)
+
+	mt := &mocks.MockContainerTasks{}
+	mt.On("FindContainerIDs", "consul", config.TypeContainer).Return([]string{"abc"}, nil)
+
+	me := &enginemocks.Engine{}
+	me.On("ApplyWithVariables", mock.Anything, bp, mock.Anything, "").Return(res, applyErr)
+	me.On("Destroy", mock.Anything, bp, false).Return(nil)
+	me.On("GetClients").Return(&shipyard.Clients{Docker: md, ContainerTasks: mt})
+
+	return &mockT{}, me, md, bp
+}
+
+func TestRunAppliesBlueprintWithVariables(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	mt.run(func() {
+		RunWithEngine(mt, me, bp, map[string]string{"version": "1.9.0"})
+	})
+
+	assert.Empty(t, mt.fatal)
+	me.AssertCalled(t, "ApplyWithVariables", mock.Anything, bp, map[string]string{"version": "1.9.0"}, "")
+	me.AssertNotCalled(t, "Destroy", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunDestroysBlueprintOnCleanup(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	mt.run(func() {
+		RunWithEngine(mt, me, bp, nil)
+	})
+
+	mt.runCleanup()
+
+	me.AssertCalled(t, "Destroy", mock.Anything, bp, false)
+	assert.Empty(t, mt.errors)
+}
+
+func TestRunWithApplyErrorFailsAndDestroys(t *testing.T) {
+	mt, _, _, _ := setupStack(t, nil)
+
+	bp := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(bp, "container.hcl"), []byte(failingBlueprint), os.ModePerm)
+	assert.NoError(t, err)
+
+	ct := &mocks.MockContainerTasks{}
+	ct.On("FindContainerIDs", mock.Anything, mock.Anything).Return([]string{"abc"}, nil)
+	ct.On("PullImage", mock.Anything, false).Return(nil)
+	ct.On("CreateContainer", mock.Anything).Return("", fmt.Errorf("boom"))
+	ct.On("RemoveContainer", "abc").Return(nil)
+
+	// use the engine with mock clients so that the
+	// resources which are destroyed can be checked
+	l := hclog.NewNullLogger()
+	e := shipyard.NewWithClients(&shipyard.Clients{ContainerTasks: ct, Logger: l}, l)
+
+	mt.run(func() {
+		RunWithEngine(mt, e, bp, nil)
+	})
+
+	assert.Contains(t, mt.fatal, "boom")
+
+	mt.runCleanup()
+	assert.Empty(t, mt.errors)
+
+	// the failed container may have been partially created
+	ct.AssertCalled(t, "RemoveContainer", "abc")
+
+	c := config.New()
+	c.FromJSON(utils.StatePath())
+
+	_, err = c.FindResource("container.consul")
+	assert.Error(t, err)
+}
+
+const failingBlueprint = `
+container "consul" {
+  image {
+    name = "consul:1.8.1"
+  }
+}
+`
+
+func TestRunResolvesRelativePath(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(filepath.Dir(bp))
+
+	mt.run(func() {
+		RunWithEngine(mt, me, "./"+filepath.Base(bp), nil)
+	})
+
+	assert.Empty(t, mt.fatal)
+}
+
+func TestOutputsReturnTypedValues(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	assert.Equal(t, "8500", s.Output("port"))
+	assert.Equal(t, 8500, s.OutputInt("port"))
+	assert.True(t, s.OutputBool("enabled"))
+}
+
+func TestOutputWithInvalidTypeFails(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	mt.run(func() {
+		s.OutputBool("port")
+	})
+
+	assert.Contains(t, mt.fatal, "not a boolean")
+}
+
+func TestOutputNotFoundFails(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	mt.run(func() {
+		s.Output("missing")
+	})
+
+	assert.Contains(t, mt.fatal, "output.missing")
+}
+
+func TestContainerFQDNReturnsName(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	assert.Equal(t, utils.FQDN("consul", "container"), s.ContainerFQDN("consul"))
+}
+
+func TestContainerIPAddressReturnsStaticAddress(t *testing.T) {
+	mt, me, md, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	assert.Equal(t, "10.6.0.200", s.ContainerIPAddress("consul", "network.static"))
+	md.AssertNotCalled(t, "ContainerInspect", mock.Anything, mock.Anything)
+}
+
+func TestContainerIPAddressLooksUpDynamicAddress(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	assert.Equal(t, "10.5.0.2", s.ContainerIPAddress("consul", "cloud"))
+}
+
+func TestContainerIPAddressNotAttachedFails(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	mt.run(func() {
+		s.ContainerIPAddress("consul", "other")
+	})
+
+	assert.Contains(t, mt.fatal, "not attached")
+}
+
+func TestKubeConfigPathReturnsPath(t *testing.T) {
+	mt, me, _, bp := setupStack(t, nil)
+
+	var s *Stack
+	mt.run(func() {
+		s = RunWithEngine(mt, me, bp, nil)
+	})
+
+	_, kc, _ := utils.CreateKubeConfigPath("k3s")
+	assert.Equal(t, kc, s.KubeConfigPath("k3s"))
+}
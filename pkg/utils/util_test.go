@@ -102,6 +102,21 @@ func TestFQDNReplacesInvalidChars(t *testing.T) {
 	assert.Equal(t, "tes-t.type.shipyard.run", fq)
 }
 
+func TestFQDNWithInstanceNameReturnsProviderName(t *testing.T) {
+	fq := FQDN("consul[0]", "container")
+	assert.Equal(t, "consul-0.container.shipyard.run", fq)
+
+	fq = FQDN(`consul["dc1"]`, "container")
+	assert.Equal(t, "consul-dc1.container.shipyard.run", fq)
+}
+
+func TestProviderNameReplacesInstanceKey(t *testing.T) {
+	assert.Equal(t, "cloud", ProviderName("cloud"))
+	assert.Equal(t, "cloud-0", ProviderName("cloud[0]"))
+	assert.Equal(t, "cloud-dc1", ProviderName(`cloud["dc1"]`))
+	assert.Equal(t, "cloud-dc1", ProviderName("cloud[dc1]"))
+}
+
 func TestFQDNVolumeReturnsCorrectValue(t *testing.T) {
 	fq := FQDNVolumeName("test")
 	assert.Equal(t, "test.volume.shipyard.run", fq)
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -50,33 +51,101 @@ func ReplaceNonURIChars(s string) (string, error) {
 	return reg.ReplaceAllString(s, "-"), nil
 }
 
-// FQDN generates the full qualified name for a container
+// instanceNameRegex matches the names of resource instances created with
+// count or for_each i.e. consul[0] or consul["dc1"]
+var instanceNameRegex = regexp.MustCompile(`^(.+)\[(.+)\]$`)
+
+// ProviderName returns the name used for the Docker objects and files for
+// a resource. The names of instances created with count or for_each contain
+// brackets and quotes, these are replaced so that consul[0] becomes consul-0
+// and consul["dc1"] becomes consul-dc1.
+func ProviderName(name string) string {
+	m := instanceNameRegex.FindStringSubmatch(name)
+	if m == nil {
+		return name
+	}
+
+	key := m[2]
+	if k, err := strconv.Unquote(key); err == nil {
+		key = k
+	}
+
+	return fmt.Sprintf("%s-%s", m[1], key)
+}
+
+// FQDN generates the full qualified name for a container, containers
+// in workspaces other than the default include the workspace in the name
 func FQDN(name, typeName string) string {
 	// ensure that the name is valid for URI schema
-	cleanName, err := ReplaceNonURIChars(name)
+	cleanName, err := ReplaceNonURIChars(ProviderName(name))
 	if err != nil {
 		panic(err)
 	}
 
-	fqdn := fmt.Sprintf("%s.%s.shipyard.run", cleanName, typeName)
+	fqdn := fmt.Sprintf("%s.%s.%s", cleanName, typeName, workspaceDomain())
 	return fqdn
 }
 
 // FQDNVolumeName creates a full qualified volume name
 func FQDNVolumeName(name string) string {
 	// ensure that the name is valid for URI schema
-	cleanName, err := ReplaceNonURIChars(name)
+	cleanName, err := ReplaceNonURIChars(ProviderName(name))
 	if err != nil {
 		panic(err)
 	}
 
-	return fmt.Sprintf("%s.volume.shipyard.run", cleanName)
+	return fmt.Sprintf("%s.volume.%s", cleanName, workspaceDomain())
+}
+
+// DockerNetworkName returns the name of the Docker network for the network resource
+// with the given name i.e. network.cloud or cloud, networks in workspaces other
+// than the default include the workspace in the name
+func DockerNetworkName(name string) string {
+	name = ProviderName(strings.TrimPrefix(name, "network."))
+
+	w := Workspace()
+	if w == DefaultWorkspace {
+		return name
+	}
+
+	cleanWorkspace, err := ReplaceNonURIChars(w)
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf("%s.%s", name, cleanWorkspace)
+}
+
+// workspaceDomain returns the domain for Docker objects in the current workspace
+func workspaceDomain() string {
+	w := Workspace()
+	if w == DefaultWorkspace {
+		return "shipyard.run"
+	}
+
+	cleanWorkspace, err := ReplaceNonURIChars(w)
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf("%s.shipyard.run", cleanWorkspace)
+}
+
+// ProxyAddress returns the address of the proxy used for caching docker images
+func ProxyAddress() string {
+	return fmt.Sprintf("http://%s:3128", FQDN(CacheResourceName, "container"))
+}
+
+// ConfigDir returns the location of the cluster config for the
+// current workspace, usually $HOME/.shipyard/config
+func ConfigDir() string {
+	return filepath.Join(WorkspaceDir(Workspace()), "/config")
 }
 
 // CreateKubeConfigPath creates the file path for the KubeConfig file when
 // using Kubernetes cluster
 func CreateKubeConfigPath(name string) (dir, filePath string, dockerPath string) {
-	dir = filepath.Join(ShipyardHome(), "/config/", name)
+	dir = filepath.Join(ConfigDir(), ProviderName(name))
 	filePath = filepath.Join(dir, "/kubeconfig.yaml")
 	dockerPath = filepath.Join(dir, "/kubeconfig-docker.yaml")
 
@@ -104,7 +173,10 @@ func GetClusterConfig(name string) (ClusterConfig, string) {
 		return ClusterConfig{}, ""
 	}
 
-	dir := filepath.Join(ShipyardHome(), "/config/", parts[1])
+	// instance keys created with for_each can contain a .
+	clusterName := strings.Join(parts[1:], ".")
+
+	dir := filepath.Join(ConfigDir(), ProviderName(clusterName))
 	filePath := filepath.Join(dir, "/config.json")
 
 	if _, err := os.Stat(filePath); err == nil {
@@ -133,7 +205,7 @@ func GetClusterConfig(name string) (ClusterConfig, string) {
 	// generate the config file
 	config := ClusterConfig{
 		LocalAddress:  GetDockerIP(),
-		RemoteAddress: FQDN(fmt.Sprintf("server.%s", parts[1]), parts[0]),
+		RemoteAddress: FQDN(fmt.Sprintf("server.%s", clusterName), parts[0]),
 		ConnectorPort: connectorPort,
 		APIPort:       apiPort,
 		RemoteAPIPort: remoteAPIPort,
@@ -176,16 +248,22 @@ func ShipyardTemp() string {
 	return dir
 }
 
-// StateDir returns the location of the shipyard
-// state, usually $HOME/.shipyard/state
+// StateDir returns the location of the shipyard state for
+// the current workspace, usually $HOME/.shipyard/state
 func StateDir() string {
-	return filepath.Join(ShipyardHome(), "/state")
+	return filepath.Join(WorkspaceDir(Workspace()), "/state")
 }
 
 // CertsDir returns the location of the certificates for the given resource
-// used to secure the Shipyard ingress, usually rooted at $HOME/.shipyard/certs
+// used to secure the Shipyard ingress, usually rooted at $HOME/.shipyard/certs.
+// The root certificates, returned when name is empty, are shared by all
+// workspaces, the certificates for resources are stored in the current workspace
 func CertsDir(name string) string {
-	certs := filepath.Join(ShipyardHome(), "/certs", name)
+	certs := filepath.Join(ShipyardHome(), "/certs")
+	if name != "" {
+		certs = filepath.Join(WorkspaceDir(Workspace()), "/certs", ProviderName(name))
+	}
+
 	certs = filepath.FromSlash(certs)
 
 	// create the folder if it does not exist
@@ -207,9 +285,16 @@ func StatePath() string {
 	return filepath.Join(StateDir(), "/state.json")
 }
 
-// ImageCacheLog returns the location of the image cache log
+// PluginsDir returns the location of the provider plugins for
+// the current workspace, usually $HOME/.shipyard/plugins
+func PluginsDir() string {
+	return filepath.Join(WorkspaceDir(Workspace()), "/plugins")
+}
+
+// ImageCacheLog returns the location of the image cache log, each
+// workspace has its own image cache
 func ImageCacheLog() string {
-	return fmt.Sprintf("%s/images.log", ShipyardHome())
+	return filepath.Join(WorkspaceDir(Workspace()), "/images.log")
 }
 
 // IsLocalFolder tests if the given path is a localfolder and can
@@ -295,8 +380,9 @@ func GetReleasesFolder() string {
 }
 
 // GetDataFolder creates the data directory used by the application
+// for the current workspace, usually rooted at $HOME/.shipyard/data
 func GetDataFolder(p string) string {
-	data := filepath.Join(ShipyardHome(), "data", p)
+	data := filepath.Join(WorkspaceDir(Workspace()), "data", p)
 	// create the folder if it does not exist
 	os.MkdirAll(data, os.ModePerm)
 	return data
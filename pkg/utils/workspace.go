@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultWorkspace is the workspace used when no other workspace has been selected,
+// the default workspace stores its state and config in the root of the Shipyard home
+// folder so that existing stacks continue to work
+const DefaultWorkspace = "default"
+
+// WorkspaceEnvName is the environment variable used to set the workspace
+const WorkspaceEnvName = "SHIPYARD_WORKSPACE"
+
+var currentWorkspace string
+var workspaceMutex = sync.Mutex{}
+
+// SetWorkspace sets the workspace for the current process, this
+// overrides the environment variable and the selected workspace.
+// Setting an empty name removes the override.
+func SetWorkspace(name string) {
+	workspaceMutex.Lock()
+	defer workspaceMutex.Unlock()
+
+	currentWorkspace = name
+}
+
+// Workspace returns the current workspace, this is the workspace set with
+// SetWorkspace, the SHIPYARD_WORKSPACE environment variable, or the workspace
+// selected with SelectWorkspace, in that order. When none of these are set
+// the default workspace is returned.
+func Workspace() string {
+	workspaceMutex.Lock()
+	w := currentWorkspace
+	workspaceMutex.Unlock()
+
+	if w != "" {
+		return w
+	}
+
+	if w := os.Getenv(WorkspaceEnvName); w != "" {
+		return w
+	}
+
+	if d, err := ioutil.ReadFile(selectedWorkspacePath()); err == nil {
+		if w := strings.TrimSpace(string(d)); w != "" {
+			return w
+		}
+	}
+
+	return DefaultWorkspace
+}
+
+// ValidateWorkspace ensures the workspace name can be used for folders and Docker objects
+func ValidateWorkspace(name string) error {
+	_, err := ValidateName(name)
+	if err != nil {
+		return fmt.Errorf("Invalid workspace name %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// WorkspaceDir returns the folder which contains the state and config for the
+// given workspace, usually $HOME/.shipyard/workspaces/[name]
+func WorkspaceDir(name string) string {
+	if name == DefaultWorkspace {
+		return ShipyardHome()
+	}
+
+	return filepath.Join(WorkspacesDir(), name)
+}
+
+// WorkspacesDir returns the folder which contains all workspaces
+// other than the default, usually $HOME/.shipyard/workspaces
+func WorkspacesDir() string {
+	return filepath.Join(ShipyardHome(), "/workspaces")
+}
+
+// WorkspaceStatePath returns the full path for the state file of the given workspace
+func WorkspaceStatePath(name string) string {
+	return filepath.Join(WorkspaceDir(name), "/state", "/state.json")
+}
+
+// Workspaces returns the names of all workspaces sorted by name,
+// the default workspace always exists
+func Workspaces() ([]string, error) {
+	ws := []string{DefaultWorkspace}
+
+	files, err := ioutil.ReadDir(WorkspacesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Unable to list workspaces: %s", err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() && f.Name() != DefaultWorkspace {
+			ws = append(ws, f.Name())
+		}
+	}
+
+	sort.Strings(ws)
+
+	return ws, nil
+}
+
+// ActiveWorkspaces returns the names of the workspaces which have
+// resources in the state sorted by name
+func ActiveWorkspaces() ([]string, error) {
+	ws, err := Workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	active := []string{}
+	for _, w := range ws {
+		if _, err := os.Stat(WorkspaceStatePath(w)); err == nil {
+			active = append(active, w)
+		}
+	}
+
+	return active, nil
+}
+
+// SelectWorkspace sets the workspace used by future commands
+// when no other workspace is specified, the workspace is
+// created if it does not exist
+func SelectWorkspace(name string) error {
+	err := ValidateWorkspace(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(WorkspaceDir(name), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("Unable to create workspace %s: %s", name, err)
+	}
+
+	// selecting the default workspace removes the selection
+	if name == DefaultWorkspace {
+		err := os.Remove(selectedWorkspacePath())
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Unable to select workspace %s: %s", name, err)
+		}
+
+		return nil
+	}
+
+	err = ioutil.WriteFile(selectedWorkspacePath(), []byte(name), 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to select workspace %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// DeleteWorkspace removes the state and config for the given workspace,
+// the default workspace and the current workspace can not be deleted
+func DeleteWorkspace(name string) error {
+	if name == DefaultWorkspace {
+		return fmt.Errorf("The default workspace can not be deleted")
+	}
+
+	if name == Workspace() {
+		return fmt.Errorf("Workspace %s is the current workspace, select a different workspace before deleting", name)
+	}
+
+	err := ValidateWorkspace(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(WorkspaceDir(name)); os.IsNotExist(err) {
+		return fmt.Errorf("Workspace %s does not exist", name)
+	}
+
+	err = os.RemoveAll(WorkspaceDir(name))
+	if err != nil {
+		return fmt.Errorf("Unable to delete workspace %s: %s", name, err)
+	}
+
+	return nil
+}
+
+func selectedWorkspacePath() string {
+	return filepath.Join(ShipyardHome(), "/workspace")
+}
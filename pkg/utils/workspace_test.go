@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupWorkspaceTest(t *testing.T) string {
+	tmp := t.TempDir()
+
+	home := os.Getenv(HomeEnvName())
+	os.Setenv(HomeEnvName(), tmp)
+
+	env := os.Getenv(WorkspaceEnvName)
+	os.Unsetenv(WorkspaceEnvName)
+
+	t.Cleanup(func() {
+		os.Setenv(HomeEnvName(), home)
+		os.Setenv(WorkspaceEnvName, env)
+		SetWorkspace("")
+	})
+
+	return tmp
+}
+
+func TestWorkspaceReturnsDefault(t *testing.T) {
+	tmp := setupWorkspaceTest(t)
+
+	assert.Equal(t, DefaultWorkspace, Workspace())
+	assert.Equal(t, filepath.Join(tmp, ".shipyard", "state"), StateDir())
+	assert.Equal(t, "test.container.shipyard.run", FQDN("test", "container"))
+	assert.Equal(t, "cloud", DockerNetworkName("network.cloud"))
+	assert.Equal(t, filepath.Join(tmp, ".shipyard", "data", "app"), GetDataFolder("app"))
+}
+
+func TestWorkspaceUsesEnvironmentVariable(t *testing.T) {
+	setupWorkspaceTest(t)
+	os.Setenv(WorkspaceEnvName, "payments")
+
+	assert.Equal(t, "payments", Workspace())
+}
+
+func TestWorkspaceSetOverridesEnvironmentVariable(t *testing.T) {
+	setupWorkspaceTest(t)
+	os.Setenv(WorkspaceEnvName, "payments")
+
+	SetWorkspace("search")
+
+	assert.Equal(t, "search", Workspace())
+}
+
+func TestWorkspaceUsesSelectedWorkspace(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	err := SelectWorkspace("payments")
+	assert.NoError(t, err)
+	assert.Equal(t, "payments", Workspace())
+
+	err = SelectWorkspace(DefaultWorkspace)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWorkspace, Workspace())
+}
+
+func TestSelectWorkspaceWithInvalidNameReturnsError(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	err := SelectWorkspace("../payments")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceScopesPathsAndNames(t *testing.T) {
+	tmp := setupWorkspaceTest(t)
+	SetWorkspace("payments")
+
+	ws := filepath.Join(tmp, ".shipyard", "workspaces", "payments")
+	assert.Equal(t, filepath.Join(ws, "state"), StateDir())
+	assert.Equal(t, filepath.Join(ws, "state", "state.json"), StatePath())
+	assert.Equal(t, filepath.Join(ws, "config"), ConfigDir())
+
+	d, kc, _ := CreateKubeConfigPath("k3s")
+	assert.Equal(t, filepath.Join(ws, "config", "k3s"), d)
+	assert.Equal(t, filepath.Join(ws, "config", "k3s", "kubeconfig.yaml"), kc)
+
+	assert.Equal(t, "test.container.payments.shipyard.run", FQDN("test", "container"))
+	assert.Equal(t, "images.volume.payments.shipyard.run", FQDNVolumeName("images"))
+	assert.Equal(t, "http://docker-cache.container.payments.shipyard.run:3128", ProxyAddress())
+	assert.Equal(t, "cloud.payments", DockerNetworkName("network.cloud"))
+	assert.Equal(t, "cloud.payments", DockerNetworkName("cloud"))
+
+	assert.Equal(t, filepath.Join(ws, "data", "app"), GetDataFolder("app"))
+	assert.Equal(t, filepath.Join(ws, "certs", "k3s"), CertsDir("k3s"))
+	assert.Equal(t, filepath.Join(ws, "images.log"), ImageCacheLog())
+	assert.Equal(t, filepath.Join(ws, "plugins"), PluginsDir())
+
+	// the root certificates are shared by all workspaces
+	assert.Equal(t, filepath.Join(tmp, ".shipyard", "certs"), CertsDir(""))
+}
+
+func TestWorkspacesListsWorkspaces(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	SelectWorkspace("search")
+	SelectWorkspace("payments")
+
+	ws, err := Workspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "payments", "search"}, ws)
+}
+
+func TestActiveWorkspacesListsWorkspacesWithState(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	SelectWorkspace("search")
+	SelectWorkspace("payments")
+
+	os.MkdirAll(StateDir(), os.ModePerm)
+	ioutil.WriteFile(StatePath(), []byte("{}"), os.ModePerm)
+
+	ws, err := ActiveWorkspaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, ws)
+}
+
+func TestDeleteWorkspaceRemovesFolder(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	SelectWorkspace("payments")
+	SelectWorkspace(DefaultWorkspace)
+
+	err := DeleteWorkspace("payments")
+	assert.NoError(t, err)
+
+	_, err = os.Stat(WorkspaceDir("payments"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeleteWorkspaceReturnsErrorForDefaultOrCurrent(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	err := DeleteWorkspace(DefaultWorkspace)
+	assert.Error(t, err)
+
+	SelectWorkspace("payments")
+
+	err = DeleteWorkspace("payments")
+	assert.Error(t, err)
+}
+
+func TestDeleteWorkspaceReturnsErrorWhenNotExists(t *testing.T) {
+	setupWorkspaceTest(t)
+
+	err := DeleteWorkspace("payments")
+	assert.Error(t, err)
+}